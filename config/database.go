@@ -1,10 +1,13 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -39,6 +42,9 @@ func SetUpDatabaseConnection() *gorm.DB {
 		panic(err)
 	}
 
+	// User yang sudah terdaftar sebelum ada verifikasi email dianggap sudah terverifikasi
+	backfillEmailVerified := db.Migrator().HasTable(&entities.User{}) && !db.Migrator().HasColumn(&entities.User{}, "EmailVerifiedAt")
+
 	if err := db.AutoMigrate(
 		entities.HistoryPenarikan{},
 		entities.HistoryTransaksiUser{},
@@ -48,16 +54,263 @@ func SetUpDatabaseConnection() *gorm.DB {
 		entities.Transaksi{},
 		entities.PenerimaDonasi{},
 		entities.PembuatDonasi{},
+		entities.CategoryEvent{},
+		entities.Asset{},
 		entities.Event{},
+		entities.EventStatusHistory{},
+		entities.KabarTerbaru{},
+		entities.KabarTerbaruGambar{},
+		entities.Komentar{},
+		entities.KomentarReport{},
+		entities.KomentarRateLimit{},
+		entities.RefreshToken{},
+		entities.RevokedToken{},
+		entities.UserTokenRevocation{},
+		entities.PasswordResetToken{},
+		entities.EmailChangeToken{},
+		entities.LoginAttempt{},
+		entities.LoginThrottle{},
+		entities.UserTwoFactor{},
+		entities.TwoFactorRecoveryCode{},
+		entities.UserKYC{},
+		entities.APIKey{},
+		entities.OIDCLoginState{},
+		entities.UserIdentity{},
+		entities.UserSession{},
+		entities.CampaignHandover{},
 	); err != nil {
 		fmt.Println(err)
 		panic(err)
 	}
 
+	if err := migrateEventCategories(db); err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	if err := migrateEventStatus(db); err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	// time_left sekarang dihitung saat response dibuat
+	if db.Migrator().HasColumn(&entities.Event{}, "sisa_hari_donasi") {
+		if err := db.Migrator().DropColumn(&entities.Event{}, "sisa_hari_donasi"); err != nil {
+			fmt.Println(err)
+			panic(err)
+		}
+	}
+
+	if err := migrateNIKHash(db); err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	// NIK pada event dulu disalin utuh dari KYC
+	if err := db.Exec(`UPDATE events SET nomor_ktp = left(nomor_ktp, 6) || '******' || right(nomor_ktp, 4) WHERE nomor_ktp ~ '^[0-9]{16}$'`).Error; err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	if err := repository.MigrateEventSearch(db); err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	if err := restrictCascadeDeletion(db, &entities.User{}, "Transaksi", "HistoryPenarikan", "Events"); err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+	if err := restrictCascadeDeletion(db, &entities.Event{}, "Transaksi", "HistoryPenarikan"); err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	if backfillEmailVerified {
+		if err := db.Model(&entities.User{}).Where("email_verified_at IS NULL").Update("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+			fmt.Println(err)
+			panic(err)
+		}
+	}
+
 	fmt.Println("Database Connected")
 	return db
 }
 
+// restrictCascadeDeletion mengganti foreign key lama yang masih ON DELETE
+// CASCADE, karena AutoMigrate tidak mengubah constraint yang sudah ada.
+// Transaksi dan penarikan tidak boleh ikut terhapus bersama user maupun event.
+func restrictCascadeDeletion(db *gorm.DB, model any, relations ...string) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+
+	for _, relation := range relations {
+		constraint := stmt.Schema.Relationships.Relations[relation].ParseConstraint()
+		if constraint == nil {
+			continue
+		}
+
+		var deleteRule string
+		if err := db.Raw("SELECT delete_rule FROM information_schema.referential_constraints WHERE constraint_name = ?", constraint.Name).Scan(&deleteRule).Error; err != nil {
+			return err
+		}
+		if deleteRule != "CASCADE" {
+			continue
+		}
+
+		if err := db.Migrator().DropConstraint(model, relation); err != nil {
+			return err
+		}
+		if err := db.Migrator().CreateConstraint(model, relation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateEventCategories melengkapi slug kategori lama dan memindahkan kolom
+// jenis_event yang berisi nama kategori menjadi category_id.
+func migrateEventCategories(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var categories []entities.CategoryEvent
+		if err := tx.Where("slug IS NULL OR slug = ''").Find(&categories).Error; err != nil {
+			return err
+		}
+		for _, category := range categories {
+			slug, err := availableCategorySlug(tx, category.Nama, category.ID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&category).Update("slug", slug).Error; err != nil {
+				return err
+			}
+		}
+
+		if tx.Migrator().HasColumn(&entities.Event{}, "jenis_event") {
+			var names []string
+			if err := tx.Model(&entities.Event{}).Distinct("jenis_event").
+				Where("jenis_event IS NOT NULL AND jenis_event <> ''").
+				Pluck("jenis_event", &names).Error; err != nil {
+				return err
+			}
+
+			for _, name := range names {
+				var category entities.CategoryEvent
+				err := tx.Where("lower(nama) = lower(?)", name).Order("id").First(&category).Error
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return err
+				}
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					// Kategori yang sudah tidak ada dibuat ulang dalam keadaan nonaktif
+					slug, err := availableCategorySlug(tx, name, 0)
+					if err != nil {
+						return err
+					}
+					isActive := false
+					category = entities.CategoryEvent{Nama: name, Slug: slug, IsActive: &isActive}
+					if err := tx.Create(&category).Error; err != nil {
+						return err
+					}
+				}
+
+				if err := tx.Model(&entities.Event{}).
+					Where("jenis_event = ? AND category_id IS NULL", name).
+					Update("category_id", category.ID).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Migrator().DropColumn(&entities.Event{}, "jenis_event"); err != nil {
+				return err
+			}
+		}
+
+		// Seeder lama memakai id eksplisit sehingga sequence perlu disesuaikan
+		return tx.Exec("SELECT setval(pg_get_serial_sequence('category_events', 'id'), GREATEST((SELECT COALESCE(MAX(id), 0) FROM category_events), 1))").Error
+	})
+}
+
+// migrateNIKHash menghitung ulang hash NIK yang dibuat dengan SHA-256 biasa
+// atau dengan kunci lama, sehingga NIK_HASH_KEY juga bisa dirotasi.
+func migrateNIKHash(db *gorm.DB) error {
+	var kycs []entities.UserKYC
+	return db.Select("id", "nik_encrypted", "nik_hash").FindInBatches(&kycs, 500, func(tx *gorm.DB, batch int) error {
+		for _, kyc := range kycs {
+			nik, err := helpers.DecryptString(kyc.NIKEncrypted)
+			if err != nil {
+				return err
+			}
+			nikHash := helpers.HashNIK(nik)
+			if nikHash == kyc.NIKHash {
+				continue
+			}
+			if err := tx.Model(&entities.UserKYC{}).Where("id = ?", kyc.ID).UpdateColumn("nik_hash", nikHash).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// migrateEventStatus mengubah flag lama is_expired, is_target_full dan is_done
+// menjadi kolom status. Event lama dianggap sudah disetujui.
+func migrateEventStatus(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&entities.Event{}, "is_expired") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`UPDATE events SET status = CASE
+			WHEN is_target_full THEN ?
+			WHEN is_expired OR expired_donasi <= now() THEN ?
+			ELSE ? END`,
+			entities.EventStatusFunded, entities.EventStatusExpired, entities.EventStatusActive,
+		).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`INSERT INTO event_status_histories (event_id, from_status, to_status, reason, created_at)
+			SELECT id, ?, status, ?, now() FROM events`,
+			entities.EventStatusDraft, "Migrasi dari status lama",
+		).Error; err != nil {
+			return err
+		}
+
+		for _, column := range []string{"is_expired", "is_target_full", "is_done"} {
+			if !tx.Migrator().HasColumn(&entities.Event{}, column) {
+				continue
+			}
+			if err := tx.Migrator().DropColumn(&entities.Event{}, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// availableCategorySlug membuat slug dari nama dan menambahkan angka jika
+// slug tersebut sudah dipakai kategori lain.
+func availableCategorySlug(db *gorm.DB, name string, categoryID uint) (string, error) {
+	base := helpers.Slugify(name)
+	if base == "" {
+		base = "kategori"
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := db.Model(&entities.CategoryEvent{}).Where("slug = ? AND id <> ?", slug, categoryID).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
 func ClosDatabaseConnection(db *gorm.DB) {
 	dbSQL, err := db.DB()
 	if err != nil {
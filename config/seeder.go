@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"os"
 
 	"github.com/Caknoooo/golang-clean_template/entities"
 	"gorm.io/gorm"
@@ -20,6 +21,10 @@ func Seeder(db *gorm.DB) error {
 		return err
 	}
 
+	if err := AdminSeeder(db); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -118,20 +123,24 @@ func StatusPembayaranSeeder(db *gorm.DB) error {
 	return nil
 }
 
-
+// CreateCategoryEvent hanya mengisi kategori awal ketika tabel masih kosong,
+// sehingga kategori yang diubah atau dihapus admin tidak dibuat ulang.
 func CreateCategoryEvent(db *gorm.DB) error {
 	var CategoryEvent = []entities.CategoryEvent{
 		{
-			ID: 1,
-			Nama: "Kegiatan Sosial",
+			Nama:   "Kegiatan Sosial",
+			Slug:   "kegiatan-sosial",
+			Urutan: 1,
 		},
 		{
-			ID: 2,
-			Nama: "Kesehatan",
+			Nama:   "Kesehatan",
+			Slug:   "kesehatan",
+			Urutan: 2,
 		},
 		{
-			ID: 3,
-			Nama: "Pendidikan",
+			Nama:   "Pendidikan",
+			Slug:   "pendidikan",
+			Urutan: 3,
 		},
 	}
 
@@ -143,20 +152,27 @@ func CreateCategoryEvent(db *gorm.DB) error {
 		}
 	}
 
+	var count int64
+	if err := db.Model(&entities.CategoryEvent{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
 
-	for _, data := range CategoryEvent {
-		var category_event entities.CategoryEvent
-		err := db.Where(&entities.CategoryEvent{ID: data.ID}).First(&category_event).Error
-		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			return err
-		}
+	return db.Create(&CategoryEvent).Error
+}
 
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			if err := db.Create(&data).Error; err != nil {
-				return err
-			}
-		}
+// AdminSeeder menjadikan user dengan email ADMIN_EMAIL sebagai admin pertama,
+// karena promote user hanya bisa dilakukan oleh admin.
+func AdminSeeder(db *gorm.DB) error {
+	email := os.Getenv("ADMIN_EMAIL")
+	if email == "" {
+		return nil
 	}
 
+	if err := db.Model(&entities.User{}).Where("email = ?", email).Update("role", entities.RoleAdmin).Error; err != nil {
+		return err
+	}
 	return nil
-}
\ No newline at end of file
+}
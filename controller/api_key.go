@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/services"
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyController interface {
+	CreateAPIKey(ctx *gin.Context)
+	GetAllAPIKey(ctx *gin.Context)
+	RevokeAPIKey(ctx *gin.Context)
+}
+
+type apiKeyController struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyController(as services.APIKeyService) APIKeyController {
+	return &apiKeyController{
+		apiKeyService: as,
+	}
+}
+
+func (ac *apiKeyController) CreateAPIKey(ctx *gin.Context) {
+	var apiKeyDTO dto.APIKeyCreateDTO
+	if err := ctx.ShouldBind(&apiKeyDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	creatorID := ctx.MustGet("userID").(uuid.UUID)
+	result, err := ac.apiKeyService.CreateAPIKey(ctx.Request.Context(), creatorID, apiKeyDTO)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Membuat API Key", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Membuat API Key", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (ac *apiKeyController) GetAllAPIKey(ctx *gin.Context) {
+	result, err := ac.apiKeyService.GetAllAPIKey(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan List API Key", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan List API Key", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (ac *apiKeyController) RevokeAPIKey(ctx *gin.Context) {
+	apiKeyID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := ac.apiKeyService.RevokeAPIKey(ctx.Request.Context(), apiKeyID); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mencabut API Key", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mencabut API Key", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/services"
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AssetController interface {
+	UploadAsset(ctx *gin.Context)
+	ServeFile(ctx *gin.Context)
+}
+
+type assetController struct {
+	assetService services.AssetService
+}
+
+func NewAssetController(as services.AssetService) AssetController {
+	return &assetController{
+		assetService: as,
+	}
+}
+
+func (ac *assetController) UploadAsset(ctx *gin.Context) {
+	// Batas body sedikit di atas ukuran file untuk memberi ruang header multipart
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, ac.assetService.MaxUploadSize()+1<<20)
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			res := utils.BuildResponseFailed("Gagal Mengunggah Gambar", services.ErrAssetTooLarge.Error(), utils.EmptyObj{})
+			ctx.JSON(http.StatusRequestEntityTooLarge, res)
+			return
+		}
+		res := utils.BuildResponseFailed("Gagal Mengunggah Gambar", services.ErrAssetFileRequired.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	asset, err := ac.assetService.UploadImage(ctx.Request.Context(), userID, file)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, services.ErrAssetTooLarge):
+			status = http.StatusRequestEntityTooLarge
+		case errors.Is(err, services.ErrAssetQuotaFull):
+			status = http.StatusForbidden
+		case errors.Is(err, helpers.ErrUnsupportedImage):
+			status = http.StatusUnsupportedMediaType
+		}
+		res := utils.BuildResponseFailed("Gagal Mengunggah Gambar", err.Error(), utils.EmptyObj{})
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mengunggah Gambar", services.ToAssetResponse(asset))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// ServeFile menyajikan file asset dari storage lokal dan hanya didaftarkan
+// jika STORAGE_DRIVER memakai storage lokal. Key file selalu unik sehingga
+// file boleh di-cache selamanya oleh browser.
+func (ac *assetController) ServeFile(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	file, err := ac.assetService.OpenFile(ctx.Request.Context(), key)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrStorageObjectNotFound) {
+			status = http.StatusNotFound
+		}
+		res := utils.BuildResponseFailed("Gagal Mendapatkan File", err.Error(), utils.EmptyObj{})
+		ctx.JSON(status, res)
+		return
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.DataFromReader(http.StatusOK, -1, contentType, file, nil)
+}
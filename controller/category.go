@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/services"
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryController interface {
+	GetCategoryTree(ctx *gin.Context)
+	GetAllCategories(ctx *gin.Context)
+	CreateCategory(ctx *gin.Context)
+	UpdateCategory(ctx *gin.Context)
+	DeleteCategory(ctx *gin.Context)
+}
+
+type categoryController struct {
+	categoryService services.CategoryService
+}
+
+func NewCategoryController(cs services.CategoryService) CategoryController {
+	return &categoryController{
+		categoryService: cs,
+	}
+}
+
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrCategorySlugTaken), errors.Is(err, services.ErrCategoryInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (cc *categoryController) GetCategoryTree(ctx *gin.Context) {
+	result, err := cc.categoryService.GetCategoryTree(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Kategori", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Kategori", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (cc *categoryController) GetAllCategories(ctx *gin.Context) {
+	result, err := cc.categoryService.GetAllCategories(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Kategori", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Kategori", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (cc *categoryController) CreateCategory(ctx *gin.Context) {
+	var categoryDTO dto.CategoryCreateDTO
+	if err := ctx.ShouldBind(&categoryDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := cc.categoryService.CreateCategory(ctx.Request.Context(), categoryDTO)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Menambahkan Kategori", err.Error(), utils.EmptyObj{})
+		ctx.JSON(categoryErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menambahkan Kategori", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (cc *categoryController) UpdateCategory(ctx *gin.Context) {
+	categoryID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var categoryDTO dto.CategoryUpdateDTO
+	if err := ctx.ShouldBind(&categoryDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := cc.categoryService.UpdateCategory(ctx.Request.Context(), uint(categoryID), categoryDTO)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Update Kategori", err.Error(), utils.EmptyObj{})
+		ctx.JSON(categoryErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Update Kategori", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (cc *categoryController) DeleteCategory(ctx *gin.Context) {
+	categoryID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := cc.categoryService.DeleteCategory(ctx.Request.Context(), uint(categoryID)); err != nil {
+		res := utils.BuildResponseFailed("Gagal Menghapus Kategori", err.Error(), utils.EmptyObj{})
+		ctx.JSON(categoryErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menghapus Kategori", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
@@ -1,13 +1,13 @@
 package controller
 
 import (
-	"fmt"
-	"math"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/Caknoooo/golang-clean_template/dto"
 	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
 	"github.com/Caknoooo/golang-clean_template/services"
 	"github.com/Caknoooo/golang-clean_template/utils"
 	"github.com/gin-gonic/gin"
@@ -18,14 +18,15 @@ import (
 type EventController interface {
 	CreateEvent(ctx *gin.Context)
 	GetAllEvent(ctx *gin.Context)
+	SearchEvents(ctx *gin.Context)
 	GetAllEventByUserID(ctx *gin.Context)
 	GetEventByID(ctx *gin.Context)
 	LikeEventByEventID(ctx *gin.Context)
 	UpdateEvent(ctx *gin.Context)
+	UpdateEventStatus(ctx *gin.Context)
+	GetEventStatusHistory(ctx *gin.Context)
 	DeleteEvent(ctx *gin.Context)
 	GetAllEventLastTransaksi(ctx *gin.Context)
-	Post3Event(ctx *gin.Context)
-	Get3Event(ctx *gin.Context)
 	GetEventForService(ctx *gin.Context)
 }
 
@@ -33,17 +34,27 @@ type eventController struct {
 	jwtService       services.JWTService
 	eventService     services.EventService
 	transaksiService services.TransaksiService
+	userService      services.UserService
+	twoFactorService services.TwoFactorService
+	policyService    services.PolicyService
+	kycService       services.KYCService
+	categoryService  services.CategoryService
+	assetService     services.AssetService
 	db               *gorm.DB
-	page             uint
 }
 
-func NewEventController(es services.EventService, ts services.TransaksiService, jwt services.JWTService, db *gorm.DB) EventController {
+func NewEventController(es services.EventService, ts services.TransaksiService, us services.UserService, tfs services.TwoFactorService, ps services.PolicyService, ks services.KYCService, cs services.CategoryService, as services.AssetService, jwt services.JWTService, db *gorm.DB) EventController {
 	return &eventController{
 		jwtService:       jwt,
 		eventService:     es,
 		transaksiService: ts,
+		userService:      us,
+		twoFactorService: tfs,
+		policyService:    ps,
+		kycService:       ks,
+		categoryService:  cs,
+		assetService:     as,
 		db:               db,
-		page:             1,
 	}
 }
 
@@ -54,20 +65,67 @@ func (ec *eventController) CreateEvent(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
+	if !eventDTO.ExpiredDonasi.After(time.Now()) {
+		res := utils.BuildResponseFailed("Gagal Menambahkan Event", services.ErrEventDeadlinePassed.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	user, err := ec.userService.GetUserByID(ctx.Request.Context(), ctx.MustGet("userID").(uuid.UUID))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan User", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	if user.EmailVerifiedAt == nil {
+		res := utils.BuildResponseFailed("Gagal Menambahkan Event", "Email Belum Diverifikasi", utils.EmptyObj{})
+		ctx.JSON(http.StatusForbidden, res)
+		return
+	}
+	if user.DeletionScheduledAt != nil {
+		res := utils.BuildResponseFailed("Gagal Menambahkan Event", "Akun Sedang Dalam Proses Penghapusan", utils.EmptyObj{})
+		ctx.JSON(http.StatusForbidden, res)
+		return
+	}
+
+	// Data identitas pembuat diambil dari KYC yang sudah disetujui, bukan dari input
+	identity, err := ec.kycService.GetApprovedIdentity(ctx.Request.Context(), user.ID)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrKYCNotApproved) {
+			status = http.StatusForbidden
+		}
+		res := utils.BuildResponseFailed("Gagal Menambahkan Event", err.Error(), utils.EmptyObj{})
+		ctx.JSON(status, res)
+		return
+	}
+	eventDTO.NomorKTP = helpers.MaskNIK(identity.NIK)
+	eventDTO.Pekerjaan = identity.Pekerjaan
+	eventDTO.AsalInstansi = identity.AsalInstansi
+
+	// Pemilik event selalu user yang sedang login
+	eventDTO.UserID = user.ID
 
 	// Check if the category event exists
-	var category entities.CategoryEvent
-	if err := ec.db.Where("nama = ?", eventDTO.JenisEvent).First(&category).Error; err != nil {
+	category, err := ec.categoryService.GetActiveCategory(ctx.Request.Context(), *eventDTO.CategoryID)
+	if err != nil {
 		res := utils.BuildResponseFailed("Kategori Event Tidak Ditemukan", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
-	// Create the event
-	eventDTO.JenisEvent = category.Nama
-	fmt.Println(eventDTO.ExpiredDonasi)
-	
+
+	// Foto event harus gambar yang diunggah sendiri oleh pembuat event
+	asset, err := ec.assetService.GetOwnedAsset(ctx.Request.Context(), actorFromContext(ctx), *eventDTO.FotoAssetID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Menambahkan Event", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	eventDTO.FotoEvent = asset.URL
+	eventDTO.FotoThumbnail = asset.ThumbnailURL
+
 	// Time zone
-	expiredDonasiStr  := eventDTO.ExpiredDonasi.Format(time.RFC3339)
+	expiredDonasiStr := eventDTO.ExpiredDonasi.Format(time.RFC3339)
 	expiredDonasiLocal, err := time.ParseInLocation(time.RFC3339, expiredDonasiStr, time.Local)
 	if err != nil {
 		res := utils.BuildResponseFailed("Gagal Memparse Waktu", err.Error(), utils.EmptyObj{})
@@ -76,10 +134,6 @@ func (ec *eventController) CreateEvent(ctx *gin.Context) {
 	}
 
 	eventDTO.ExpiredDonasi = expiredDonasiLocal
-	fmt.Println(eventDTO.ExpiredDonasi)
-
-	timeLeft := TimeLeft(eventDTO.ExpiredDonasi)
-	eventDTO.SisaHariDonasi = &timeLeft
 
 	event, err := ec.eventService.CreateEvent(ctx, eventDTO)
 	if err != nil {
@@ -88,35 +142,68 @@ func (ec *eventController) CreateEvent(ctx *gin.Context) {
 		return
 	}
 
-	res := utils.BuildResponseSuccess("Berhasil Menambahkan Event", event)
+	event.Category = &category
+	res := utils.BuildResponseSuccess("Berhasil Menambahkan Event", services.ToEventOwnerResponse(event))
 	ctx.JSON(http.StatusOK, res)
 }
 
-func TimeLeft(expiredTime time.Time) string {
-	timeLeft := expiredTime.Sub(time.Now())
+func (ec *eventController) GetAllEvent(ctx *gin.Context) {
+	var listQuery dto.EventListQuery
+	if err := ctx.ShouldBindQuery(&listQuery); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Query", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
 
-	fmt.Print(timeLeft.Hours() / 24)
-	if timeLeft.Hours()/24 <= 0 {
-		return "Waktu Habis"
+	if !canFilterEventStatus(ctx, listQuery.EventFilterQuery) {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan List Event", services.ErrForbidden.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusForbidden, res)
+		return
 	}
 
-	if timeLeft.Hours()/24 < 1 {
-		return "<1 Hari"
+	events, meta, err := ec.eventService.GetEvents(ctx.Request.Context(), listQuery)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan List Event", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
 	}
 
-	dayLeft := int(math.Round(timeLeft.Hours() / 24))
-	return fmt.Sprintf("%v Hari", dayLeft)
+	res := utils.BuildResponseSuccessWithMeta("Berhasil Mendapatkan List Event", services.ToEventResponses(events, optionalActorFromContext(ctx)), meta)
+	ctx.JSON(http.StatusOK, res)
 }
 
-func (ec *eventController) GetAllEvent(ctx *gin.Context) {
-	events, err := ec.eventService.GetAllEvent(ctx)
+// canFilterEventStatus membatasi filter status yang tidak tampil untuk umum,
+// misalnya antrean pending_review, hanya untuk admin.
+func canFilterEventStatus(ctx *gin.Context, filter dto.EventFilterQuery) bool {
+	if filter.Status == "" || entities.IsEventPublicStatus(filter.Status) {
+		return true
+	}
+	actor := optionalActorFromContext(ctx)
+	return actor != nil && actor.IsAdmin()
+}
+
+func (ec *eventController) SearchEvents(ctx *gin.Context) {
+	var searchQuery dto.EventSearchQuery
+	if err := ctx.ShouldBindQuery(&searchQuery); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Query", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if !canFilterEventStatus(ctx, searchQuery.EventFilterQuery) {
+		res := utils.BuildResponseFailed("Gagal Mencari Event", services.ErrForbidden.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusForbidden, res)
+		return
+	}
+
+	results, meta, err := ec.eventService.SearchEvents(ctx.Request.Context(), searchQuery)
 	if err != nil {
-		res := utils.BuildResponseFailed("Gagal Mendapatkan List Event", err.Error(), utils.EmptyObj{})
+		res := utils.BuildResponseFailed("Gagal Mencari Event", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	res := utils.BuildResponseSuccess("Berhasil Mendapatkan List Event", events)
+	res := utils.BuildResponseSuccessWithMeta("Berhasil Mencari Event", services.ToEventSearchResponses(results, optionalActorFromContext(ctx)), meta)
 	ctx.JSON(http.StatusOK, res)
 }
 
@@ -129,14 +216,15 @@ func (ec *eventController) GetAllEventByUserID(ctx *gin.Context) {
 		return
 	}
 
-	result, err := ec.eventService.GetAllEventByUserID(ctx, uuid)
+	actor := optionalActorFromContext(ctx)
+	result, err := ec.eventService.GetAllEventByUserID(ctx.Request.Context(), uuid, !services.CanViewHiddenEvents(actor, uuid))
 	if err != nil {
 		res := utils.BuildResponseFailed("Gagal Mendapatkan Event", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Event", result)
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Event", services.ToEventResponses(result, actor))
 	ctx.JSON(http.StatusOK, res)
 }
 
@@ -156,29 +244,28 @@ func (ec *eventController) GetEventByID(ctx *gin.Context) {
 		return
 	}
 
-	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Event", result)
+	// Event yang belum disetujui atau dibatalkan hanya terlihat oleh pemilik dan admin
+	actor := optionalActorFromContext(ctx)
+	if !entities.IsEventPublicStatus(result.Status) && !services.CanViewHiddenEvents(actor, result.UserID) {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Event", services.ErrResourceNotFound.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Event", services.ToEventResponse(result, actor))
 	ctx.JSON(http.StatusOK, res)
 }
 
 func (ec *eventController) LikeEventByEventID(ctx *gin.Context) {
-	user_id := ctx.Param("user_id")
-	event_id := ctx.Param("event_id")
-
-	user_uuid, err := uuid.Parse(user_id)
-	if err != nil {
-		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
-		return
-	}
-
-	event_uuid, err := uuid.Parse(event_id)
+	event_uuid, err := uuid.Parse(ctx.Param("event_id"))
 	if err != nil {
 		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	if err = ec.eventService.LikeEventByEventID(ctx, user_uuid, event_uuid); err != nil {
+	actor := actorFromContext(ctx)
+	if err = ec.eventService.LikeEventByEventID(ctx, actor.UserID, event_uuid); err != nil {
 		res := utils.BuildResponseFailed("Gagal Like Event", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
@@ -190,7 +277,7 @@ func (ec *eventController) LikeEventByEventID(ctx *gin.Context) {
 
 func (ec *eventController) UpdateEvent(ctx *gin.Context) {
 	id := ctx.Param("id")
-	uuid, err := uuid.Parse(id)
+	eventID, err := uuid.Parse(id)
 	if err != nil {
 		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusUnauthorized, res)
@@ -204,10 +291,49 @@ func (ec *eventController) UpdateEvent(ctx *gin.Context) {
 		return
 	}
 
-	eventDTO.ID = uuid
-	if err := ec.eventService.UpdateEvent(ctx, eventDTO, uuid); err != nil {
+	actor := actorFromContext(ctx)
+	if err := ec.policyService.AuthorizeEvent(ctx.Request.Context(), actor, eventID); err != nil {
+		abortWithPolicyError(ctx, err)
+		return
+	}
+
+	// Kepemilikan event tidak bisa dipindahkan lewat update
+	eventDTO.UserID = nil
+
+	// Mengganti rekening pencairan termasuk aksi sensitif
+	if eventDTO.RekeningEvent != nil {
+		if err := ec.twoFactorService.CheckStepUp(ctx.Request.Context(), actor.UserID, ctx.MustGet("sessionID").(uuid.UUID), ctx.GetHeader(services.StepUpHeader)); err != nil {
+			res := utils.BuildResponseFailed("Gagal Mengupdate Event", err.Error(), utils.EmptyObj{})
+			ctx.JSON(http.StatusForbidden, res)
+			return
+		}
+	}
+
+	if eventDTO.CategoryID != nil {
+		if _, err := ec.categoryService.GetActiveCategory(ctx.Request.Context(), *eventDTO.CategoryID); err != nil {
+			res := utils.BuildResponseFailed("Kategori Event Tidak Ditemukan", err.Error(), utils.EmptyObj{})
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+	}
+
+	eventDTO.FotoEvent = nil
+	eventDTO.FotoThumbnail = nil
+	if eventDTO.FotoAssetID != nil {
+		asset, err := ec.assetService.GetOwnedAsset(ctx.Request.Context(), actor, *eventDTO.FotoAssetID)
+		if err != nil {
+			res := utils.BuildResponseFailed("Gagal Mengupdate Event", err.Error(), utils.EmptyObj{})
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+		eventDTO.FotoEvent = &asset.URL
+		eventDTO.FotoThumbnail = &asset.ThumbnailURL
+	}
+
+	eventDTO.ID = eventID
+	if err := ec.eventService.UpdateEvent(ctx, eventDTO, eventID); err != nil {
 		res := utils.BuildResponseFailed("Gagal Mengupdate Event", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		ctx.JSON(eventStatusErrorStatus(err), res)
 		return
 	}
 
@@ -215,67 +341,121 @@ func (ec *eventController) UpdateEvent(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
-func (ec *eventController) DeleteEvent(ctx *gin.Context) {
-	id := ctx.Param("id")
-	uuid, err := uuid.Parse(id)
+func eventStatusErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrResourceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidEventTransition),
+		errors.Is(err, services.ErrEventStatusChanged),
+		errors.Is(err, services.ErrEventDeadlinePassed),
+		errors.Is(err, services.ErrEventHasDonation),
+		errors.Is(err, services.ErrEventFundsRemaining),
+		errors.Is(err, services.ErrEventNotEditable),
+		errors.Is(err, services.ErrEventNotDeletable):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (ec *eventController) UpdateEventStatus(ctx *gin.Context) {
+	eventID, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
 		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	if err := ec.eventService.DeleteEvent(ctx, uuid); err != nil {
-		res := utils.BuildResponseFailed("Gagal Delete Event", err.Error(), utils.EmptyObj{})
+	var statusDTO dto.EventStatusUpdateDTO
+	if err := ctx.ShouldBind(&statusDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	res := utils.BuildResponseSuccess("Berhasil Delete Event", utils.EmptyObj{})
+	actor := actorFromContext(ctx)
+	if err := ec.policyService.AuthorizeEvent(ctx.Request.Context(), actor, eventID); err != nil {
+		abortWithPolicyError(ctx, err)
+		return
+	}
+
+	event, err := ec.eventService.TransitionStatus(ctx.Request.Context(), &actor, eventID, statusDTO.Status, statusDTO.Reason)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mengubah Status Event", err.Error(), utils.EmptyObj{})
+		ctx.JSON(eventStatusErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mengubah Status Event", services.ToEventResponse(event, &actor))
 	ctx.JSON(http.StatusOK, res)
 }
 
-func (ec *eventController) GetAllEventLastTransaksi(ctx *gin.Context) {
-	id := ctx.Param("event_id")
-	uuid, err := uuid.Parse(id)
+func (ec *eventController) GetEventStatusHistory(ctx *gin.Context) {
+	eventID, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
 		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
-	events, err := ec.transaksiService.GetAllEventLastTransaksi(ctx, uuid)
+
+	if err := ec.policyService.AuthorizeEvent(ctx.Request.Context(), actorFromContext(ctx), eventID); err != nil {
+		abortWithPolicyError(ctx, err)
+		return
+	}
+
+	result, err := ec.eventService.GetStatusHistory(ctx.Request.Context(), eventID)
 	if err != nil {
-		res := utils.BuildResponseFailed("Gagal Mendapatkan List Transaksi", err.Error(), utils.EmptyObj{})
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Riwayat Status Event", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	res := utils.BuildResponseSuccess("Berhasil Mendapatkan List Transaksi", events)
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Riwayat Status Event", result)
 	ctx.JSON(http.StatusOK, res)
 }
 
-func (ec *eventController) Post3Event(ctx *gin.Context) {
-	var PageNumber dto.EventPaginationResponse
-	if err := ctx.ShouldBind(&PageNumber); err != nil {
-		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+func (ec *eventController) DeleteEvent(ctx *gin.Context) {
+	id := ctx.Param("id")
+	uuid, err := uuid.Parse(id)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	ec.page = PageNumber.PageNumber
-	res := utils.BuildResponseSuccess("Berhasil Menambahkan 3 List Event", utils.EmptyObj{})
+	if err := ec.policyService.AuthorizeEvent(ctx.Request.Context(), actorFromContext(ctx), uuid); err != nil {
+		abortWithPolicyError(ctx, err)
+		return
+	}
+
+	if err := ec.eventService.DeleteEvent(ctx, uuid); err != nil {
+		res := utils.BuildResponseFailed("Gagal Delete Event", err.Error(), utils.EmptyObj{})
+		ctx.JSON(eventStatusErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Delete Event", utils.EmptyObj{})
 	ctx.JSON(http.StatusOK, res)
 }
 
-func (ec *eventController) Get3Event(ctx *gin.Context) {
-	fmt.Print(ec.page)
-	events, err := ec.eventService.Get3Event(ctx, ec.page * 3)
+func (ec *eventController) GetAllEventLastTransaksi(ctx *gin.Context) {
+	id := ctx.Param("event_id")
+	uuid, err := uuid.Parse(id)
 	if err != nil {
-		res := utils.BuildResponseFailed("Gagal Mendapatkan List Event", err.Error(), utils.EmptyObj{})
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
-	
-	res := utils.BuildResponseSuccess("Berhasil Mendapatkan List Event", events)
+	events, err := ec.transaksiService.GetAllEventLastTransaksi(ctx, uuid)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan List Transaksi", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan List Transaksi", events)
 	ctx.JSON(http.StatusOK, res)
 }
 
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/services"
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type KabarTerbaruController interface {
+	CreateKabarTerbaru(ctx *gin.Context)
+	GetKabarTerbaruByEventID(ctx *gin.Context)
+	UpdateKabarTerbaru(ctx *gin.Context)
+	DeleteKabarTerbaru(ctx *gin.Context)
+}
+
+type kabarTerbaruController struct {
+	kabarTerbaruService services.KabarTerbaruService
+	eventService        services.EventService
+	policyService       services.PolicyService
+}
+
+func NewKabarTerbaruController(ks services.KabarTerbaruService, es services.EventService, ps services.PolicyService) KabarTerbaruController {
+	return &kabarTerbaruController{
+		kabarTerbaruService: ks,
+		eventService:        es,
+		policyService:       ps,
+	}
+}
+
+func kabarTerbaruErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrKabarTerbaruNotFound), errors.Is(err, services.ErrResourceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrKabarTerbaruEventHidden):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// parseKabarTerbaruPath membaca id event dan id kabar dari path.
+func parseKabarTerbaruPath(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	eventID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return uuid.Nil, uuid.Nil, false
+	}
+	kabarID, err := uuid.Parse(ctx.Param("kabar_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return eventID, kabarID, true
+}
+
+func (kc *kabarTerbaruController) CreateKabarTerbaru(ctx *gin.Context) {
+	eventID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var kabarDTO dto.KabarTerbaruCreateDTO
+	if err := ctx.ShouldBind(&kabarDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	actor := actorFromContext(ctx)
+	if err := kc.policyService.AuthorizeEvent(ctx.Request.Context(), actor, eventID); err != nil {
+		abortWithPolicyError(ctx, err)
+		return
+	}
+
+	result, err := kc.kabarTerbaruService.CreateKabarTerbaru(ctx.Request.Context(), actor, eventID, kabarDTO)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Menambahkan Kabar Terbaru", err.Error(), utils.EmptyObj{})
+		ctx.JSON(kabarTerbaruErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menambahkan Kabar Terbaru", services.ToKabarTerbaruResponse(result))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *kabarTerbaruController) GetKabarTerbaruByEventID(ctx *gin.Context) {
+	eventID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	event, err := kc.eventService.GetEventByID(ctx.Request.Context(), eventID)
+	if err != nil || (!entities.IsEventPublicStatus(event.Status) && !services.CanViewHiddenEvents(optionalActorFromContext(ctx), event.UserID)) {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Kabar Terbaru", services.ErrResourceNotFound.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	result, err := kc.kabarTerbaruService.GetKabarTerbaruByEventID(ctx.Request.Context(), eventID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Kabar Terbaru", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Kabar Terbaru", services.ToKabarTerbaruResponses(result))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *kabarTerbaruController) UpdateKabarTerbaru(ctx *gin.Context) {
+	eventID, kabarID, ok := parseKabarTerbaruPath(ctx)
+	if !ok {
+		return
+	}
+
+	var kabarDTO dto.KabarTerbaruUpdateDTO
+	if err := ctx.ShouldBindJSON(&kabarDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := kc.policyService.AuthorizeEvent(ctx.Request.Context(), actorFromContext(ctx), eventID); err != nil {
+		abortWithPolicyError(ctx, err)
+		return
+	}
+
+	result, err := kc.kabarTerbaruService.UpdateKabarTerbaru(ctx.Request.Context(), eventID, kabarID, kabarDTO)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mengupdate Kabar Terbaru", err.Error(), utils.EmptyObj{})
+		ctx.JSON(kabarTerbaruErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mengupdate Kabar Terbaru", services.ToKabarTerbaruResponse(result))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *kabarTerbaruController) DeleteKabarTerbaru(ctx *gin.Context) {
+	eventID, kabarID, ok := parseKabarTerbaruPath(ctx)
+	if !ok {
+		return
+	}
+
+	if err := kc.policyService.AuthorizeEvent(ctx.Request.Context(), actorFromContext(ctx), eventID); err != nil {
+		abortWithPolicyError(ctx, err)
+		return
+	}
+
+	if err := kc.kabarTerbaruService.DeleteKabarTerbaru(ctx.Request.Context(), eventID, kabarID); err != nil {
+		res := utils.BuildResponseFailed("Gagal Menghapus Kabar Terbaru", err.Error(), utils.EmptyObj{})
+		ctx.JSON(kabarTerbaruErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menghapus Kabar Terbaru", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/services"
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type KomentarController interface {
+	GetKomentarByEventID(ctx *gin.Context)
+	CreateKomentar(ctx *gin.Context)
+	UpdateKomentar(ctx *gin.Context)
+	DeleteKomentar(ctx *gin.Context)
+	ReportKomentar(ctx *gin.Context)
+	HideKomentar(ctx *gin.Context)
+	UnhideKomentar(ctx *gin.Context)
+	GetReportedKomentar(ctx *gin.Context)
+}
+
+type komentarController struct {
+	komentarService services.KomentarService
+	eventService    services.EventService
+}
+
+func NewKomentarController(ks services.KomentarService, es services.EventService) KomentarController {
+	return &komentarController{
+		komentarService: ks,
+		eventService:    es,
+	}
+}
+
+// abortWithKomentarError menulis response gagal dengan status yang sesuai,
+// termasuk header Retry-After ketika user terkena rate limit.
+func abortWithKomentarError(ctx *gin.Context, message string, err error) {
+	status := http.StatusBadRequest
+	var rateLimited *services.KomentarRateLimitedError
+	switch {
+	case errors.As(err, &rateLimited):
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(rateLimited.RetryAfter.Seconds()))))
+		status = http.StatusTooManyRequests
+	case errors.Is(err, services.ErrKomentarNotFound), errors.Is(err, services.ErrResourceNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrKomentarAlreadyReported), errors.Is(err, services.ErrKomentarEventHidden):
+		status = http.StatusConflict
+	}
+
+	res := utils.BuildResponseFailed(message, err.Error(), utils.EmptyObj{})
+	ctx.JSON(status, res)
+}
+
+func parseKomentarID(ctx *gin.Context) (uuid.UUID, bool) {
+	komentarID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return uuid.Nil, false
+	}
+	return komentarID, true
+}
+
+func (kc *komentarController) GetKomentarByEventID(ctx *gin.Context) {
+	eventID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var listQuery dto.KomentarListQuery
+	if err := ctx.ShouldBindQuery(&listQuery); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Query", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	event, err := kc.eventService.GetEventByID(ctx.Request.Context(), eventID)
+	moderator := err == nil && services.CanViewHiddenEvents(optionalActorFromContext(ctx), event.UserID)
+	if err != nil || (!entities.IsEventPublicStatus(event.Status) && !moderator) {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Komentar", services.ErrResourceNotFound.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	// Komentar yang disembunyikan tetap terlihat oleh pemilik event dan admin
+	result, meta, err := kc.komentarService.GetKomentarByEventID(ctx.Request.Context(), eventID, moderator, listQuery)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Komentar", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccessWithMeta("Berhasil Mendapatkan Komentar", services.ToKomentarResponses(result, moderator), meta)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *komentarController) CreateKomentar(ctx *gin.Context) {
+	eventID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var komentarDTO dto.KomentarCreateDTO
+	if err := ctx.ShouldBind(&komentarDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := kc.komentarService.CreateKomentar(ctx.Request.Context(), actorFromContext(ctx), eventID, komentarDTO)
+	if err != nil {
+		abortWithKomentarError(ctx, "Gagal Menambahkan Komentar", err)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menambahkan Komentar", services.ToKomentarResponse(result, false))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *komentarController) UpdateKomentar(ctx *gin.Context) {
+	komentarID, ok := parseKomentarID(ctx)
+	if !ok {
+		return
+	}
+
+	var komentarDTO dto.KomentarUpdateDTO
+	if err := ctx.ShouldBind(&komentarDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := kc.komentarService.UpdateKomentar(ctx.Request.Context(), actorFromContext(ctx), komentarID, komentarDTO)
+	if err != nil {
+		abortWithKomentarError(ctx, "Gagal Mengupdate Komentar", err)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mengupdate Komentar", services.ToKomentarResponse(result, false))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *komentarController) DeleteKomentar(ctx *gin.Context) {
+	komentarID, ok := parseKomentarID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := kc.komentarService.DeleteKomentar(ctx.Request.Context(), actorFromContext(ctx), komentarID); err != nil {
+		abortWithKomentarError(ctx, "Gagal Menghapus Komentar", err)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menghapus Komentar", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *komentarController) ReportKomentar(ctx *gin.Context) {
+	komentarID, ok := parseKomentarID(ctx)
+	if !ok {
+		return
+	}
+
+	var reportDTO dto.KomentarReportDTO
+	if err := ctx.ShouldBind(&reportDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := kc.komentarService.ReportKomentar(ctx.Request.Context(), actorFromContext(ctx), komentarID, reportDTO); err != nil {
+		abortWithKomentarError(ctx, "Gagal Melaporkan Komentar", err)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Melaporkan Komentar", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *komentarController) HideKomentar(ctx *gin.Context) {
+	komentarID, ok := parseKomentarID(ctx)
+	if !ok {
+		return
+	}
+
+	var hideDTO dto.KomentarHideDTO
+	if err := ctx.ShouldBind(&hideDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := kc.komentarService.HideKomentar(ctx.Request.Context(), actorFromContext(ctx), komentarID, hideDTO)
+	if err != nil {
+		abortWithKomentarError(ctx, "Gagal Menyembunyikan Komentar", err)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menyembunyikan Komentar", services.ToKomentarResponse(result, true))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *komentarController) UnhideKomentar(ctx *gin.Context) {
+	komentarID, ok := parseKomentarID(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := kc.komentarService.UnhideKomentar(ctx.Request.Context(), actorFromContext(ctx), komentarID)
+	if err != nil {
+		abortWithKomentarError(ctx, "Gagal Menampilkan Komentar", err)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menampilkan Komentar", services.ToKomentarResponse(result, true))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *komentarController) GetReportedKomentar(ctx *gin.Context) {
+	var listQuery dto.KomentarListQuery
+	if err := ctx.ShouldBindQuery(&listQuery); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Query", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, meta, err := kc.komentarService.GetReportedKomentar(ctx.Request.Context(), listQuery)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Komentar Yang Dilaporkan", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccessWithMeta("Berhasil Mendapatkan Komentar Yang Dilaporkan", services.ToKomentarResponses(result, true), meta)
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/services"
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type KYCController interface {
+	SubmitKYC(ctx *gin.Context)
+	GetMyKYC(ctx *gin.Context)
+	GetKYCQueue(ctx *gin.Context)
+	GetKYCByID(ctx *gin.Context)
+	GetKYCDocument(ctx *gin.Context)
+	ApproveKYC(ctx *gin.Context)
+	RejectKYC(ctx *gin.Context)
+}
+
+type kycController struct {
+	kycService services.KYCService
+}
+
+func NewKYCController(ks services.KYCService) KYCController {
+	return &kycController{
+		kycService: ks,
+	}
+}
+
+func (kc *kycController) SubmitKYC(ctx *gin.Context) {
+	var kycDTO dto.KYCSubmitDTO
+	if err := ctx.ShouldBind(&kycDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	ktp, _ := ctx.FormFile(services.KYCDocumentKTP)
+	selfie, _ := ctx.FormFile(services.KYCDocumentSelfie)
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	result, err := kc.kycService.SubmitKYC(ctx.Request.Context(), userID, kycDTO, ktp, selfie)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mengajukan KYC", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mengajukan KYC", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *kycController) GetMyKYC(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	result, err := kc.kycService.GetMyKYC(ctx.Request.Context(), userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan KYC", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan KYC", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *kycController) GetKYCQueue(ctx *gin.Context) {
+	result, err := kc.kycService.GetKYCQueue(ctx.Request.Context(), ctx.Query("status"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan List KYC", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan List KYC", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *kycController) GetKYCByID(ctx *gin.Context) {
+	kycID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := kc.kycService.GetKYCByID(ctx.Request.Context(), kycID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan KYC", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan KYC", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *kycController) GetKYCDocument(ctx *gin.Context) {
+	kycID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	path, err := kc.kycService.GetKYCDocumentPath(ctx.Request.Context(), kycID, ctx.Param("document"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Dokumen KYC", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.File(path)
+}
+
+func (kc *kycController) ApproveKYC(ctx *gin.Context) {
+	kycID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	reviewerID := ctx.MustGet("userID").(uuid.UUID)
+	if err := kc.kycService.ApproveKYC(ctx.Request.Context(), reviewerID, kycID); err != nil {
+		res := utils.BuildResponseFailed("Gagal Menyetujui KYC", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menyetujui KYC", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (kc *kycController) RejectKYC(ctx *gin.Context) {
+	kycID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var rejectDTO dto.KYCRejectDTO
+	if err := ctx.ShouldBind(&rejectDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	reviewerID := ctx.MustGet("userID").(uuid.UUID)
+	if err := kc.kycService.RejectKYC(ctx.Request.Context(), reviewerID, kycID, rejectDTO.Reason); err != nil {
+		res := utils.BuildResponseFailed("Gagal Menolak KYC", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menolak KYC", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
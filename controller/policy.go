@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/golang-clean_template/services"
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func actorFromContext(ctx *gin.Context) services.Actor {
+	return services.Actor{
+		UserID: ctx.MustGet("userID").(uuid.UUID),
+		Role:   ctx.GetString("role"),
+	}
+}
+
+// abortWithPolicyError mengubah hasil pengecekan policy menjadi response yang
+// sama di semua handler.
+func abortWithPolicyError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrForbidden):
+		res := utils.BuildResponseFailed("Gagal Memproses Request", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, res)
+	case errors.Is(err, services.ErrResourceNotFound):
+		res := utils.BuildResponseFailed("Gagal Memproses Request", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, res)
+	default:
+		res := utils.BuildResponseFailed("Gagal Memproses Request", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, res)
+	}
+}
+
+// optionalActorFromContext mengembalikan nil jika request berasal dari
+// pengunjung yang tidak login.
+func optionalActorFromContext(ctx *gin.Context) *services.Actor {
+	if _, ok := ctx.Get("userID"); !ok {
+		return nil
+	}
+	actor := actorFromContext(ctx)
+	return &actor
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/golang-clean_template/services"
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SessionController interface {
+	GetSessions(ctx *gin.Context)
+	RevokeSession(ctx *gin.Context)
+	RevokeOtherSessions(ctx *gin.Context)
+}
+
+type sessionController struct {
+	sessionService services.SessionService
+}
+
+func NewSessionController(ss services.SessionService) SessionController {
+	return &sessionController{
+		sessionService: ss,
+	}
+}
+
+func (sc *sessionController) GetSessions(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	sessionID := ctx.MustGet("sessionID").(uuid.UUID)
+
+	result, err := sc.sessionService.GetSessions(ctx.Request.Context(), userID, sessionID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Sesi", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Sesi", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (sc *sessionController) RevokeSession(ctx *gin.Context) {
+	sessionID, err := uuid.Parse(ctx.Param("session_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	if err := sc.sessionService.RevokeSession(ctx.Request.Context(), userID, sessionID); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		res := utils.BuildResponseFailed("Gagal Mencabut Sesi", err.Error(), utils.EmptyObj{})
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mencabut Sesi", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (sc *sessionController) RevokeOtherSessions(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	sessionID := ctx.MustGet("sessionID").(uuid.UUID)
+
+	if err := sc.sessionService.RevokeOtherSessions(ctx.Request.Context(), userID, sessionID); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mencabut Sesi", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mencabut Sesi Lain", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
@@ -1,7 +1,11 @@
 package controller
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Caknoooo/golang-clean_template/dto"
@@ -18,30 +22,68 @@ type UserController interface {
 	GetAllUser(ctx *gin.Context)
 	MeUser(ctx *gin.Context)
 	LoginUser(ctx *gin.Context)
+	LoginTwoFactor(ctx *gin.Context)
+	OIDCLogin(ctx *gin.Context)
+	OIDCCallback(ctx *gin.Context)
+	OIDCReauth(ctx *gin.Context)
+	VerifyEmail(ctx *gin.Context)
+	ResendVerificationEmail(ctx *gin.Context)
+	ForgotPassword(ctx *gin.Context)
+	ResetPassword(ctx *gin.Context)
+	ChangePassword(ctx *gin.Context)
+	ChangeEmail(ctx *gin.Context)
+	ConfirmEmailChange(ctx *gin.Context)
+	RefreshToken(ctx *gin.Context)
 	LogoutUser(ctx *gin.Context)
+	LogoutAllUser(ctx *gin.Context)
 	UpdateUser(ctx *gin.Context)
 	DeleteUser(ctx *gin.Context)
+	CancelDeletion(ctx *gin.Context)
+	GetHandoverOffers(ctx *gin.Context)
+	AcceptHandover(ctx *gin.Context)
+	RejectHandover(ctx *gin.Context)
 	CreateTransaksiUser(ctx *gin.Context)
 	GetTransaksiUser(ctx *gin.Context)
+	PromoteUser(ctx *gin.Context)
+	DemoteUser(ctx *gin.Context)
+	UnlockUser(ctx *gin.Context)
+	GetLoginAttempts(ctx *gin.Context)
+	SetupTwoFactor(ctx *gin.Context)
+	EnableTwoFactor(ctx *gin.Context)
+	DisableTwoFactor(ctx *gin.Context)
+	RegenerateRecoveryCodes(ctx *gin.Context)
+	StepUp(ctx *gin.Context)
 }
 
 type userController struct {
-	jwtService        services.JWTService
-	userService       services.UserService
-	transaksiService  services.TransaksiService
-	pembayaranService services.PembayaranService
-	eventService      services.EventService
-	db                *gorm.DB
+	jwtService          services.JWTService
+	refreshTokenService services.RefreshTokenService
+	loginGuardService   services.LoginGuardService
+	twoFactorService    services.TwoFactorService
+	oidcService         services.OIDCService
+	accountDeletion     services.AccountDeletionService
+	sessionService      services.SessionService
+	userService         services.UserService
+	transaksiService    services.TransaksiService
+	pembayaranService   services.PembayaranService
+	eventService        services.EventService
+	db                  *gorm.DB
 }
 
-func NewUserController(us services.UserService, ts services.TransaksiService, ps services.PembayaranService, es services.EventService, db *gorm.DB, jwt services.JWTService) UserController {
+func NewUserController(us services.UserService, ts services.TransaksiService, ps services.PembayaranService, es services.EventService, db *gorm.DB, jwt services.JWTService, rs services.RefreshTokenService, lg services.LoginGuardService, tfs services.TwoFactorService, oidc services.OIDCService, ads services.AccountDeletionService, ss services.SessionService) UserController {
 	return &userController{
-		jwtService:        jwt,
-		userService:       us,
-		transaksiService:  ts,
-		pembayaranService: ps,
-		eventService:      es,
-		db:                db,
+		jwtService:          jwt,
+		refreshTokenService: rs,
+		loginGuardService:   lg,
+		twoFactorService:    tfs,
+		oidcService:         oidc,
+		accountDeletion:     ads,
+		sessionService:      ss,
+		userService:         us,
+		transaksiService:    ts,
+		pembayaranService:   ps,
+		eventService:        es,
+		db:                  db,
 	}
 }
 
@@ -89,7 +131,7 @@ func (uc *userController) MeUser(ctx *gin.Context) {
 		return
 	}
 
-	result, err := uc.userService.GetUserByID(ctx.Request.Context(), userID)
+	result, err := uc.userService.GetMe(ctx.Request.Context(), userID)
 	if err != nil {
 		res := utils.BuildResponseFailed("Gagal Mendapatkan User", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
@@ -102,9 +144,40 @@ func (uc *userController) MeUser(ctx *gin.Context) {
 
 func (uc *userController) LoginUser(ctx *gin.Context) {
 	var userLoginDTO dto.UserLoginDTO
-	err := ctx.ShouldBind(&userLoginDTO)
+	if err := ctx.ShouldBind(&userLoginDTO); err != nil {
+		response := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	attempt := entities.LoginAttempt{
+		Email:     userLoginDTO.Email,
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+
+	if err := uc.loginGuardService.ReserveLoginAttempt(ctx.Request.Context(), attempt.Email, attempt.IPAddress); err != nil {
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			response := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, response)
+			return
+		}
+		response := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
 	res, _ := uc.userService.Verify(ctx.Request.Context(), userLoginDTO.Email, userLoginDTO.Password)
 	if !res {
+		attempt.Reason = "invalid_credentials"
+		if user, err := uc.userService.GetUserByEmail(ctx.Request.Context(), userLoginDTO.Email); err == nil {
+			attempt.UserID = &user.ID
+		}
+		if err := uc.loginGuardService.RecordLoginFailure(ctx.Request.Context(), attempt); err != nil {
+			log.Printf("error recording login failure: %v", err)
+		}
 		response := utils.BuildResponseFailed("Gagal Login", "Email atau Password Salah", utils.EmptyObj{})
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
 		return
@@ -116,11 +189,40 @@ func (uc *userController) LoginUser(ctx *gin.Context) {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
 		return
 	}
-	token := uc.jwtService.GenerateToken(user.ID, user.Role)
-	userResponse := entities.Authorization{
-		Token:     token,
-		Role:      user.Role,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+
+	attempt.UserID = &user.ID
+
+	// Percobaan login baru dianggap berhasil setelah kode dua langkah benar,
+	// agar counter kegagalan tidak direset hanya dengan password.
+	twoFactorEnabled, err := uc.twoFactorService.IsTwoFactorEnabled(ctx.Request.Context(), user.ID)
+	if err != nil {
+		response := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+	if !twoFactorEnabled {
+		if err := uc.loginGuardService.RecordLoginSuccess(ctx.Request.Context(), attempt); err != nil {
+			log.Printf("error recording login success: %v", err)
+		}
+	}
+
+	if user.EmailVerifiedAt == nil {
+		response := utils.BuildResponseFailed("Gagal Login", "Email Belum Diverifikasi", utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		return
+	}
+
+	if twoFactorEnabled {
+		response := utils.BuildResponseSuccess("Verifikasi Dua Langkah Diperlukan", uc.twoFactorService.CreateLoginChallenge(user))
+		ctx.JSON(http.StatusOK, response)
+		return
+	}
+
+	userResponse, err := uc.refreshTokenService.IssueTokenPair(ctx.Request.Context(), user, sessionClient(ctx))
+	if err != nil {
+		response := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
 	}
 
 	// ctx.SetCookie("token", token, 60*60*24, "/", "localhost", false, true)
@@ -129,14 +231,330 @@ func (uc *userController) LoginUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
-func (uc *userController) LogoutUser(ctx *gin.Context) {
-	token := ctx.GetHeader("Authorization")
+func (uc *userController) LoginTwoFactor(ctx *gin.Context) {
+	var loginDTO dto.TwoFactorLoginDTO
+	if err := ctx.ShouldBind(&loginDTO); err != nil {
+		response := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	userID, email, err := uc.jwtService.ValidateActionToken(loginDTO.ChallengeToken, services.TwoFactorLoginPurpose)
+	if err != nil {
+		response := utils.BuildResponseFailed("Gagal Login", "Sesi Login Tidak Valid Atau Kadaluarsa", utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	attempt := entities.LoginAttempt{
+		Email:     email,
+		UserID:    &userID,
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+
+	if err := uc.loginGuardService.ReserveLoginAttempt(ctx.Request.Context(), attempt.Email, attempt.IPAddress); err != nil {
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			response := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, response)
+			return
+		}
+		response := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := uc.twoFactorService.VerifyTwoFactorCode(ctx.Request.Context(), userID, loginDTO.Code); err != nil {
+		attempt.Reason = "invalid_two_factor_code"
+		if err := uc.loginGuardService.RecordLoginFailure(ctx.Request.Context(), attempt); err != nil {
+			log.Printf("error recording login failure: %v", err)
+		}
+		response := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := uc.loginGuardService.RecordLoginSuccess(ctx.Request.Context(), attempt); err != nil {
+		log.Printf("error recording login success: %v", err)
+	}
+
+	user, err := uc.userService.GetUserByID(ctx.Request.Context(), userID)
+	if err != nil {
+		response := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	userResponse, err := uc.refreshTokenService.IssueTokenPair(ctx.Request.Context(), user, sessionClient(ctx))
+	if err != nil {
+		response := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := utils.BuildResponseSuccess("Berhasil Login", userResponse)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (uc *userController) OIDCLogin(ctx *gin.Context) {
+	authorization, err := uc.oidcService.GetAuthorizationURL(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	setOIDCStateCookie(ctx, authorization.StateCookie, authorization.StateCookieMaxAge, authorization.SecureCookie)
+	ctx.Redirect(http.StatusFound, authorization.URL)
+}
+
+// OIDCReauth dipanggil oleh frontend yang sedang login, sehingga URL provider
+// dikembalikan sebagai JSON dan bukan redirect.
+func (uc *userController) OIDCReauth(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	sessionID := ctx.MustGet("sessionID").(uuid.UUID)
+	authorization, err := uc.oidcService.GetReauthURL(ctx.Request.Context(), userID, sessionID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Memulai Konfirmasi Identitas", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	setOIDCStateCookie(ctx, authorization.StateCookie, authorization.StateCookieMaxAge, authorization.SecureCookie)
+	res := utils.BuildResponseSuccess("Berhasil Memulai Konfirmasi Identitas", dto.OIDCAuthorizationResponse{
+		AuthorizationURL: authorization.URL,
+	})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) OIDCCallback(ctx *gin.Context) {
+	stateCookie, _ := ctx.Cookie(services.OIDCStateCookie)
+	setOIDCStateCookie(ctx, "", -1, false)
+
+	if providerError := ctx.Query("error"); providerError != "" {
+		res := utils.BuildResponseFailed("Gagal Login", providerError, utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := uc.oidcService.HandleCallback(ctx.Request.Context(), ctx.Query("state"), stateCookie, ctx.Query("code"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	if result.Reauth != nil {
+		res := utils.BuildResponseSuccess("Berhasil Mengonfirmasi Identitas", result.Reauth)
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+	user := result.User
+
+	// Login lewat provider tetap melewati verifikasi dua langkah milik aplikasi
+	twoFactorEnabled, err := uc.twoFactorService.IsTwoFactorEnabled(ctx.Request.Context(), user.ID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	if twoFactorEnabled {
+		res := utils.BuildResponseSuccess("Verifikasi Dua Langkah Diperlukan", uc.twoFactorService.CreateLoginChallenge(user))
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
+	tokenPair, err := uc.refreshTokenService.IssueTokenPair(ctx.Request.Context(), user, sessionClient(ctx))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Login", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Login", tokenPair)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) VerifyEmail(ctx *gin.Context) {
+	token := ctx.Query("token")
 	if token == "" {
-		res := utils.BuildResponseFailed("Gagal Logout", "Token Kosong", utils.EmptyObj{})
+		res := utils.BuildResponseFailed("Gagal Verifikasi Email", "Token Kosong", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := uc.userService.VerifyEmail(ctx.Request.Context(), token); err != nil {
+		res := utils.BuildResponseFailed("Gagal Verifikasi Email", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Verifikasi Email", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) ResendVerificationEmail(ctx *gin.Context) {
+	var emailDTO dto.UserEmailDTO
+	if err := ctx.ShouldBind(&emailDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := uc.userService.ResendVerificationEmail(ctx.Request.Context(), emailDTO.Email); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mengirim Email Verifikasi", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Email Verifikasi Akan Dikirim Jika Email Terdaftar", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) ForgotPassword(ctx *gin.Context) {
+	var emailDTO dto.UserEmailDTO
+	if err := ctx.ShouldBind(&emailDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := uc.userService.ForgotPassword(ctx.Request.Context(), emailDTO.Email); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mengirim Email Reset Password", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Email Reset Password Akan Dikirim Jika Email Terdaftar", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) ResetPassword(ctx *gin.Context) {
+	var resetDTO dto.UserResetPasswordDTO
+	if err := ctx.ShouldBind(&resetDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := uc.userService.ResetPassword(ctx.Request.Context(), resetDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Reset Password", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Reset Password", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) ChangePassword(ctx *gin.Context) {
+	var passwordDTO dto.UserChangePasswordDTO
+	if err := ctx.ShouldBind(&passwordDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	reauthenticated, err := uc.checkReauth(ctx, passwordDTO.ReauthToken)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mengubah Password", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusForbidden, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	if err := uc.userService.ChangePassword(ctx.Request.Context(), userID, passwordDTO, reauthenticated); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mengubah Password", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	// Seluruh sesi sudah dicabut, sesi baru diberikan untuk device yang sedang dipakai
+	user, err := uc.userService.GetUserByID(ctx.Request.Context(), userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan User", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := uc.refreshTokenService.IssueTokenPair(ctx.Request.Context(), user, sessionClient(ctx))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Membuat Token", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mengubah Password", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) ChangeEmail(ctx *gin.Context) {
+	var emailDTO dto.UserChangeEmailDTO
+	if err := ctx.ShouldBind(&emailDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	reauthenticated, err := uc.checkReauth(ctx, emailDTO.ReauthToken)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mengubah Email", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusForbidden, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	if err := uc.userService.RequestEmailChange(ctx.Request.Context(), userID, emailDTO, reauthenticated); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mengubah Email", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Link Konfirmasi Telah Dikirim Ke Email Baru", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) ConfirmEmailChange(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		res := utils.BuildResponseFailed("Gagal Konfirmasi Email", "Token Kosong", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := uc.userService.ConfirmEmailChange(ctx.Request.Context(), token); err != nil {
+		res := utils.BuildResponseFailed("Gagal Konfirmasi Email", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mengubah Email", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) RefreshToken(ctx *gin.Context) {
+	var refreshDTO dto.RefreshTokenDTO
+	if err := ctx.ShouldBind(&refreshDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
 
+	result, err := uc.refreshTokenService.RotateRefreshToken(ctx.Request.Context(), refreshDTO.RefreshToken, sessionClient(ctx))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Refresh Token", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Refresh Token", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) LogoutUser(ctx *gin.Context) {
+	token := ctx.MustGet("token").(string)
+
 	err := uc.jwtService.InvalidateToken(token)
 	if err != nil {
 		res := utils.BuildResponseFailed("Gagal Logout", err.Error(), utils.EmptyObj{})
@@ -144,6 +562,23 @@ func (uc *userController) LogoutUser(ctx *gin.Context) {
 		return
 	}
 
+	err = uc.sessionService.RevokeSession(ctx.Request.Context(), ctx.MustGet("userID").(uuid.UUID), ctx.MustGet("sessionID").(uuid.UUID))
+	if err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+		res := utils.BuildResponseFailed("Gagal Logout", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	// Refresh token bersifat opsional, jika dikirim maka family-nya ikut
+	// dicabut. Refresh token yang tidak valid tidak menggagalkan logout karena
+	// access token sudah dicabut di atas.
+	var refreshDTO dto.RefreshTokenDTO
+	if err := ctx.ShouldBind(&refreshDTO); err == nil {
+		if err := uc.refreshTokenService.RevokeRefreshToken(ctx.Request.Context(), refreshDTO.RefreshToken); err != nil {
+			log.Printf("error revoking refresh token on logout: %v", err)
+		}
+	}
+
 	ctx.Header("Set-Cookie", "token=; Path=/; Max-Age=-1")
 	ctx.Header("Expires", "Thu, 01 Jan 1970 00:00:00 GMT")
 
@@ -151,6 +586,60 @@ func (uc *userController) LogoutUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+func (uc *userController) LogoutAllUser(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uuid.UUID)
+
+	if err := uc.revokeAllSession(ctx, userID); err != nil {
+		res := utils.BuildResponseFailed("Gagal Logout Dari Semua Device", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	ctx.Header("Set-Cookie", "token=; Path=/; Max-Age=-1")
+	ctx.Header("Expires", "Thu, 01 Jan 1970 00:00:00 GMT")
+
+	res := utils.BuildResponseSuccess("Berhasil Logout Dari Semua Device", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+// setOIDCStateCookie hanya dikirim ke endpoint OIDC. SameSite=Lax tetap
+// mengirim cookie saat provider me-redirect browser kembali ke callback.
+func setOIDCStateCookie(ctx *gin.Context, value string, maxAge int, secure bool) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(services.OIDCStateCookie, value, maxAge, "/api/user/oidc", "", secure, true)
+}
+
+// checkReauth memeriksa reauth token hasil login ulang lewat provider OIDC.
+// Token yang dikirim namun tidak valid menggagalkan request, bukan kembali ke
+// pengecekan password.
+func (uc *userController) checkReauth(ctx *gin.Context, reauthToken string) (bool, error) {
+	if reauthToken == "" {
+		return false, nil
+	}
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	sessionID := ctx.MustGet("sessionID").(uuid.UUID)
+	if err := uc.oidcService.CheckReauthToken(ctx.Request.Context(), userID, sessionID, reauthToken); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sessionClient mengambil informasi device untuk dicatat pada session login.
+func sessionClient(ctx *gin.Context) dto.SessionClient {
+	return dto.SessionClient{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+}
+
+// revokeAllSession mencabut seluruh access token dan refresh token milik user.
+func (uc *userController) revokeAllSession(ctx *gin.Context, userID uuid.UUID) error {
+	if err := uc.refreshTokenService.RevokeAllRefreshToken(ctx.Request.Context(), userID); err != nil {
+		return err
+	}
+	return uc.jwtService.InvalidateAllUserToken(userID)
+}
+
 func (uc *userController) UpdateUser(ctx *gin.Context) {
 	var userDTO dto.UserUpdateDTO
 	if err := ctx.ShouldBind(&userDTO); err != nil {
@@ -178,25 +667,116 @@ func (uc *userController) UpdateUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// DeleteUser menjadwalkan penghapusan akun. Data pribadi baru dianonimkan
+// setelah masa tenggang sehingga user masih bisa membatalkannya.
 func (uc *userController) DeleteUser(ctx *gin.Context) {
-	token := ctx.MustGet("token").(string)
-	userID, err := uc.jwtService.GetUserIDByToken(token)
-	if err != nil {
-		res := utils.BuildResponseFailed("Gagal Memproses Request", "Token Tidak Valid", nil)
+	var deletionDTO dto.AccountDeletionRequestDTO
+	if err := ctx.ShouldBind(&deletionDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
 
-	if err = uc.userService.DeleteUser(ctx.Request.Context(), userID); err != nil {
+	reauthenticated, err := uc.checkReauth(ctx, deletionDTO.ReauthToken)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Menghapus User", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusForbidden, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	result, err := uc.accountDeletion.RequestDeletion(ctx.Request.Context(), userID, deletionDTO, reauthenticated)
+	if err != nil {
+		var activeCampaignErr *services.ActiveCampaignError
+		if errors.As(err, &activeCampaignErr) {
+			res := utils.BuildResponseFailed("Gagal Menghapus User", err.Error(), activeCampaignErr.Events)
+			ctx.AbortWithStatusJSON(http.StatusConflict, res)
+			return
+		}
 		res := utils.BuildResponseFailed("Gagal Menghapus User", err.Error(), utils.EmptyObj{})
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
 
-	res := utils.BuildResponseSuccess("Berhasil Menghapus User", utils.EmptyObj{})
+	res := utils.BuildResponseSuccess("Penghapusan Akun Berhasil Dijadwalkan", result)
 	ctx.JSON(http.StatusOK, res)
 }
 
+func (uc *userController) CancelDeletion(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	if err := uc.accountDeletion.CancelDeletion(ctx.Request.Context(), userID); err != nil {
+		res := utils.BuildResponseFailed("Gagal Membatalkan Penghapusan Akun", err.Error(), utils.EmptyObj{})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Membatalkan Penghapusan Akun", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) GetHandoverOffers(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	result, err := uc.accountDeletion.GetHandoverOffers(ctx.Request.Context(), userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Tawaran Penyerahan Campaign", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Tawaran Penyerahan Campaign", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) AcceptHandover(ctx *gin.Context) {
+	handoverID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	if err := uc.accountDeletion.AcceptHandover(ctx.Request.Context(), userID, handoverID); err != nil {
+		res := utils.BuildResponseFailed("Gagal Menerima Penyerahan Campaign", err.Error(), utils.EmptyObj{})
+		ctx.JSON(handoverErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menerima Penyerahan Campaign", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) RejectHandover(ctx *gin.Context) {
+	handoverID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	if err := uc.accountDeletion.RejectHandover(ctx.Request.Context(), userID, handoverID); err != nil {
+		res := utils.BuildResponseFailed("Gagal Menolak Penyerahan Campaign", err.Error(), utils.EmptyObj{})
+		ctx.JSON(handoverErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menolak Penyerahan Campaign", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func handoverErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrHandoverNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrHandoverAlreadyAccepted):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrHandoverRecipientInvalid), errors.Is(err, services.ErrHandoverRecipientNoKYC):
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
 func (uc *userController) CreateTransaksiUser(ctx *gin.Context) {
 	// Mendapatkan user ID dari token yang di-passing melalui context
 	token := ctx.MustGet("token").(string)
@@ -215,6 +795,27 @@ func (uc *userController) CreateTransaksiUser(ctx *gin.Context) {
 		return
 	}
 
+	// Mendapatkan ID event dari path parameter
+	eventID, err := uuid.Parse(ctx.Param("event_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	// Event dicek lebih dulu agar pembayaran tidak dibuat untuk event yang tidak menerima donasi
+	event, err := uc.eventService.GetEventByID(ctx.Request.Context(), eventID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Event", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	if event.Status != entities.EventStatusActive {
+		res := utils.BuildResponseFailed("Gagal Menambahkan Transaksi", services.ErrEventNotAcceptingDonation.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusConflict, res)
+		return
+	}
+
 	var listBank entities.ListBank
 	if err := uc.db.Where("id = ?", pembayaran.ListBankID).First(&listBank).Error; err != nil {
 		res := utils.BuildResponseFailed("ID Bank Tidak Ditemukan", err.Error(), utils.EmptyObj{})
@@ -230,15 +831,7 @@ func (uc *userController) CreateTransaksiUser(ctx *gin.Context) {
 		return
 	}
 
-	// Mendapatkan ID event dari path parameter
-	eventID, err := uuid.Parse(ctx.Param("event_id"))
-	if err != nil {
-		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
-		return
-	}
-
-	// Membuat transaksi baru dan mendapatkan ID transaksi
+	// Transaksi dan total donasi event disimpan bersamaan
 	transaksi := dto.TransaksiCreateDTO{
 		NamaBank:            listBank.Nama,
 		Jumlah_Donasi_Event: resultPembayaran.Jumlah,
@@ -248,81 +841,251 @@ func (uc *userController) CreateTransaksiUser(ctx *gin.Context) {
 		UserID:              userID,
 	}
 
-	resultTransaksi, err := uc.transaksiService.CreateTransaksi(ctx.Request.Context(), transaksi)
+	resultTransaksi, event, err := uc.eventService.RecordDonation(ctx.Request.Context(), transaksi)
 	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrEventNotAcceptingDonation) || errors.Is(err, services.ErrEventDeadlinePassed) {
+			status = http.StatusConflict
+		}
 		res := utils.BuildResponseFailed("Gagal Menambahkan Transaksi", err.Error(), utils.EmptyObj{})
+		ctx.JSON(status, res)
+		return
+	}
+
+	message := "Berhasil Menambahkan Transaksi"
+	if event.Status == entities.EventStatusFunded {
+		message = "Donasi Mencapai Batas Maksimum"
+	}
+	res := utils.BuildResponseSuccess(message, services.ToTransaksiResponse(resultTransaksi))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) GetTransaksiUser(ctx *gin.Context) {
+	token := ctx.MustGet("token").(string)
+	userID, err := uc.jwtService.GetUserIDByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Memproses Request", "Token Tidak Valid", nil)
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	result, err := uc.transaksiService.GetAllTransaksiByUserID(ctx.Request.Context(), userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan User", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	// Mendapatkan data event berdasarkan ID event
-	event, err := uc.eventService.GetEventByID(ctx.Request.Context(), eventID)
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan User", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) PromoteUser(ctx *gin.Context) {
+	actorID := ctx.MustGet("userID").(uuid.UUID)
+	userID, err := uuid.Parse(ctx.Param("user_id"))
 	if err != nil {
-		res := utils.BuildResponseFailed("Gagal Mendapatkan Event", err.Error(), utils.EmptyObj{})
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	if event.Is_target_full {
-		res := utils.BuildResponseFailed("Jumlah Donasi Telah Penuh", err.Error(), utils.EmptyObj{})
+	result, err := uc.userService.PromoteUser(ctx.Request.Context(), actorID, userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Promote User", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	sisa_donasi := event.SisaDonasi + resultPembayaran.Jumlah
-	
-	// Menghitung jumlah donasi baru yang akan ditambahkan ke event
-	newJumlahDonasi := event.JumlahDonasi + resultTransaksi.Jumlah_Donasi_Event
-	if newJumlahDonasi >= event.MaxDonasi {
-		newJumlahDonasi = event.MaxDonasi
-		eventDTO := dto.EventUpdateDTO{
-			JumlahDonasi: &newJumlahDonasi,
-		}
+	res := utils.BuildResponseSuccess("Berhasil Promote User", result)
+	ctx.JSON(http.StatusOK, res)
+}
 
-		err = uc.eventService.PatchEvent(ctx.Request.Context(), eventDTO, eventID)
-		if err != nil {
-			res := utils.BuildResponseFailed("Gagal Mengupdate Jumlah Donasi Event", err.Error(), utils.EmptyObj{})
-			ctx.JSON(http.StatusBadRequest, res)
-			return
-		}
+func (uc *userController) DemoteUser(ctx *gin.Context) {
+	actorID := ctx.MustGet("userID").(uuid.UUID)
+	userID, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
 
-		res := utils.BuildResponseSuccess("Donasi Mencapai Batas Maksimum", event.MaxDonasi)
-		ctx.JSON(http.StatusOK, res)
+	result, err := uc.userService.DemoteUser(ctx.Request.Context(), actorID, userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Demote User", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	eventDTO := dto.EventUpdateDTO{
-		JumlahDonasi: &newJumlahDonasi,
-		SisaDonasi: &sisa_donasi,
+	res := utils.BuildResponseSuccess("Berhasil Demote User", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) UnlockUser(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Gagal Parse Id", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
 	}
 
-	err = uc.eventService.PatchEvent(ctx.Request.Context(), eventDTO, eventID)
+	if err := uc.loginGuardService.UnlockUser(ctx.Request.Context(), userID); err != nil {
+		res := utils.BuildResponseFailed("Gagal Membuka Kunci User", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Membuka Kunci User", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) GetLoginAttempts(ctx *gin.Context) {
+	result, err := uc.loginGuardService.GetLoginAttempts(ctx.Request.Context(), ctx.Query("email"))
 	if err != nil {
-		res := utils.BuildResponseFailed("Gagal Mengupdate Jumlah Donasi Event", err.Error(), utils.EmptyObj{})
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Riwayat Login", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	res1 := utils.BuildResponseSuccess("Berhasil Menambahkan Transaksi", resultTransaksi)
-	ctx.JSON(http.StatusOK, res1)
+	res := utils.BuildResponseSuccess("Berhasil Mendapatkan Riwayat Login", result)
+	ctx.JSON(http.StatusOK, res)
 }
 
-func (uc *userController) GetTransaksiUser(ctx *gin.Context) {
-	token := ctx.MustGet("token").(string)
-	userID, err := uc.jwtService.GetUserIDByToken(token)
+func (uc *userController) SetupTwoFactor(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	result, err := uc.twoFactorService.SetupTwoFactor(ctx.Request.Context(), userID)
 	if err != nil {
-		res := utils.BuildResponseFailed("Gagal Memproses Request", "Token Tidak Valid", nil)
-		ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+		res := utils.BuildResponseFailed("Gagal Menyiapkan Autentikasi Dua Langkah", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	result, err := uc.transaksiService.GetAllTransaksiByUserID(ctx.Request.Context(), userID)
+	res := utils.BuildResponseSuccess("Berhasil Menyiapkan Autentikasi Dua Langkah", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) EnableTwoFactor(ctx *gin.Context) {
+	var codeDTO dto.TwoFactorCodeDTO
+	if err := ctx.ShouldBind(&codeDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	result, err := uc.twoFactorService.EnableTwoFactor(ctx.Request.Context(), userID, codeDTO.Code)
 	if err != nil {
-		res := utils.BuildResponseFailed("Gagal Mendapatkan User", err.Error(), utils.EmptyObj{})
+		res := utils.BuildResponseFailed("Gagal Mengaktifkan Autentikasi Dua Langkah", err.Error(), utils.EmptyObj{})
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	res := utils.BuildResponseSuccess("Berhasil Mendapatkan User", result)
+	res := utils.BuildResponseSuccess("Berhasil Mengaktifkan Autentikasi Dua Langkah", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) DisableTwoFactor(ctx *gin.Context) {
+	var codeDTO dto.TwoFactorCodeDTO
+	if err := ctx.ShouldBind(&codeDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	if !uc.guardTwoFactorCode(ctx, "Gagal Menonaktifkan Autentikasi Dua Langkah", func() error {
+		return uc.twoFactorService.DisableTwoFactor(ctx.Request.Context(), userID, codeDTO.Code)
+	}) {
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Menonaktifkan Autentikasi Dua Langkah", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var codeDTO dto.TwoFactorCodeDTO
+	if err := ctx.ShouldBind(&codeDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	var result dto.TwoFactorRecoveryCodesResponse
+	if !uc.guardTwoFactorCode(ctx, "Gagal Membuat Recovery Code", func() (err error) {
+		result, err = uc.twoFactorService.RegenerateRecoveryCodes(ctx.Request.Context(), userID, codeDTO.Code)
+		return err
+	}) {
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Membuat Recovery Code", result)
 	ctx.JSON(http.StatusOK, res)
 }
+
+func (uc *userController) StepUp(ctx *gin.Context) {
+	var codeDTO dto.TwoFactorCodeDTO
+	if err := ctx.ShouldBind(&codeDTO); err != nil {
+		res := utils.BuildResponseFailed("Gagal Mendapatkan Request Dari Body", err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uuid.UUID)
+	var result dto.StepUpTokenResponse
+	if !uc.guardTwoFactorCode(ctx, "Gagal Verifikasi Dua Langkah", func() (err error) {
+		result, err = uc.twoFactorService.CreateStepUpToken(ctx.Request.Context(), userID, ctx.MustGet("sessionID").(uuid.UUID), codeDTO.Code)
+		return err
+	}) {
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Berhasil Verifikasi Dua Langkah", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// guardTwoFactorCode menjalankan verify dengan throttle yang sama seperti
+// /login/2fa, sehingga kode dua langkah tidak bisa ditebak berulang kali
+// walaupun penyerang memegang access token yang valid.
+func (uc *userController) guardTwoFactorCode(ctx *gin.Context, message string, verify func() error) bool {
+	user, err := uc.userService.GetUserByID(ctx.Request.Context(), ctx.MustGet("userID").(uuid.UUID))
+	if err != nil {
+		res := utils.BuildResponseFailed(message, err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return false
+	}
+
+	attempt := entities.LoginAttempt{
+		Email:     user.Email,
+		UserID:    &user.ID,
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+
+	if err := uc.loginGuardService.ReserveLoginAttempt(ctx.Request.Context(), attempt.Email, attempt.IPAddress); err != nil {
+		status := http.StatusBadRequest
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			status = http.StatusTooManyRequests
+		}
+		res := utils.BuildResponseFailed(message, err.Error(), utils.EmptyObj{})
+		ctx.JSON(status, res)
+		return false
+	}
+
+	if err := verify(); err != nil {
+		attempt.Reason = "invalid_two_factor_code"
+		if err := uc.loginGuardService.RecordLoginFailure(ctx.Request.Context(), attempt); err != nil {
+			log.Printf("error recording login failure: %v", err)
+		}
+		res := utils.BuildResponseFailed(message, err.Error(), utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return false
+	}
+
+	if err := uc.loginGuardService.RecordLoginSuccess(ctx.Request.Context(), attempt); err != nil {
+		log.Printf("error recording login success: %v", err)
+	}
+	return true
+}
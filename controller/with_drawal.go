@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -20,20 +21,24 @@ type PenarikanController interface {
 }
 
 type penarikanController struct {
-	jwtService services.JWTService
-	userService services.UserService
+	jwtService       services.JWTService
+	userService      services.UserService
 	penarikanService services.PenarikanService
-	eventService services.EventService
-	db *gorm.DB
+	eventService     services.EventService
+	policyService    services.PolicyService
+	kycService       services.KYCService
+	db               *gorm.DB
 }
 
-func NewPenarikanController(us services.UserService, es services.EventService, ps services.PenarikanService, db *gorm.DB, jwt services.JWTService) PenarikanController {
+func NewPenarikanController(us services.UserService, es services.EventService, ps services.PenarikanService, pos services.PolicyService, ks services.KYCService, db *gorm.DB, jwt services.JWTService) PenarikanController {
 	return &penarikanController{
-		jwtService: jwt,
-		userService: us,
+		jwtService:       jwt,
+		userService:      us,
 		penarikanService: ps,
-		eventService: es,
-		db: db,
+		eventService:     es,
+		policyService:    pos,
+		kycService:       ks,
+		db:               db,
 	}
 }
 
@@ -53,6 +58,21 @@ func (pc *penarikanController) CreatePenarikan(ctx *gin.Context) {
 		return
 	}
 
+	if err := pc.policyService.AuthorizeEvent(ctx.Request.Context(), actorFromContext(ctx), penarikanDTO.EventID); err != nil {
+		abortWithPolicyError(ctx, err)
+		return
+	}
+
+	if _, err := pc.kycService.GetApprovedIdentity(ctx.Request.Context(), userID); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrKYCNotApproved) {
+			status = http.StatusForbidden
+		}
+		res := utils.BuildResponseFailed("Gagal Menambahkan Penarikan", err.Error(), utils.EmptyObj{})
+		ctx.JSON(status, res)
+		return
+	}
+
 	event, err := pc.eventService.GetEventByID(ctx.Request.Context(), penarikanDTO.EventID)
 	if err != nil {
 		res := utils.BuildResponseFailed("Gagal Mendapatkan Event", err.Error(), utils.EmptyObj{})
@@ -60,8 +80,13 @@ func (pc *penarikanController) CreatePenarikan(ctx *gin.Context) {
 		return
 	}
 
-	balance := event.JumlahDonasi
-	if balance < penarikanDTO.Jumlah_Penarikan {
+	if !entities.IsEventWithdrawableStatus(event.Status) {
+		res := utils.BuildResponseFailed("Gagal Menambahkan Penarikan", "Dana Event Ini Tidak Dapat Ditarik", utils.EmptyObj{})
+		ctx.JSON(http.StatusConflict, res)
+		return
+	}
+
+	if event.SisaDonasi < penarikanDTO.Jumlah_Penarikan {
 		res := utils.BuildResponseFailed("Saldo Tidak Mencukupi", "Saldo anda tidak mencukupi untuk melakukan penarikan", nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
@@ -90,24 +115,6 @@ func (pc *penarikanController) CreatePenarikan(ctx *gin.Context) {
 		return
 	}
 
-	// newBalance := balance - penarikanDTO.Jumlah_Penarikan
-	// if newBalance <= 0 {
-	// 	event.SisaDonasi = 0
-	// } else{
-	// 	event.SisaDonasi = newBalance
-	// }
-
-	// eventDTO := dto.EventUpdateDTO {
-	// 	JumlahDonasi: &event.JumlahDonasi,
-	// }
-
-	// err = pc.eventService.UpdateEvent(ctx, eventDTO, penarikan.EventID)
-	// if err != nil {
-	// 	res := utils.BuildResponseFailed("Gagal Mengupdate Saldo Event", err.Error(), utils.EmptyObj{})
-	// 	ctx.JSON(http.StatusBadRequest, res)
-	// 	return
-	// }
-
 	res := utils.BuildResponseSuccess("Penarikan Berhasil", result)
 	ctx.JSON(http.StatusOK, res)
 }
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AccountDeletionRequestDTO struct {
+	Password    string `json:"password" binding:"required_without=ReauthToken" form:"password"`
+	ReauthToken string `json:"reauth_token" form:"reauth_token"`
+	// HandoverEmail diisi jika campaign yang masih aktif ingin diserahkan ke
+	// campaigner lain yang sudah lolos KYC. Penerima harus menyetujui tawaran
+	// sebelum campaign dipindahkan.
+	HandoverEmail string `json:"handover_email" binding:"omitempty,email" form:"handover_email"`
+}
+
+type AccountDeletionCampaign struct {
+	ID            uuid.UUID `json:"id"`
+	JudulEvent    string    `json:"judul_event"`
+	SisaDonasi    float64   `json:"sisa_donasi"`
+	ExpiredDonasi time.Time `json:"expired_donasi"`
+}
+
+// HandoverEvents adalah campaign yang akan diserahkan ke HandoverEmail jika
+// penerima menyetujui tawaran sebelum masa tenggang berakhir.
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time                 `json:"deletion_scheduled_at"`
+	HandoverEmail       string                    `json:"handover_email,omitempty"`
+	HandoverEvents      []AccountDeletionCampaign `json:"handover_events,omitempty"`
+}
+
+type CampaignHandoverResponse struct {
+	ID                  uuid.UUID                 `json:"id"`
+	FromNama            string                    `json:"from_nama"`
+	DeletionScheduledAt *time.Time                `json:"deletion_scheduled_at"`
+	AcceptedAt          *time.Time                `json:"accepted_at"`
+	Events              []AccountDeletionCampaign `json:"events"`
+	CreatedAt           time.Time                 `json:"created_at"`
+}
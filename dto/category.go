@@ -0,0 +1,23 @@
+package dto
+
+type CategoryCreateDTO struct {
+	Nama      string `json:"nama" form:"nama" binding:"required,max=50"`
+	Slug      string `json:"slug" form:"slug" binding:"omitempty,max=60"`
+	Icon      string `json:"icon" form:"icon" binding:"omitempty,max=100"`
+	Deskripsi string `json:"deskripsi" form:"deskripsi"`
+	Urutan    int    `json:"urutan" form:"urutan"`
+	IsActive  *bool  `json:"is_active" form:"is_active"`
+	ParentID  *uint  `json:"parent_id" form:"parent_id"`
+}
+
+// CategoryUpdateDTO hanya mengubah field yang dikirim. ParentID bernilai 0
+// berarti kategori dijadikan kategori utama.
+type CategoryUpdateDTO struct {
+	Nama      *string `json:"nama" form:"nama" binding:"omitempty,max=50"`
+	Slug      *string `json:"slug" form:"slug" binding:"omitempty,max=60"`
+	Icon      *string `json:"icon" form:"icon" binding:"omitempty,max=100"`
+	Deskripsi *string `json:"deskripsi" form:"deskripsi"`
+	Urutan    *int    `json:"urutan" form:"urutan"`
+	IsActive  *bool   `json:"is_active" form:"is_active"`
+	ParentID  *uint   `json:"parent_id" form:"parent_id"`
+}
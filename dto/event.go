@@ -7,43 +7,94 @@ import (
 )
 
 type EventCreateDTO struct {
-	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	RekeningEvent  string    `json:"rekening_event" form:"rekening_event" binding:"required"`
-	JudulEvent     string    `json:"judul_event" form:"judul_event" binding:"required"`
-	DeskripsiEvent string    `json:"deskripsi_event" form:"deskripsi_event" binding:"required"`
-	JenisEvent     string    `json:"jenis_event" form:"jenis_event" binding:"required"`
-	MaxDonasi      float64   `json:"max_donasi" form:"max_donasi" binding:"required"`
-	FotoEvent      string    `json:"foto_event" form:"foto_event" binding:"required"`
-	ExpiredDonasi  time.Time `json:"expired_donasi" form:"expired_donasi" binding:"required"`
-	SisaHariDonasi *string       `json:"time_left" form:"time_left"`
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	RekeningEvent  string     `json:"rekening_event" form:"rekening_event" binding:"required"`
+	JudulEvent     string     `json:"judul_event" form:"judul_event" binding:"required"`
+	DeskripsiEvent string     `json:"deskripsi_event" form:"deskripsi_event" binding:"required"`
+	CategoryID     *uint      `json:"category_id" form:"category_id" binding:"required"`
+	MaxDonasi      float64    `json:"max_donasi" form:"max_donasi" binding:"required,gt=0"`
+	FotoAssetID    *uuid.UUID `json:"foto_asset_id" form:"foto_asset_id" binding:"required"`
+	ExpiredDonasi  time.Time  `json:"expired_donasi" form:"expired_donasi" binding:"required"`
+
+	// URL foto diisi dari asset yang diunggah lewat /api/asset
+	FotoEvent     string `json:"-" form:"-"`
+	FotoThumbnail string `json:"-" form:"-"`
 
 	NamaDepanPembuat    string `json:"nama_depan_pembuat" form:"nama_depan_pembuat" binding:"required"`
 	NamaBelakangPembuat string `json:"nama_belakang_pembuat" form:"nama_belakang_pembuat" binding:"required"`
 	NomorTeleponPembuat string `json:"nomor_telepon_pembuat" form:"nomor_telepon_pembuat" binding:"required"`
-	NomorKTP            string `json:"nomor_ktp" form:"nomor_ktp" binding:"required"`
-	Pekerjaan           string `json:"pekerjaan" form:"pekerjaan" binding:"required"`
-	AsalInstansi        string `json:"asal_instansi" form:"asal_instansi" binding:"required"`
+	NomorKTP            string `json:"-" form:"-"`
+	Pekerjaan           string `json:"-" form:"-"`
+	AsalInstansi        string `json:"-" form:"-"`
 
 	NamaDepanPenerima    string `json:"nama_depan_penerima" form:"nama_depan_penerima" binding:"required"`
 	NamaBelakangPenerima string `json:"nama_belakang_penerima" form:"nama_belakang_penerima" binding:"required"`
 	TujuanGalangDana     string `json:"tujuan_galang_dana" form:"tujuan_galang_dana" binding:"required"`
 	LokasiTujuan         string `json:"lokasi_tujuan" form:"lokasi_tujuan" binding:"required"`
 
-	UserID uuid.UUID `json:"user_id" form:"user_id" binding:"required"`
+	UserID uuid.UUID `json:"-" form:"-"`
 }
 
 type EventUpdateDTO struct {
-	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	RekeningEvent  *string   `json:"rekening_event" form:"rekening_event"`
-	Judul          *string   `json:"judul" form:"judul"`
-	DeskripsiEvent *string   `json:"deskripsi_event" form:"deskripsi_event"`
-	JenisEvent     *string   `json:"jenis_event" form:"jenis_event"`
-	JumlahDonasi   *float64  `json:"jumlah_donasi" form:"jumlah_donasi"`
-	SisaDonasi *float64 `json:"sisa_donasi" form:"sisa_donasi"`
-	FotoEvent      *string   `json:"foto_event" form:"foto_event"`
-	UserID         *string   `json:"user_id" form:"user_id"`
-	IsTargetFull   *bool     `json:"is_target_full" form:"is_target_full"`
-	IsExpired      *bool     `json:"is_expired" form:"is_expired"`
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	RekeningEvent  *string    `json:"rekening_event" form:"rekening_event"`
+	JudulEvent     *string    `json:"judul_event" form:"judul_event"`
+	DeskripsiEvent *string    `json:"deskripsi_event" form:"deskripsi_event"`
+	CategoryID     *uint      `json:"category_id" form:"category_id"`
+	FotoAssetID    *uuid.UUID `json:"foto_asset_id" form:"foto_asset_id"`
+	FotoEvent      *string    `json:"-" form:"-"`
+	FotoThumbnail  *string    `json:"-" form:"-"`
+	UserID         *string    `json:"user_id" form:"user_id"`
+}
+
+// EventStatusUpdateDTO dipakai untuk mengubah status event secara manual.
+// Status funded dan expired hanya diubah oleh sistem.
+type EventStatusUpdateDTO struct {
+	Status string `json:"status" form:"status" binding:"required,oneof=draft pending_review active closed cancelled"`
+	Reason string `json:"reason" form:"reason" binding:"max=500"`
+}
+
+// EventPublicResponse adalah tampilan event untuk pengunjung umum, tanpa data
+// pribadi pembuat dan rekening pencairan.
+type EventPublicResponse struct {
+	ID                   uuid.UUID  `json:"id"`
+	JudulEvent           string     `json:"judul_event"`
+	DeskripsiEvent       string     `json:"deskripsi_event"`
+	CategoryID           *uint      `json:"category_id"`
+	JenisEvent           string     `json:"jenis_event"`
+	CategorySlug         string     `json:"category_slug"`
+	FotoEvent            string     `json:"foto_event"`
+	FotoThumbnail        string     `json:"foto_thumbnail"`
+	FotoAssetID          *uuid.UUID `json:"foto_asset_id"`
+	MaxDonasi            float64    `json:"max_donasi"`
+	JumlahDonasi         float64    `json:"jumlah_donasi"`
+	SisaDonasi           float64    `json:"sisa_donasi"`
+	LikeCount            uint64     `json:"like_count"`
+	ExpiredDonasi        time.Time  `json:"expired_donasi"`
+	SisaHariDonasi       string     `json:"time_left"`
+	Status               string     `json:"status"`
+	NamaDepanPembuat     string     `json:"nama_depan_pembuat"`
+	NamaBelakangPembuat  string     `json:"nama_belakang_pembuat"`
+	Pekerjaan            string     `json:"pekerjaan"`
+	AsalInstansi         string     `json:"asal_pekerjaan"`
+	NamaDepanPenerima    string     `json:"nama_depan_penerima"`
+	NamaBelakangPenerima string     `json:"nama_belakang_penerima"`
+	TujuanGalangDana     string     `json:"tujuan_galang_dana"`
+	LokasiTujuan         string     `json:"lokasi_tujuan"`
+	UserID               uuid.UUID  `json:"user_id"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+
+	// KabarTerbaru hanya diisi pada detail event, urut dari yang terbaru
+	KabarTerbaru []KabarTerbaruResponse `json:"kabar_terbaru,omitempty"`
+}
+
+// EventOwnerResponse hanya untuk pemilik event dan admin.
+type EventOwnerResponse struct {
+	EventPublicResponse
+	RekeningEvent       string `json:"rekening_event"`
+	NomorKTP            string `json:"nomor_ktp"`
+	NomorTeleponPembuat string `json:"nomor_telepon_pembuat"`
 }
 
 type EventResponseServiceDTO struct {
@@ -52,7 +103,7 @@ type EventResponseServiceDTO struct {
 	DeskripsiEvent string    `json:"deskripsi_event" form:"deskripsi_event"`
 	FotoEvent      string    `json:"foto_event" form:"foto_event"`
 	ExpiredDonasi  time.Time `json:"expired_donasi" form:"expired_donasi" binding:"required"`
-	IsExpired      bool      `json:"is_expired" form:"is_expired"`
+	Status         string    `json:"status" form:"status"`
 }
 
 type EventResponseDetailDonasiDTO struct {
@@ -62,8 +113,7 @@ type EventResponseDetailDonasiDTO struct {
 	JumlahDonasi   float64   `json:"jumlah_donasi" form:"jumlah_donasi"`
 	MaxDonasi      float64   `json:"max_donasi" form:"max_donasi"`
 	ExpiredDonasi  time.Time `json:"expired_donasi" form:"expired_donasi" binding:"required"`
-	IsExpired      bool      `json:"is_expired" form:"is_expired"`
-	IsDone         uint64    `json:"is_done"`
+	Status         string    `json:"status" form:"status"`
 
 	// Mengeluarkan 3 orang yang terakhir donasi
 }
@@ -82,6 +132,39 @@ type EventResponseMyEventDTO struct {
 	FotoEvent      string `json:"foto_event" form:"foto_event"`
 }
 
-type EventPaginationResponse struct {
-	PageNumber uint `json:"page_number" form:"page_number"`
-}
\ No newline at end of file
+// EventFilterQuery berisi filter yang sama untuk daftar dan pencarian event.
+type EventFilterQuery struct {
+	// Category berisi slug kategori, sub kategori ikut disertakan
+	Category  string `form:"category"`
+	Status    string `form:"status" binding:"omitempty,oneof=draft pending_review active funded expired closed cancelled"`
+	CreatorID string `form:"creator_id" binding:"omitempty,uuid"`
+	// Expired dan TargetFull dipertahankan dari versi sebelumnya dan
+	// dipetakan ke status expired dan funded
+	Expired    *bool `form:"expired"`
+	TargetFull *bool `form:"target_full"`
+}
+
+// EventListQuery dibaca dari query string GET /api/event. Cursor dipakai
+// sebagai pengganti page untuk mengambil halaman berikutnya.
+type EventListQuery struct {
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	Cursor  string `form:"cursor"`
+	Sort    string `form:"sort" binding:"omitempty,oneof=newest most_liked ending_soon most_funded"`
+	EventFilterQuery
+}
+
+// EventSearchQuery selalu diurutkan berdasarkan relevansi.
+type EventSearchQuery struct {
+	Q       string `form:"q" binding:"required,max=200"`
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	EventFilterQuery
+}
+
+type EventSearchResponse struct {
+	Event             any     `json:"event"`
+	Rank              float64 `json:"rank"`
+	HeadlineJudul     string  `json:"headline_judul"`
+	HeadlineDeskripsi string  `json:"headline_deskripsi"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type KabarTerbaruCreateDTO struct {
+	Judul              string   `json:"judul" form:"judul" binding:"required,max=150"`
+	Isi                string   `json:"isi" form:"isi" binding:"required,max=10000"`
+	Gambar             []string `json:"gambar" form:"gambar" binding:"omitempty,max=10,dive,url,max=255"`
+	HistoryPenarikanID *uint    `json:"history_penarikan_id" form:"history_penarikan_id"`
+}
+
+// KabarTerbaruUpdateDTO hanya mengubah field yang dikirim. Gambar yang dikirim
+// menggantikan seluruh gambar lama, dan HistoryPenarikanID bernilai 0 berarti
+// tautan ke penarikan dilepas.
+type KabarTerbaruUpdateDTO struct {
+	Judul              *string   `json:"judul" form:"judul" binding:"omitempty,max=150"`
+	Isi                *string   `json:"isi" form:"isi" binding:"omitempty,max=10000"`
+	Gambar             *[]string `json:"gambar" form:"gambar" binding:"omitempty,max=10,dive,url,max=255"`
+	HistoryPenarikanID *uint     `json:"history_penarikan_id" form:"history_penarikan_id"`
+}
+
+type KabarTerbaruPenarikanResponse struct {
+	ID               uint      `json:"id"`
+	JumlahPenarikan  float64   `json:"jumlah_penarikan"`
+	NamaBank         string    `json:"nama_bank"`
+	TanggalPenarikan time.Time `json:"tanggal_penarikan"`
+}
+
+type KabarTerbaruResponse struct {
+	ID        uuid.UUID                      `json:"id"`
+	Judul     string                         `json:"judul"`
+	Isi       string                         `json:"isi"`
+	Gambar    []string                       `json:"gambar"`
+	Penarikan *KabarTerbaruPenarikanResponse `json:"penarikan,omitempty"`
+	EventID   uuid.UUID                      `json:"event_id"`
+	UserID    uuid.UUID                      `json:"user_id"`
+	CreatedAt time.Time                      `json:"created_at"`
+	UpdatedAt time.Time                      `json:"updated_at"`
+}
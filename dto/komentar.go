@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KomentarCreateDTO dipakai untuk komentar baru maupun balasan. TransaksiID
+// diisi jika komentar adalah doa yang menyertai donasi penulis.
+type KomentarCreateDTO struct {
+	Isi         string     `json:"isi" form:"isi" binding:"required,max=2000"`
+	ParentID    *uuid.UUID `json:"parent_id" form:"parent_id"`
+	TransaksiID *uuid.UUID `json:"transaksi_id" form:"transaksi_id"`
+}
+
+type KomentarUpdateDTO struct {
+	Isi string `json:"isi" form:"isi" binding:"required,max=2000"`
+}
+
+type KomentarReportDTO struct {
+	Alasan string `json:"alasan" form:"alasan" binding:"required,max=255"`
+}
+
+type KomentarHideDTO struct {
+	Alasan string `json:"alasan" form:"alasan" binding:"max=255"`
+}
+
+type KomentarListQuery struct {
+	Page    int `form:"page" binding:"omitempty,min=1"`
+	PerPage int `form:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+// KomentarResponse menampilkan komentar beserta balasannya. Data moderasi
+// hanya diisi untuk pemilik event dan admin.
+type KomentarResponse struct {
+	ID        uuid.UUID          `json:"id"`
+	Isi       string             `json:"isi"`
+	IsDoa     bool               `json:"is_doa"`
+	ParentID  *uuid.UUID         `json:"parent_id"`
+	EventID   uuid.UUID          `json:"event_id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	NamaUser  string             `json:"nama_user"`
+	EditedAt  *time.Time         `json:"edited_at"`
+	Deleted   bool               `json:"deleted"`
+	CreatedAt time.Time          `json:"created_at"`
+	Balasan   []KomentarResponse `json:"balasan,omitempty"`
+
+	Hidden       bool   `json:"hidden,omitempty"`
+	HiddenReason string `json:"hidden_reason,omitempty"`
+	ReportCount  int    `json:"report_count,omitempty"`
+}
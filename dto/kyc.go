@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KYCSubmitDTO dikirim sebagai multipart form bersama file "ktp" dan "selfie".
+type KYCSubmitDTO struct {
+	NIK          string `form:"nik" json:"nik" binding:"required,len=16,numeric"`
+	NamaLengkap  string `form:"nama_lengkap" json:"nama_lengkap" binding:"required"`
+	TanggalLahir string `form:"tanggal_lahir" json:"tanggal_lahir" binding:"required"`
+	JenisKelamin string `form:"jenis_kelamin" json:"jenis_kelamin" binding:"required,oneof=L P"`
+	Pekerjaan    string `form:"pekerjaan" json:"pekerjaan" binding:"required"`
+	AsalInstansi string `form:"asal_instansi" json:"asal_instansi" binding:"required"`
+}
+
+type KYCRejectDTO struct {
+	Reason string `form:"reason" json:"reason" binding:"required"`
+}
+
+type KYCResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	NIK             string     `json:"nik"`
+	NamaLengkap     string     `json:"nama_lengkap"`
+	TanggalLahir    time.Time  `json:"tanggal_lahir"`
+	JenisKelamin    string     `json:"jenis_kelamin"`
+	Pekerjaan       string     `json:"pekerjaan"`
+	AsalInstansi    string     `json:"asal_instansi"`
+	Status          string     `json:"status"`
+	RejectionReason string     `json:"rejection_reason,omitempty"`
+	SubmittedAt     time.Time  `json:"submitted_at"`
+	ReviewedAt      *time.Time `json:"reviewed_at"`
+}
+
+// KYCAdminResponse menampilkan NIK lengkap dan link dokumen untuk direview.
+type KYCAdminResponse struct {
+	KYCResponse
+	UserID     uuid.UUID           `json:"user_id"`
+	User       *UserPublicResponse `json:"user,omitempty"`
+	ReviewedBy *uuid.UUID          `json:"reviewed_by"`
+	KTPURL     string              `json:"ktp_url"`
+	SelfieURL  string              `json:"selfie_url"`
+	Province   string              `json:"province"`
+}
+
+type KYCIdentity struct {
+	NIK          string
+	Pekerjaan    string
+	AsalInstansi string
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type OIDCReauthResponse struct {
+	ReauthToken string    `json:"reauth_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
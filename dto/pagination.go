@@ -0,0 +1,9 @@
+package dto
+
+type PaginationMeta struct {
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page"`
+	Total      int64  `json:"total"`
+	TotalPages int64  `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -1,7 +1,7 @@
 package dto
 
 type PembayaranDTO struct {
-	Jumlah             float64 `gorm:"type:float" json:"jumlah" binding:"required"`
+	Jumlah float64 `gorm:"type:float" json:"jumlah" binding:"required,gt=0"`
 	// StatusPembayaranID uint    `json:"status_pembayaran_id" binding:"required"`
-	ListBankID         uint    `json:"list_bank_id" binding:"required"`
+	ListBankID uint `json:"list_bank_id" binding:"required"`
 }
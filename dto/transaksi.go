@@ -17,3 +17,14 @@ type TransaksiCreateDTO struct {
 	PembayaranID uuid.UUID `gorm:"type:uuid" json:"pembayaran_id" binding:"required"`
 	UserID       uuid.UUID `json:"user_id" form:"user_id" binding:"required"`
 }
+
+type TransaksiResponse struct {
+	ID                  uuid.UUID           `json:"id"`
+	NamaBank            string              `json:"nama_bank"`
+	Jumlah_Donasi_Event float64             `json:"jumlah_donasi"`
+	Tanggal_Transaksi   time.Time           `json:"tangal_transaksi"`
+	UserID              uuid.UUID           `json:"user_id"`
+	User                *UserPublicResponse `json:"user,omitempty"`
+	EventID             uuid.UUID           `json:"event_id"`
+	PembayaranID        uuid.UUID           `json:"pembayaran_id"`
+}
@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -13,15 +15,71 @@ type UserCreateDTO struct {
 	ConfirmPassword string    `gorm:"type:varchar(100)" form:"confirm_password" json:"confirm_password" binding:"required"`
 }
 
+// UserUpdateDTO hanya untuk data profil, email dan password punya endpoint sendiri
 type UserUpdateDTO struct {
-	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Nama     *string    `gorm:"type:varchar(100)" form:"nama" json:"nama,omitempty"`
-	NoTelp   *string    `gorm:"type:varchar(20)" form:"no_telp" json:"no_telp,omitempty"`
-	Email    *string    `gorm:"type:varchar(100)" form:"email" json:"email,omitempty"`
-	Password *string    `gorm:"type:varchar(100)" form:"password" json:"password,omitempty"`
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Nama   *string   `gorm:"type:varchar(100)" form:"nama" json:"nama,omitempty"`
+	NoTelp *string   `gorm:"type:varchar(20)" form:"no_telp" json:"no_telp,omitempty"`
 }
 
 type UserLoginDTO struct {
 	Email    string `json:"email" binding:"email" form:"email"`
 	Password string `json:"password" binding:"required" form:"password"`
 }
+
+type UserEmailDTO struct {
+	Email string `json:"email" binding:"required,email" form:"email"`
+}
+
+type UserResetPasswordDTO struct {
+	Token           string `json:"token" binding:"required" form:"token"`
+	Password        string `json:"password" binding:"required,min=8" form:"password"`
+	ConfirmPassword string `json:"confirm_password" binding:"required" form:"confirm_password"`
+}
+
+// ReauthToken menggantikan password saat ini bagi user yang login lewat
+// provider OIDC dan tidak pernah memiliki password.
+type UserChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password" binding:"required_without=ReauthToken" form:"current_password"`
+	ReauthToken     string `json:"reauth_token" form:"reauth_token"`
+	Password        string `json:"password" binding:"required,min=8" form:"password"`
+	ConfirmPassword string `json:"confirm_password" binding:"required" form:"confirm_password"`
+}
+
+type UserChangeEmailDTO struct {
+	Email       string `json:"email" binding:"required,email" form:"email"`
+	Password    string `json:"password" binding:"required_without=ReauthToken" form:"password"`
+	ReauthToken string `json:"reauth_token" form:"reauth_token"`
+}
+
+// UserPublicResponse dipakai ketika data user ditampilkan ke user lain,
+// misalnya daftar donatur pada transaksi.
+type UserPublicResponse struct {
+	ID   uuid.UUID `json:"id"`
+	Nama string    `json:"nama"`
+}
+
+// UserSelfResponse dipakai untuk data milik user yang sedang login.
+type UserSelfResponse struct {
+	ID                  uuid.UUID  `json:"id"`
+	Nama                string     `json:"nama"`
+	NoTelp              string     `json:"no_telp"`
+	Email               string     `json:"email"`
+	Role                string     `json:"role"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+type UserAdminResponse struct {
+	ID                  uuid.UUID  `json:"id"`
+	Nama                string     `json:"nama"`
+	NoTelp              string     `json:"no_telp"`
+	Email               string     `json:"email"`
+	Role                string     `json:"role"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	AnonymizedAt        *time.Time `json:"anonymized_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ScopeEventsRead    = "events:read"
+	ScopeTransaksiRead = "transaksi:read"
+)
+
+var APIKeyScopes = []string{ScopeEventsRead, ScopeTransaksiRead}
+
+func IsValidScope(scope string) bool {
+	for _, s := range APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey dipakai sistem partner untuk mengakses API tanpa akun user. Key
+// hanya ditampilkan sekali saat dibuat, yang disimpan hanya prefix dan hash-nya.
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name       string     `gorm:"type:varchar(100)" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16);uniqueIndex" json:"prefix"`
+	KeyHash    string     `gorm:"type:varchar(64)" json:"-"`
+	Scopes     string     `gorm:"type:text" json:"-"`
+	ExpiresAt  *time.Time `gorm:"type:timestamp with time zone" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"type:timestamp with time zone" json:"revoked_at"`
+	LastUsedAt *time.Time `gorm:"type:timestamp with time zone" json:"last_used_at"`
+	LastUsedIP string     `gorm:"type:varchar(64)" json:"last_used_ip"`
+
+	CreatedBy uuid.UUID `gorm:"type:uuid" json:"created_by"`
+	Creator   User      `gorm:"foreignKey:CreatedBy" json:"-"`
+
+	Timestamp
+}
+
+func (k APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package entities
+
+import "github.com/google/uuid"
+
+// Asset adalah gambar yang sudah diunggah ke storage. Key dan ThumbnailKey
+// adalah lokasi file di storage, sedangkan URL dan ThumbnailURL adalah alamat
+// publiknya.
+type Asset struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Key          string    `gorm:"type:varchar(255);uniqueIndex" json:"key"`
+	ThumbnailKey string    `gorm:"type:varchar(255)" json:"thumbnail_key"`
+	URL          string    `gorm:"type:varchar(255)" json:"url"`
+	ThumbnailURL string    `gorm:"type:varchar(255)" json:"thumbnail_url"`
+	ContentType  string    `gorm:"type:varchar(50)" json:"content_type"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+
+	UserID uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID" json:"-"`
+
+	Timestamp
+}
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CampaignHandover adalah tawaran penyerahan campaign dari user yang
+// menjadwalkan penghapusan akun. Campaign baru dipindahkan ketika penghapusan
+// akun dijalankan, dan hanya jika penerima sudah menerima tawaran tersebut.
+type CampaignHandover struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	AcceptedAt *time.Time `gorm:"type:timestamp with time zone" json:"accepted_at"`
+
+	FromUserID uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"from_user_id"`
+	FromUser   User      `gorm:"foreignKey:FromUserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	ToUserID   uuid.UUID `gorm:"type:uuid;index" json:"to_user_id"`
+	ToUser     User      `gorm:"foreignKey:ToUserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
@@ -1,6 +1,22 @@
 package entities
 
 type CategoryEvent struct {
-	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
-	Nama       string     `gorm:"type:varchar(50)" json:"nama"`
-}
\ No newline at end of file
+	ID        uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nama      string `gorm:"type:varchar(50)" json:"nama"`
+	Slug      string `gorm:"type:varchar(60);uniqueIndex" json:"slug"`
+	Icon      string `gorm:"type:varchar(100)" json:"icon"`
+	Deskripsi string `gorm:"type:text" json:"deskripsi"`
+	Urutan    int    `gorm:"not null;default:0" json:"urutan"`
+	// IsActive berupa pointer agar nilai false tetap ikut tersimpan saat create
+	IsActive *bool `gorm:"not null;default:true" json:"is_active"`
+
+	ParentID *uint           `gorm:"index" json:"parent_id"`
+	Parent   *CategoryEvent  `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"-"`
+	Children []CategoryEvent `gorm:"foreignKey:ParentID" json:"children,omitempty"`
+
+	Timestamp
+}
+
+func (c CategoryEvent) Active() bool {
+	return c.IsActive == nil || *c.IsActive
+}
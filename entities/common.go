@@ -11,7 +11,9 @@ type Timestamp struct {
 }
 
 type Authorization struct {
-	Token string `gorm:"type:varchar(255)" json:"token"`
-	Role  string `gorm:"type:varchar(30)" json:"role"`
-	ExpiresAt time.Time `gorm:"column:expires_at" json:"expiresAt"`
-}
\ No newline at end of file
+	Token            string    `gorm:"type:varchar(255)" json:"token"`
+	Role             string    `gorm:"type:varchar(30)" json:"role"`
+	ExpiresAt        time.Time `gorm:"column:expires_at" json:"expiresAt"`
+	RefreshToken     string    `gorm:"type:varchar(255)" json:"refreshToken"`
+	RefreshExpiresAt time.Time `gorm:"column:refresh_expires_at" json:"refreshExpiresAt"`
+}
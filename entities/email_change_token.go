@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailChangeToken menyimpan permintaan perubahan email yang menunggu
+// konfirmasi dari alamat baru.
+type EmailChangeToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	NewEmail  string     `gorm:"type:varchar(100)" json:"new_email"`
+	ExpiresAt time.Time  `gorm:"type:timestamp with time zone" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"type:timestamp with time zone" json:"used_at"`
+
+	UserID uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
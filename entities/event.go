@@ -13,17 +13,14 @@ type Event struct {
 	RekeningEvent  string    `gorm:"type:varchar(100)" json:"rekening_event"`
 	JudulEvent     string    `gorm:"type:varchar(100)" json:"judul_event"`
 	DeskripsiEvent string    `gorm:"type:text" json:"deskripsi_event"`
-	JenisEvent     string    `gorm:"type:varchar(100)" json:"jenis_event"`
-	FotoEvent      string    `gorm:"type:varchar(100)" json:"foto_event"`
+	FotoEvent      string    `gorm:"type:varchar(255)" json:"foto_event"`
+	FotoThumbnail  string    `gorm:"type:varchar(255)" json:"foto_thumbnail"`
 	MaxDonasi      float64   `gorm:"type:float" json:"max_donasi"`
 	JumlahDonasi   float64   `gorm:"type:float" json:"jumlah_donasi"`
 	SisaDonasi     float64   `gorm:"type:float" json:"sisa_donasi"`
 	LikeCount      uint64    `json:"like_count"`
 	ExpiredDonasi  time.Time `gorm:"timestamp with time zone" json:"expired_donasi"`
-	SisaHariDonasi string    `json:"time_left"`
-	IsDone         uint64    `json:"is_done"`
-	Is_target_full bool      `gorm:"type:boolean" json:"is_target_full"`
-	Is_expired     bool      `gorm:"type:boolean" json:"is_expired"`
+	Status         string    `gorm:"type:varchar(20);not null;default:'draft';index" json:"status"`
 
 	// Pembuat Event
 	NamaDepanPembuat    string `gorm:"type:varchar(100)" json:"nama_depan_pembuat"`
@@ -42,9 +39,18 @@ type Event struct {
 	UserID uuid.UUID `gorm:"type:uuid" json:"user_id"`
 	User   User      `gorm:"foreignKey:UserID" json:"-"`
 
-	Likes            []Like             `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
-	HistoryPenarikan []HistoryPenarikan `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"history_penarikans,omitempty"`
-	Transaksi        []Transaksi        `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"transaksis,omitempty"`
+	FotoAssetID *uuid.UUID `gorm:"type:uuid;index" json:"foto_asset_id"`
+	FotoAsset   *Asset     `gorm:"foreignKey:FotoAssetID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+
+	CategoryID *uint          `gorm:"index" json:"category_id"`
+	Category   *CategoryEvent `gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"-"`
+
+	Likes            []Like               `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	HistoryPenarikan []HistoryPenarikan   `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"history_penarikans,omitempty"`
+	Transaksi        []Transaksi          `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"transaksis,omitempty"`
+	StatusHistories  []EventStatusHistory `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	KabarTerbaru     []KabarTerbaru       `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Komentar         []Komentar           `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
 
 	Timestamp
 }
@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventStatusDraft         = "draft"
+	EventStatusPendingReview = "pending_review"
+	EventStatusActive        = "active"
+	EventStatusFunded        = "funded"
+	EventStatusExpired       = "expired"
+	EventStatusClosed        = "closed"
+	EventStatusCancelled     = "cancelled"
+)
+
+// EventPublicStatuses adalah status event yang boleh dilihat pengunjung umum.
+var EventPublicStatuses = []string{EventStatusActive, EventStatusFunded, EventStatusExpired, EventStatusClosed}
+
+// EventWithdrawableStatuses adalah status event yang dananya boleh ditarik.
+// Event yang dibatalkan tetap bisa ditarik sampai sisa donasinya habis agar
+// dana yang sudah masuk tidak tertahan.
+var EventWithdrawableStatuses = []string{EventStatusActive, EventStatusFunded, EventStatusExpired, EventStatusCancelled}
+
+// EventDeletableStatuses adalah status event yang boleh dihapus permanen.
+var EventDeletableStatuses = []string{EventStatusDraft, EventStatusPendingReview}
+
+func IsEventPublicStatus(status string) bool {
+	return containsStatus(EventPublicStatuses, status)
+}
+
+func IsEventWithdrawableStatus(status string) bool {
+	return containsStatus(EventWithdrawableStatuses, status)
+}
+
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// EventStatusHistory mencatat setiap perubahan status event. ActorID bernilai
+// nil jika perubahan dilakukan oleh sistem, misalnya saat target tercapai.
+type EventStatusHistory struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventID    uuid.UUID  `gorm:"type:uuid;index" json:"event_id"`
+	FromStatus string     `gorm:"type:varchar(20)" json:"from_status"`
+	ToStatus   string     `gorm:"type:varchar(20)" json:"to_status"`
+	Reason     string     `gorm:"type:text" json:"reason"`
+	ActorID    *uuid.UUID `gorm:"type:uuid" json:"actor_id"`
+	CreatedAt  time.Time  `gorm:"type:timestamp with time zone" json:"created_at"`
+}
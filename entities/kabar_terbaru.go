@@ -0,0 +1,29 @@
+package entities
+
+import "github.com/google/uuid"
+
+// KabarTerbaru adalah kabar perkembangan event yang ditulis pembuat event
+// untuk para donatur. HistoryPenarikanID opsional untuk menautkan kabar ke
+// penarikan dana tertentu.
+type KabarTerbaru struct {
+	ID    uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Judul string    `gorm:"type:varchar(150)" json:"judul"`
+	Isi   string    `gorm:"type:text" json:"isi"`
+
+	Gambar []KabarTerbaruGambar `gorm:"foreignKey:KabarTerbaruID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"gambar"`
+
+	HistoryPenarikanID *uint             `gorm:"index" json:"history_penarikan_id"`
+	HistoryPenarikan   *HistoryPenarikan `gorm:"foreignKey:HistoryPenarikanID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+
+	EventID uuid.UUID `gorm:"type:uuid;index" json:"event_id"`
+	UserID  uuid.UUID `gorm:"type:uuid" json:"user_id"`
+
+	Timestamp
+}
+
+type KabarTerbaruGambar struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	URL            string    `gorm:"type:varchar(255)" json:"url"`
+	Urutan         int       `gorm:"not null;default:0" json:"urutan"`
+	KabarTerbaruID uuid.UUID `gorm:"type:uuid;index" json:"kabar_terbaru_id"`
+}
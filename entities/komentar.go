@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Komentar adalah komentar pada event. Komentar yang menyertakan TransaksiID
+// milik penulisnya ditampilkan sebagai doa dari donatur. Balasan hanya satu
+// tingkat, sehingga ParentID selalu menunjuk komentar utama. Komentar yang
+// dihapus penulisnya tetap disimpan tanpa isi agar balasannya tidak hilang.
+type Komentar struct {
+	ID  uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Isi string    `gorm:"type:text" json:"isi"`
+
+	ParentID *uuid.UUID `gorm:"type:uuid;index" json:"parent_id"`
+	Balasan  []Komentar `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"balasan,omitempty"`
+
+	TransaksiID *uuid.UUID `gorm:"type:uuid;index" json:"transaksi_id"`
+	Transaksi   *Transaksi `gorm:"foreignKey:TransaksiID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+
+	EventID uuid.UUID `gorm:"type:uuid;index" json:"event_id"`
+	UserID  uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	User    User      `gorm:"foreignKey:UserID" json:"-"`
+
+	// HiddenByID bernilai nil jika komentar disembunyikan otomatis karena
+	// terlalu banyak laporan
+	HiddenAt     *time.Time `gorm:"type:timestamp with time zone" json:"hidden_at"`
+	HiddenByID   *uuid.UUID `gorm:"type:uuid" json:"hidden_by_id"`
+	HiddenReason string     `gorm:"type:varchar(255)" json:"hidden_reason"`
+
+	ReportCount int              `gorm:"not null;default:0" json:"report_count"`
+	Reports     []KomentarReport `gorm:"foreignKey:KomentarID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	EditedAt    *time.Time       `gorm:"type:timestamp with time zone" json:"edited_at"`
+	DeletedAt   *time.Time       `gorm:"type:timestamp with time zone;index" json:"deleted_at"`
+
+	Timestamp
+}
+
+// KomentarReport adalah laporan user terhadap komentar. Satu user hanya bisa
+// melaporkan komentar yang sama satu kali.
+type KomentarReport struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	KomentarID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_komentar_report_user" json:"komentar_id"`
+	UserID     uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_komentar_report_user" json:"user_id"`
+	Alasan     string    `gorm:"type:varchar(255)" json:"alasan"`
+	CreatedAt  time.Time `gorm:"type:timestamp with time zone" json:"created_at"`
+}
+
+// KomentarRateLimit menghitung komentar user pada window yang sedang
+// berjalan. Counter tidak berkurang ketika komentar dihapus.
+type KomentarRateLimit struct {
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Total       int       `gorm:"not null;default:0" json:"total"`
+	WindowStart time.Time `gorm:"type:timestamp with time zone" json:"window_start"`
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	KYCStatusPending  = "pending"
+	KYCStatusApproved = "approved"
+	KYCStatusRejected = "rejected"
+)
+
+type UserKYC struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+
+	// NIK disimpan terenkripsi, HMAC dari NIK dipakai agar satu NIK hanya
+	// bisa dipakai oleh satu akun.
+	NIKEncrypted string    `gorm:"type:varchar(255)" json:"-"`
+	NIKHash      string    `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	NamaLengkap  string    `gorm:"type:varchar(100)" json:"nama_lengkap"`
+	TanggalLahir time.Time `gorm:"type:date" json:"tanggal_lahir"`
+	JenisKelamin string    `gorm:"type:varchar(1)" json:"jenis_kelamin"`
+	Pekerjaan    string    `gorm:"type:varchar(100)" json:"pekerjaan"`
+	AsalInstansi string    `gorm:"type:varchar(100)" json:"asal_instansi"`
+	KTPPath      string    `gorm:"type:varchar(255)" json:"-"`
+	SelfiePath   string    `gorm:"type:varchar(255)" json:"-"`
+
+	Status          string     `gorm:"type:varchar(20);index" json:"status"`
+	RejectionReason string     `gorm:"type:text" json:"rejection_reason"`
+	SubmittedAt     time.Time  `gorm:"type:timestamp with time zone" json:"submitted_at"`
+	ReviewedAt      *time.Time `gorm:"type:timestamp with time zone" json:"reviewed_at"`
+	ReviewedBy      *uuid.UUID `gorm:"type:uuid" json:"reviewed_by"`
+
+	UserID uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
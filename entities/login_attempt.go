@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttempt adalah catatan audit untuk setiap percobaan login.
+type LoginAttempt struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Email     string     `gorm:"type:varchar(100);index" json:"email"`
+	UserID    *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	IPAddress string     `gorm:"type:varchar(64);index" json:"ip_address"`
+	UserAgent string     `gorm:"type:varchar(255)" json:"user_agent"`
+	Success   bool       `gorm:"type:boolean" json:"success"`
+	Reason    string     `gorm:"type:varchar(100)" json:"reason"`
+	CreatedAt time.Time  `gorm:"type:timestamp with time zone;index" json:"created_at"`
+}
+
+// LoginThrottle menyimpan jumlah kegagalan login per akun atau per IP.
+// Key berbentuk "account:<email>" atau "ip:<alamat ip>".
+type LoginThrottle struct {
+	Key           string     `gorm:"type:varchar(255);primaryKey" json:"key"`
+	Failures      int        `gorm:"type:int" json:"failures"`
+	LastFailureAt time.Time  `gorm:"type:timestamp with time zone" json:"last_failure_at"`
+	LockedUntil   *time.Time `gorm:"type:timestamp with time zone" json:"locked_until"`
+}
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OIDCLoginState menyimpan state dan PKCE verifier selama user berada di
+// halaman login provider. Disimpan di database agar tetap berlaku walaupun
+// callback diterima oleh instance server yang berbeda.
+type OIDCLoginState struct {
+	StateHash             string    `gorm:"type:varchar(64);primaryKey" json:"-"`
+	CodeVerifierEncrypted string    `gorm:"type:varchar(255)" json:"-"`
+	Nonce                 string    `gorm:"type:varchar(64)" json:"-"`
+	ExpiresAt             time.Time `gorm:"type:timestamp with time zone;index" json:"expires_at"`
+	CreatedAt             time.Time `gorm:"type:timestamp with time zone" json:"created_at"`
+
+	// ReauthUserID dan ReauthSessionID hanya terisi ketika flow dipakai untuk
+	// re-autentikasi user yang sedang login, bukan untuk login baru.
+	ReauthUserID    *uuid.UUID `gorm:"type:uuid" json:"-"`
+	ReauthSessionID *uuid.UUID `gorm:"type:uuid" json:"-"`
+}
+
+// UserIdentity menghubungkan akun provider OIDC (issuer + subject) dengan user.
+type UserIdentity struct {
+	ID      uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Issuer  string    `gorm:"type:varchar(255);uniqueIndex:idx_user_identity_issuer_subject" json:"issuer"`
+	Subject string    `gorm:"type:varchar(255);uniqueIndex:idx_user_identity_issuer_subject" json:"subject"`
+	Email   string    `gorm:"type:varchar(100)" json:"email"`
+
+	UserID uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
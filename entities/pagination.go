@@ -1,5 +1,42 @@
 package entities
 
-type Pagination struct {
-	PageNumber  uint `json:"page_number"`
-}
\ No newline at end of file
+import (
+	"github.com/google/uuid"
+)
+
+const (
+	EventSortNewest     = "newest"
+	EventSortMostLiked  = "most_liked"
+	EventSortEndingSoon = "ending_soon"
+	EventSortMostFunded = "most_funded"
+)
+
+// EventQuery berisi filter, urutan dan batas untuk daftar event. Cursor
+// dipakai sebagai pengganti Offset ketika halaman diambil berurutan.
+type EventQuery struct {
+	Search string
+	// CategoryIDs bernilai nil berarti tanpa filter kategori
+	CategoryIDs []uint
+	Statuses    []string
+	UserID      *uuid.UUID
+	Sort        string
+	Limit       int
+	Offset      int
+	Cursor      *EventCursor
+}
+
+// EventCursor menyimpan nilai kolom urutan dan id dari baris terakhir pada
+// halaman sebelumnya. Value bertipe time.Time atau float64 sesuai Sort.
+type EventCursor struct {
+	Value any
+	ID    uuid.UUID
+}
+
+// EventSearchResult adalah event hasil pencarian beserta skor relevansi dan
+// potongan teks yang sudah diberi penanda highlight.
+type EventSearchResult struct {
+	Event
+	SearchRank        float64
+	HeadlineJudul     string
+	HeadlineDeskripsi string
+}
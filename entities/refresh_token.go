@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	FamilyID  uuid.UUID  `gorm:"type:uuid;index" json:"family_id"`
+	ExpiresAt time.Time  `gorm:"type:timestamp with time zone" json:"expires_at"`
+	RevokedAt *time.Time `gorm:"type:timestamp with time zone" json:"revoked_at"`
+
+	ReplacedByID *uuid.UUID `gorm:"type:uuid" json:"replaced_by_id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;index" json:"user_id"`
+	User         User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
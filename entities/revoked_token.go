@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokedToken menyimpan jti dari access token yang sudah logout sampai
+// token tersebut kadaluarsa dengan sendirinya.
+type RevokedToken struct {
+	JTI       string    `gorm:"type:varchar(64);primaryKey" json:"jti"`
+	UserID    uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	ExpiresAt time.Time `gorm:"type:timestamp with time zone;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"type:timestamp with time zone" json:"created_at"`
+}
+
+// UserTokenRevocation mencabut seluruh access token milik user yang
+// diterbitkan sebelum RevokedBefore, digunakan untuk logout dari semua device.
+type UserTokenRevocation struct {
+	UserID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	RevokedBefore time.Time `gorm:"type:timestamp with time zone" json:"revoked_before"`
+}
@@ -0,0 +1,20 @@
+package entities
+
+const (
+	RoleUser       = "user"
+	RoleCampaigner = "campaigner"
+	RoleAdmin      = "admin"
+)
+
+// RoleLevels mengurutkan role dari yang paling rendah ke paling tinggi,
+// digunakan untuk promote dan demote user.
+var RoleLevels = []string{RoleUser, RoleCampaigner, RoleAdmin}
+
+func IsValidRole(role string) bool {
+	for _, r := range RoleLevels {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
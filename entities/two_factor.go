@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserTwoFactor struct {
+	UserID uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	// Secret TOTP disimpan terenkripsi, bukan di-hash, karena harus bisa dibaca
+	// ulang untuk menghitung kode.
+	SecretEncrypted string     `gorm:"type:varchar(255)" json:"-"`
+	EnabledAt       *time.Time `gorm:"type:timestamp with time zone" json:"enabled_at"`
+	LastUsedStep    int64      `gorm:"type:bigint" json:"-"`
+
+	Timestamp
+}
+
+type TwoFactorRecoveryCode struct {
+	ID       uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	CodeHash string     `gorm:"type:varchar(64);index" json:"-"`
+	UsedAt   *time.Time `gorm:"type:timestamp with time zone" json:"used_at"`
+
+	UserID uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
@@ -1,23 +1,31 @@
 package entities
 
 import (
+	"time"
+
 	"github.com/Caknoooo/golang-clean_template/helpers"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type User struct {
-	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Nama            string    `gorm:"type:varchar(100)" json:"nama"`
-	NoTelp          string    `gorm:"type:varchar(30)" json:"no_telp"`
-	Email           string    `gorm:"type:varchar(100)" json:"email"`
-	Password        string    `gorm:"type:varchar(100)" json:"password"`
-	ConfirmPassword string    `gorm:"type:varchar(100)" json:"confirm_password"`
-	Role            string    `gorm:"type:varchar(100)" json:"role"`
-
-	HistoryPenarikan []HistoryPenarikan `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"history_penarikans,omitempty"`
-	Transaksi        []Transaksi        `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"transaksis,omitempty"`
-	Events           []Event            `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"events,omitempty"`
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Nama            string     `gorm:"type:varchar(100)" json:"nama"`
+	NoTelp          string     `gorm:"type:varchar(30)" json:"no_telp"`
+	Email           string     `gorm:"type:varchar(100)" json:"email"`
+	Password        string     `gorm:"type:varchar(255)" json:"-"`
+	ConfirmPassword string     `gorm:"type:varchar(255)" json:"-"`
+	Role            string     `gorm:"type:varchar(100)" json:"role"`
+	EmailVerifiedAt *time.Time `gorm:"type:timestamp with time zone" json:"email_verified_at"`
+
+	// Akun tidak pernah dihapus permanen karena transaksi harus tetap tersimpan.
+	// Setelah masa tenggang berakhir data pribadinya dianonimkan.
+	DeletionScheduledAt *time.Time `gorm:"type:timestamp with time zone;index" json:"deletion_scheduled_at"`
+	AnonymizedAt        *time.Time `gorm:"type:timestamp with time zone" json:"anonymized_at"`
+
+	HistoryPenarikan []HistoryPenarikan `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"history_penarikans,omitempty"`
+	Transaksi        []Transaksi        `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"transaksis,omitempty"`
+	Events           []Event            `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"events,omitempty"`
 	Likes            []Like             `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"likes,omitempty"`
 
 	Timestamp
@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession mewakili satu login di satu device. ID-nya sama dengan family
+// refresh token dan ikut disimpan di access token sebagai claim sid.
+type UserSession struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserAgent  string     `gorm:"type:varchar(255)" json:"user_agent"`
+	IPAddress  string     `gorm:"type:varchar(45)" json:"ip_address"`
+	LastSeenAt time.Time  `gorm:"type:timestamp with time zone" json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"type:timestamp with time zone" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"type:timestamp with time zone" json:"revoked_at"`
+
+	UserID uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
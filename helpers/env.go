@@ -0,0 +1,16 @@
+package helpers
+
+import (
+	"os"
+	"strconv"
+)
+
+// GetEnvInt membaca bilangan bulat positif dari environment. fallback dipakai
+// jika variabel kosong, bukan angka atau tidak lebih dari 0.
+func GetEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
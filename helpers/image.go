@@ -0,0 +1,269 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// MaxImagePixels membatasi resolusi gambar agar file kecil dengan dimensi
+// sangat besar tidak menghabiskan memori saat di-decode.
+const MaxImagePixels = 24_000_000
+
+var (
+	ErrUnsupportedImage = errors.New("File Harus Berupa Gambar JPG Atau PNG")
+	ErrImageTooLarge    = errors.New("Resolusi Gambar Terlalu Besar")
+)
+
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// ProcessedImage berisi gambar yang sudah di-encode ulang beserta thumbnail
+// dengan format yang sama.
+type ProcessedImage struct {
+	Data        []byte
+	Thumbnail   []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// ProcessImage memeriksa tipe gambar dari isinya lalu meng-encode ulang
+// gambar tersebut. Encode ulang membuang seluruh metadata termasuk EXIF,
+// sehingga orientasi dari EXIF diterapkan lebih dulu ke piksel gambar.
+func ProcessImage(data []byte, thumbnailSize int) (ProcessedImage, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return ProcessedImage{}, ErrUnsupportedImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ProcessedImage{}, ErrUnsupportedImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxImagePixels {
+		return ProcessedImage{}, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ProcessedImage{}, ErrUnsupportedImage
+	}
+	if contentType == "image/jpeg" {
+		img = applyOrientation(toRGBA(img), jpegOrientation(data))
+	}
+
+	original, err := encodeImage(img, contentType)
+	if err != nil {
+		return ProcessedImage{}, err
+	}
+	thumbnail, err := encodeImage(Thumbnail(img, thumbnailSize), contentType)
+	if err != nil {
+		return ProcessedImage{}, err
+	}
+
+	bounds := img.Bounds()
+	return ProcessedImage{
+		Data:        original,
+		Thumbnail:   thumbnail,
+		ContentType: contentType,
+		Extension:   ext,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+	}, nil
+}
+
+func encodeImage(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// Thumbnail memperkecil gambar agar muat di dalam kotak size x size dengan
+// rasio yang sama. Setiap piksel hasil adalah rata-rata area piksel asal.
+// Gambar yang sudah lebih kecil dikembalikan apa adanya.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if size <= 0 || (srcW <= size && srcH <= size) {
+		return img
+	}
+
+	dstW, dstH := size, srcH*size/srcW
+	if srcH > srcW {
+		dstW, dstH = srcW*size/srcH, size
+	}
+	if dstW < 1 {
+		dstW = 1
+	}
+	if dstH < 1 {
+		dstH = 1
+	}
+
+	src := toRGBA(img)
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, (y+1)*srcH/dstH
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, (x+1)*srcW/dstW
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// jpegOrientation membaca tag Orientation (0x0112) dari segmen EXIF. Nilai 1
+// dikembalikan jika tag tidak ada atau tidak bisa dibaca.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// Segmen gambar dimulai setelah SOS, EXIF selalu berada sebelumnya
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != 0x0112 {
+			continue
+		}
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return 1
+		}
+		return orientation
+	}
+	return 1
+}
+
+// applyOrientation memutar atau mencerminkan gambar sesuai nilai Orientation
+// EXIF sehingga hasilnya tampil tegak tanpa metadata.
+func applyOrientation(src *image.RGBA, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	srcW, srcH := src.Rect.Dx(), src.Rect.Dy()
+	dstW, dstH := srcW, srcH
+	if orientation >= 5 {
+		dstW, dstH = srcH, srcW
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = srcW-1-x, y
+			case 3:
+				sx, sy = srcW-1-x, srcH-1-y
+			case 4:
+				sx, sy = x, srcH-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, srcH-1-x
+			case 7:
+				sx, sy = srcW-1-y, srcH-1-x
+			case 8:
+				sx, sy = srcW-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
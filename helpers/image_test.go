@@ -0,0 +1,225 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	// Pojok kiri atas ditandai merah untuk memeriksa orientasi
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeTestPNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeTestJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withEXIFOrientation menyisipkan segmen APP1 EXIF berisi tag Orientation
+// tepat setelah marker SOI.
+func withEXIFOrientation(data []byte, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	copy(tiff, "II")
+	binary.LittleEndian.PutUint16(tiff[2:], 42)
+	binary.LittleEndian.PutUint32(tiff[4:], 8)
+	binary.LittleEndian.PutUint16(tiff[8:], 1)
+	binary.LittleEndian.PutUint16(tiff[10:], 0x0112)
+	binary.LittleEndian.PutUint16(tiff[12:], 3)
+	binary.LittleEndian.PutUint32(tiff[14:], 1)
+	binary.LittleEndian.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	result := append([]byte{}, data[:2]...)
+	result = append(result, app1...)
+	return append(result, data[2:]...)
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r>>8 > 200 && g>>8 < 60 && b>>8 < 60
+}
+
+func TestProcessImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		thumbnailSize int
+		contentType   string
+		extension     string
+		width         int
+		height        int
+		thumbWidth    int
+		thumbHeight   int
+	}{
+		{
+			name:          "png",
+			data:          encodeTestPNG(t, testImage(200, 100)),
+			thumbnailSize: 50,
+			contentType:   "image/png",
+			extension:     ".png",
+			width:         200,
+			height:        100,
+			thumbWidth:    50,
+			thumbHeight:   25,
+		},
+		{
+			name:          "jpeg portrait",
+			data:          encodeTestJPEG(t, testImage(60, 120)),
+			thumbnailSize: 40,
+			contentType:   "image/jpeg",
+			extension:     ".jpg",
+			width:         60,
+			height:        120,
+			thumbWidth:    20,
+			thumbHeight:   40,
+		},
+		{
+			name:          "gambar kecil tidak diperbesar",
+			data:          encodeTestPNG(t, testImage(30, 20)),
+			thumbnailSize: 320,
+			contentType:   "image/png",
+			extension:     ".png",
+			width:         30,
+			height:        20,
+			thumbWidth:    30,
+			thumbHeight:   20,
+		},
+		{
+			name:          "jpeg dengan orientasi exif",
+			data:          withEXIFOrientation(encodeTestJPEG(t, testImage(80, 40)), 6),
+			thumbnailSize: 20,
+			contentType:   "image/jpeg",
+			extension:     ".jpg",
+			width:         40,
+			height:        80,
+			thumbWidth:    10,
+			thumbHeight:   20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ProcessImage(tt.data, tt.thumbnailSize)
+			if err != nil {
+				t.Fatalf("ProcessImage: %v", err)
+			}
+			if result.ContentType != tt.contentType || result.Extension != tt.extension {
+				t.Errorf("tipe = %s %s, want %s %s", result.ContentType, result.Extension, tt.contentType, tt.extension)
+			}
+			if result.Width != tt.width || result.Height != tt.height {
+				t.Errorf("ukuran = %dx%d, want %dx%d", result.Width, result.Height, tt.width, tt.height)
+			}
+
+			img, _, err := image.Decode(bytes.NewReader(result.Data))
+			if err != nil {
+				t.Fatalf("decode hasil: %v", err)
+			}
+			if img.Bounds().Dx() != tt.width || img.Bounds().Dy() != tt.height {
+				t.Errorf("ukuran hasil = %v", img.Bounds())
+			}
+			thumb, _, err := image.Decode(bytes.NewReader(result.Thumbnail))
+			if err != nil {
+				t.Fatalf("decode thumbnail: %v", err)
+			}
+			if thumb.Bounds().Dx() != tt.thumbWidth || thumb.Bounds().Dy() != tt.thumbHeight {
+				t.Errorf("ukuran thumbnail = %dx%d, want %dx%d", thumb.Bounds().Dx(), thumb.Bounds().Dy(), tt.thumbWidth, tt.thumbHeight)
+			}
+		})
+	}
+}
+
+func TestProcessImageStripsEXIF(t *testing.T) {
+	data := withEXIFOrientation(encodeTestJPEG(t, testImage(80, 40)), 6)
+	result, err := ProcessImage(data, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(result.Data, []byte("Exif\x00\x00")) {
+		t.Error("hasil masih berisi segmen EXIF")
+	}
+	if jpegOrientation(result.Data) != 1 {
+		t.Error("hasil masih berisi tag Orientation")
+	}
+
+	// Orientasi 6 memutar 90 derajat searah jarum jam sehingga pojok kiri
+	// atas pindah ke pojok kanan atas
+	img, _, err := image.Decode(bytes.NewReader(result.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isRed(img.At(img.Bounds().Dx()-2, 1)) {
+		t.Errorf("pojok kanan atas = %v, want merah", img.At(img.Bounds().Dx()-2, 1))
+	}
+	if isRed(img.At(1, 1)) {
+		t.Error("pojok kiri atas masih merah, orientasi tidak diterapkan")
+	}
+}
+
+func TestProcessImageRejectsInvalidInput(t *testing.T) {
+	pngData := encodeTestPNG(t, testImage(10, 10))
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"kosong", nil, ErrUnsupportedImage},
+		{"teks", []byte("bukan gambar"), ErrUnsupportedImage},
+		{"gif", []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), ErrUnsupportedImage},
+		{"png terpotong", pngData[:len(pngData)/2], ErrUnsupportedImage},
+		{"resolusi terlalu besar", pngHeader(10_000, 10_000), ErrImageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ProcessImage(tt.data, 10); !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// pngHeader membuat PNG yang hanya berisi signature dan chunk IHDR, cukup
+// untuk dibaca image.DecodeConfig.
+func pngHeader(width, height uint32) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+	data := buf.Bytes()[:33]
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
@@ -0,0 +1,155 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Kode provinsi yang dipakai pada 2 digit pertama NIK.
+var nikProvinceCodes = map[string]string{
+	"11": "Aceh",
+	"12": "Sumatera Utara",
+	"13": "Sumatera Barat",
+	"14": "Riau",
+	"15": "Jambi",
+	"16": "Sumatera Selatan",
+	"17": "Bengkulu",
+	"18": "Lampung",
+	"19": "Kepulauan Bangka Belitung",
+	"21": "Kepulauan Riau",
+	"31": "DKI Jakarta",
+	"32": "Jawa Barat",
+	"33": "Jawa Tengah",
+	"34": "DI Yogyakarta",
+	"35": "Jawa Timur",
+	"36": "Banten",
+	"51": "Bali",
+	"52": "Nusa Tenggara Barat",
+	"53": "Nusa Tenggara Timur",
+	"61": "Kalimantan Barat",
+	"62": "Kalimantan Tengah",
+	"63": "Kalimantan Selatan",
+	"64": "Kalimantan Timur",
+	"65": "Kalimantan Utara",
+	"71": "Sulawesi Utara",
+	"72": "Sulawesi Tengah",
+	"73": "Sulawesi Selatan",
+	"74": "Sulawesi Tenggara",
+	"75": "Gorontalo",
+	"76": "Sulawesi Barat",
+	"81": "Maluku",
+	"82": "Maluku Utara",
+	"91": "Papua",
+	"92": "Papua Barat",
+	"93": "Papua Selatan",
+	"94": "Papua Tengah",
+	"95": "Papua Pegunungan",
+	"96": "Papua Barat Daya",
+}
+
+const (
+	GenderMale   = "L"
+	GenderFemale = "P"
+)
+
+type NIKInfo struct {
+	ProvinceCode string
+	Province     string
+	RegencyCode  string
+	DistrictCode string
+	BirthDate    time.Time
+	Gender       string
+}
+
+// ParseNIK memvalidasi struktur NIK 16 digit: kode wilayah (provinsi,
+// kabupaten/kota, kecamatan), tanggal lahir DDMMYY dengan tanggal ditambah 40
+// untuk perempuan, dan nomor urut yang tidak boleh 0000.
+func ParseNIK(nik string, now time.Time) (NIKInfo, error) {
+	if len(nik) != 16 {
+		return NIKInfo{}, errors.New("NIK Harus 16 Digit")
+	}
+	for _, c := range nik {
+		if c < '0' || c > '9' {
+			return NIKInfo{}, errors.New("NIK Hanya Boleh Berisi Angka")
+		}
+	}
+
+	info := NIKInfo{
+		ProvinceCode: nik[0:2],
+		RegencyCode:  nik[2:4],
+		DistrictCode: nik[4:6],
+	}
+
+	province, ok := nikProvinceCodes[info.ProvinceCode]
+	if !ok {
+		return NIKInfo{}, errors.New("Kode Provinsi Pada NIK Tidak Valid")
+	}
+	info.Province = province
+
+	if info.RegencyCode == "00" || info.DistrictCode == "00" {
+		return NIKInfo{}, errors.New("Kode Wilayah Pada NIK Tidak Valid")
+	}
+
+	day, _ := strconv.Atoi(nik[6:8])
+	month, _ := strconv.Atoi(nik[8:10])
+	year, _ := strconv.Atoi(nik[10:12])
+
+	info.Gender = GenderMale
+	if day > 40 {
+		day -= 40
+		info.Gender = GenderFemale
+	}
+
+	// Tahun dua digit diasumsikan berada di abad ini kecuali menghasilkan
+	// tanggal di masa depan.
+	fullYear := 2000 + year
+	if fullYear > now.Year() {
+		fullYear = 1900 + year
+	}
+
+	birthDate := time.Date(fullYear, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if day < 1 || month < 1 || month > 12 || birthDate.Day() != day || birthDate.Month() != time.Month(month) {
+		return NIKInfo{}, errors.New("Tanggal Lahir Pada NIK Tidak Valid")
+	}
+	if birthDate.After(now) {
+		return NIKInfo{}, errors.New("Tanggal Lahir Pada NIK Tidak Valid")
+	}
+	info.BirthDate = birthDate
+
+	if nik[12:16] == "0000" {
+		return NIKInfo{}, errors.New("Nomor Urut Pada NIK Tidak Valid")
+	}
+	return info, nil
+}
+
+// MaskNIK hanya menampilkan kode wilayah dan nomor urut.
+func MaskNIK(nik string) string {
+	if len(nik) != 16 {
+		return nik
+	}
+	return nik[:6] + "******" + nik[12:]
+}
+
+// HashNIK dipakai untuk mencari NIK yang sudah terdaftar tanpa menyimpan NIK
+// asli. Jumlah kemungkinan NIK cukup kecil untuk ditebak satu per satu,
+// sehingga hash memakai HMAC dengan kunci yang hanya diketahui server.
+func HashNIK(nik string) string {
+	mac := hmac.New(sha256.New, getNIKHashKey())
+	mac.Write([]byte(nik))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// getNIKHashKey memakai NIK_HASH_KEY, atau diturunkan dari kunci enkripsi
+// agar tidak sama persis dengan kunci yang dipakai untuk enkripsi.
+func getNIKHashKey() []byte {
+	if secret := os.Getenv("NIK_HASH_KEY"); secret != "" {
+		return []byte(secret)
+	}
+	sum := sha256.Sum256(append([]byte("nik-hash:"), getEncryptionKey()...))
+	return sum[:]
+}
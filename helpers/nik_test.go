@@ -0,0 +1,82 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNIK(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		nik       string
+		wantErr   string
+		province  string
+		birthDate time.Time
+		gender    string
+	}{
+		{
+			name:      "laki-laki",
+			nik:       "3201011203050002",
+			province:  "Jawa Barat",
+			birthDate: time.Date(2005, 3, 12, 0, 0, 0, 0, time.UTC),
+			gender:    GenderMale,
+		},
+		{
+			name:      "perempuan tanggal ditambah 40",
+			nik:       "3171064508900001",
+			province:  "DKI Jakarta",
+			birthDate: time.Date(1990, 8, 5, 0, 0, 0, 0, time.UTC),
+			gender:    GenderFemale,
+		},
+		{
+			name:      "tahun di masa depan dianggap abad lalu",
+			nik:       "3578012901300001",
+			province:  "Jawa Timur",
+			birthDate: time.Date(1930, 1, 29, 0, 0, 0, 0, time.UTC),
+			gender:    GenderMale,
+		},
+		{name: "kurang dari 16 digit", nik: "320101120305000", wantErr: "NIK Harus 16 Digit"},
+		{name: "lebih dari 16 digit", nik: "32010112030500021", wantErr: "NIK Harus 16 Digit"},
+		{name: "berisi huruf", nik: "32010112030500A2", wantErr: "NIK Hanya Boleh Berisi Angka"},
+		{name: "provinsi tidak dikenal", nik: "9901011203050002", wantErr: "Kode Provinsi Pada NIK Tidak Valid"},
+		{name: "kabupaten 00", nik: "3200011203050002", wantErr: "Kode Wilayah Pada NIK Tidak Valid"},
+		{name: "kecamatan 00", nik: "3201001203050002", wantErr: "Kode Wilayah Pada NIK Tidak Valid"},
+		{name: "tanggal 00", nik: "3201010003050002", wantErr: "Tanggal Lahir Pada NIK Tidak Valid"},
+		{name: "tanggal 32", nik: "3201013203050002", wantErr: "Tanggal Lahir Pada NIK Tidak Valid"},
+		{name: "30 februari", nik: "3201013002050002", wantErr: "Tanggal Lahir Pada NIK Tidak Valid"},
+		{name: "bulan 13", nik: "3201011213050002", wantErr: "Tanggal Lahir Pada NIK Tidak Valid"},
+		{name: "lahir setelah hari ini", nik: "3201011506240002", wantErr: "Tanggal Lahir Pada NIK Tidak Valid"},
+		{name: "nomor urut 0000", nik: "3201011203050000", wantErr: "Nomor Urut Pada NIK Tidak Valid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseNIK(tt.nik, now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseNIK: %v", err)
+			}
+			if info.Province != tt.province || !info.BirthDate.Equal(tt.birthDate) || info.Gender != tt.gender {
+				t.Errorf("info = %+v", info)
+			}
+			if info.ProvinceCode != tt.nik[0:2] || info.RegencyCode != tt.nik[2:4] || info.DistrictCode != tt.nik[4:6] {
+				t.Errorf("kode wilayah = %s %s %s", info.ProvinceCode, info.RegencyCode, info.DistrictCode)
+			}
+		})
+	}
+}
+
+func TestMaskNIK(t *testing.T) {
+	if got := MaskNIK("3201011203050002"); got != "320101******0002" {
+		t.Errorf("MaskNIK = %s", got)
+	}
+	if got := MaskNIK("123"); got != "123" {
+		t.Errorf("MaskNIK nilai pendek = %s", got)
+	}
+}
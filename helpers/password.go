@@ -1,18 +1,181 @@
 package helpers
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func HashPassword(password string)(string, error){
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 4)
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+// PasswordConfig menentukan algoritma dan parameter untuk hash password baru.
+// Hash yang tersimpan selalu menyimpan algoritma dan parameternya sendiri
+// ($2a$<cost>$... untuk bcrypt, $argon2id$v=19$m=..,t=..,p=..$... untuk argon2id),
+// sehingga hash lama tetap bisa dicek dan dikenali jika perlu di-upgrade.
+type PasswordConfig struct {
+	Algorithm     string
+	BcryptCost    int
+	Argon2Memory  uint32
+	Argon2Time    uint32
+	Argon2Threads uint8
+}
+
+var (
+	passwordConfig     PasswordConfig
+	passwordConfigOnce sync.Once
+)
+
+func getPasswordConfig() PasswordConfig {
+	passwordConfigOnce.Do(func() {
+		passwordConfig = loadPasswordConfig()
+	})
+	return passwordConfig
+}
+
+// loadPasswordConfig membaca konfigurasi dari environment. Nilai di luar
+// jangkauan tipe parameter argon2 dibatasi agar tidak berputar ke 0 saat
+// dikonversi.
+func loadPasswordConfig() PasswordConfig {
+	config := PasswordConfig{
+		Algorithm:     os.Getenv("PASSWORD_HASH_ALGORITHM"),
+		BcryptCost:    GetEnvInt("BCRYPT_COST", 12),
+		Argon2Memory:  uint32(clampInt(GetEnvInt("ARGON2_MEMORY_KB", 64*1024), math.MaxInt32)),
+		Argon2Time:    uint32(clampInt(GetEnvInt("ARGON2_TIME", 3), math.MaxInt32)),
+		Argon2Threads: uint8(clampInt(GetEnvInt("ARGON2_THREADS", 2), math.MaxUint8)),
+	}
+	if config.Algorithm != PasswordAlgorithmArgon2id {
+		config.Algorithm = PasswordAlgorithmBcrypt
+	}
+	if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+		config.BcryptCost = 12
+	}
+	return config
+}
+
+func clampInt(value int, max int) int {
+	if value > max {
+		return max
+	}
+	return value
+}
+
+func HashPassword(password string) (string, error) {
+	config := getPasswordConfig()
+	if config.Algorithm == PasswordAlgorithmArgon2id {
+		return hashArgon2id(password, config)
+	}
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), config.BcryptCost)
 	return string(bytes), err
 }
 
-func CheckPassword(hashPassword string, plainPassword []byte)(bool, error){
+func CheckPassword(hashPassword string, plainPassword []byte) (bool, error) {
+	if strings.HasPrefix(hashPassword, "$argon2id$") {
+		return checkArgon2id(hashPassword, plainPassword)
+	}
+
 	hashPW := []byte(hashPassword)
-	if err := bcrypt.CompareHashAndPassword(hashPW, plainPassword); err != nil{
+	if err := bcrypt.CompareHashAndPassword(hashPW, plainPassword); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PasswordNeedsRehash bernilai true jika hash dibuat dengan algoritma atau
+// parameter yang berbeda dari konfigurasi saat ini.
+func PasswordNeedsRehash(hashPassword string) bool {
+	config := getPasswordConfig()
+	if strings.HasPrefix(hashPassword, "$argon2id$") {
+		if config.Algorithm != PasswordAlgorithmArgon2id {
+			return true
+		}
+		params, _, _, err := decodeArgon2id(hashPassword)
+		if err != nil {
+			return true
+		}
+		return params.memory != config.Argon2Memory || params.time != config.Argon2Time || params.threads != config.Argon2Threads
+	}
+
+	if config.Algorithm != PasswordAlgorithmBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hashPassword))
+	if err != nil {
+		return true
+	}
+	return cost != config.BcryptCost
+}
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+func hashArgon2id(password string, config PasswordConfig) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	hash := argon2.IDKey([]byte(password), salt, config.Argon2Time, config.Argon2Memory, config.Argon2Threads, 32)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		config.Argon2Memory,
+		config.Argon2Time,
+		config.Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+func decodeArgon2id(hashPassword string) (argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hashPassword, "$")
+	if len(parts) != 6 {
+		return argon2Params{}, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Params{}, nil, nil, errors.New("unsupported argon2id version")
+	}
+
+	var params argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return argon2Params{}, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Params{}, nil, nil, err
+	}
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return argon2Params{}, nil, nil, err
+	}
+	return params, salt, hash, nil
+}
+
+func checkArgon2id(hashPassword string, plainPassword []byte) (bool, error) {
+	params, salt, hash, err := decodeArgon2id(hashPassword)
+	if err != nil {
 		return false, err
 	}
+
+	compare := argon2.IDKey(plainPassword, salt, params.time, params.memory, params.threads, uint32(len(hash)))
+	if subtle.ConstantTimeCompare(hash, compare) != 1 {
+		return false, errors.New("password does not match")
+	}
 	return true, nil
-}
\ No newline at end of file
+}
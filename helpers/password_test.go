@@ -0,0 +1,126 @@
+package helpers
+
+import (
+	"testing"
+)
+
+// setPasswordConfig mengganti konfigurasi hash selama satu test.
+func setPasswordConfig(t *testing.T, config PasswordConfig) {
+	t.Helper()
+	previous := getPasswordConfig()
+	passwordConfig = config
+	t.Cleanup(func() { passwordConfig = previous })
+}
+
+var (
+	testBcryptConfig = PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: 4}
+	testArgon2Config = PasswordConfig{Algorithm: PasswordAlgorithmArgon2id, Argon2Memory: 1024, Argon2Time: 1, Argon2Threads: 1}
+)
+
+func TestHashAndCheckPassword(t *testing.T) {
+	for _, config := range []PasswordConfig{testBcryptConfig, testArgon2Config} {
+		t.Run(config.Algorithm, func(t *testing.T) {
+			setPasswordConfig(t, config)
+			hash, err := HashPassword("rahasia123")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := CheckPassword(hash, []byte("rahasia123")); !ok || err != nil {
+				t.Errorf("CheckPassword password benar = %v, %v", ok, err)
+			}
+			if ok, _ := CheckPassword(hash, []byte("salah")); ok {
+				t.Error("CheckPassword menerima password salah")
+			}
+			if PasswordNeedsRehash(hash) {
+				t.Error("hash baru tidak boleh perlu di-rehash")
+			}
+		})
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	setPasswordConfig(t, testBcryptConfig)
+	bcryptHash, err := HashPassword("rahasia123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	setPasswordConfig(t, testArgon2Config)
+	argon2Hash, err := HashPassword("rahasia123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withArgon2 := func(change func(config *PasswordConfig)) PasswordConfig {
+		config := testArgon2Config
+		change(&config)
+		return config
+	}
+	tests := []struct {
+		name   string
+		config PasswordConfig
+		hash   string
+		want   bool
+	}{
+		{"bcrypt dengan cost sama", testBcryptConfig, bcryptHash, false},
+		{"bcrypt dengan cost berbeda", PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: 5}, bcryptHash, true},
+		{"bcrypt saat konfigurasi argon2id", testArgon2Config, bcryptHash, true},
+		{"argon2id saat konfigurasi bcrypt", testBcryptConfig, argon2Hash, true},
+		{"argon2id dengan parameter sama", testArgon2Config, argon2Hash, false},
+		{"argon2id memory berbeda", withArgon2(func(c *PasswordConfig) { c.Argon2Memory = 2048 }), argon2Hash, true},
+		{"argon2id time berbeda", withArgon2(func(c *PasswordConfig) { c.Argon2Time = 2 }), argon2Hash, true},
+		{"argon2id threads berbeda", withArgon2(func(c *PasswordConfig) { c.Argon2Threads = 2 }), argon2Hash, true},
+		{"hash argon2id rusak", testArgon2Config, "$argon2id$v=19$rusak", true},
+		{"hash bcrypt rusak", testBcryptConfig, "bukan-hash", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setPasswordConfig(t, tt.config)
+			if got := PasswordNeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("PasswordNeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPasswordConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want PasswordConfig
+	}{
+		{
+			name: "default",
+			want: PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: 12, Argon2Memory: 64 * 1024, Argon2Time: 3, Argon2Threads: 2},
+		},
+		{
+			name: "algoritma tidak dikenal dan cost di luar batas",
+			env:  map[string]string{"PASSWORD_HASH_ALGORITHM": "md5", "BCRYPT_COST": "40"},
+			want: PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: 12, Argon2Memory: 64 * 1024, Argon2Time: 3, Argon2Threads: 2},
+		},
+		{
+			name: "threads 256 tidak berputar ke 0",
+			env:  map[string]string{"PASSWORD_HASH_ALGORITHM": "argon2id", "ARGON2_THREADS": "256"},
+			want: PasswordConfig{Algorithm: PasswordAlgorithmArgon2id, BcryptCost: 12, Argon2Memory: 64 * 1024, Argon2Time: 3, Argon2Threads: 255},
+		},
+		{
+			name: "threads 300 dibatasi",
+			env:  map[string]string{"ARGON2_THREADS": "300"},
+			want: PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: 12, Argon2Memory: 64 * 1024, Argon2Time: 3, Argon2Threads: 255},
+		},
+		{
+			name: "nilai negatif memakai default",
+			env:  map[string]string{"ARGON2_THREADS": "-1", "ARGON2_TIME": "0"},
+			want: PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: 12, Argon2Memory: 64 * 1024, Argon2Time: 3, Argon2Threads: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"PASSWORD_HASH_ALGORITHM", "BCRYPT_COST", "ARGON2_MEMORY_KB", "ARGON2_TIME", "ARGON2_THREADS"} {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := loadPasswordConfig(); got != tt.want {
+				t.Errorf("loadPasswordConfig = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package helpers
+
+import "strings"
+
+// Slugify mengubah nama menjadi slug huruf kecil yang hanya berisi huruf,
+// angka dan tanda hubung.
+func Slugify(value string) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(value) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			builder.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && builder.Len() > 0 {
+			builder.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "-")
+}
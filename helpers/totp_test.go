@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret "12345678901234567890" dari vektor uji RFC 6238 dalam base32.
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTPCode(t *testing.T) {
+	// Kode pada RFC 6238 memakai 8 digit, enam digit terakhirnya sama dengan
+	// kode 6 digit
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		code, err := GenerateTOTPCode(testTOTPSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("GenerateTOTPCode(%d): %v", tt.unix, err)
+		}
+		if code != tt.code {
+			t.Errorf("GenerateTOTPCode(%d) = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+
+	lower, err := GenerateTOTPCode(strings.ToLower(testTOTPSecret), TOTPStep(time.Unix(59, 0)))
+	if err != nil || lower != "287082" {
+		t.Errorf("secret huruf kecil = %s, %v", lower, err)
+	}
+	if _, err := GenerateTOTPCode("bukan-base32!", 1); err == nil {
+		t.Error("secret tidak valid harus ditolak")
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+	codeAt := func(offset int64) string {
+		code, err := GenerateTOTPCode(testTOTPSecret, step+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name  string
+		code  string
+		step  int64
+		valid bool
+	}{
+		{"step saat ini", codeAt(0), step, true},
+		{"satu step sebelumnya", codeAt(-1), step - 1, true},
+		{"satu step sesudahnya", codeAt(1), step + 1, true},
+		{"dua step sebelumnya", codeAt(-2), 0, false},
+		{"dua step sesudahnya", codeAt(2), 0, false},
+		{"spasi di sekitar kode", " " + codeAt(0) + " ", step, true},
+		{"terlalu pendek", codeAt(0)[:5], 0, false},
+		{"terlalu panjang", codeAt(0) + "1", 0, false},
+		{"kosong", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ValidateTOTPCode(testTOTPSecret, tt.code, now)
+			if ok != tt.valid || got != tt.step {
+				t.Errorf("ValidateTOTPCode = (%d, %v), want (%d, %v)", got, ok, tt.step, tt.valid)
+			}
+		})
+	}
+
+	if _, ok := ValidateTOTPCode("bukan-base32!", codeAt(0), now); ok {
+		t.Error("secret tidak valid harus ditolak")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("panjang secret = %d, want 32", len(secret))
+	}
+	if _, err := GenerateTOTPCode(secret, 1); err != nil {
+		t.Errorf("secret tidak bisa dipakai: %v", err)
+	}
+}
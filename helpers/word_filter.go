@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WordFilter mencari kata atau frasa terlarang pada teks. Pencocokan
+// dilakukan per kata tanpa membedakan huruf besar dan kecil, sehingga "kelas"
+// tidak ikut tertangkap oleh kata terlarang "las". Frasa seperti "judi online"
+// cocok jika semua katanya muncul berurutan.
+type WordFilter struct {
+	// phrases dikelompokkan berdasarkan kata pertamanya
+	phrases map[string][][]string
+}
+
+func NewWordFilter(words []string) *WordFilter {
+	filter := &WordFilter{phrases: make(map[string][][]string, len(words))}
+	for _, word := range words {
+		tokens := tokenize(word)
+		if len(tokens) > 0 {
+			filter.phrases[tokens[0]] = append(filter.phrases[tokens[0]], tokens)
+		}
+	}
+	return filter
+}
+
+// ParseWordList memecah daftar kata yang dipisahkan koma atau baris baru.
+func ParseWordList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Find mengembalikan kata atau frasa terlarang pertama yang ditemukan pada
+// text.
+func (f *WordFilter) Find(text string) (string, bool) {
+	if len(f.phrases) == 0 {
+		return "", false
+	}
+	tokens := tokenize(text)
+	for i, token := range tokens {
+		for _, phrase := range f.phrases[token] {
+			if matchTokens(tokens[i:], phrase) {
+				return strings.Join(phrase, " "), true
+			}
+		}
+	}
+	return "", false
+}
+
+func matchTokens(tokens []string, phrase []string) bool {
+	if len(tokens) < len(phrase) {
+		return false
+	}
+	for i, word := range phrase {
+		if tokens[i] != word {
+			return false
+		}
+	}
+	return true
+}
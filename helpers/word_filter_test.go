@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWordFilterFind(t *testing.T) {
+	filter := NewWordFilter([]string{"las", "Judi Online", "  ", "slot-gacor"})
+	tests := []struct {
+		name  string
+		text  string
+		word  string
+		found bool
+	}{
+		{"kata persis", "tukang las di sini", "las", true},
+		{"huruf besar", "TUKANG LAS", "las", true},
+		{"bagian dari kata lain", "belajar di kelas", "", false},
+		{"frasa berurutan", "ayo main judi online sekarang", "judi online", true},
+		{"frasa dengan tanda baca", "judi, online!", "judi online", true},
+		{"frasa tidak berurutan", "judi itu bukan online", "", false},
+		{"frasa terpotong di akhir", "main judi", "", false},
+		{"frasa dari kata bertanda hubung", "info slot gacor", "slot gacor", true},
+		{"teks kosong", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			word, found := filter.Find(tt.text)
+			if word != tt.word || found != tt.found {
+				t.Errorf("Find(%q) = (%q, %v), want (%q, %v)", tt.text, word, found, tt.word, tt.found)
+			}
+		})
+	}
+
+	if _, found := NewWordFilter(nil).Find("apa saja"); found {
+		t.Error("filter kosong tidak boleh menemukan kata")
+	}
+}
+
+func TestParseWordList(t *testing.T) {
+	got := ParseWordList("judi,slot\r\nlas\n")
+	want := []string{"judi", "slot", "las"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseWordList = %q, want %q", got, want)
+	}
+}
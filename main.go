@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/Caknoooo/golang-clean_template/config"
 	"github.com/Caknoooo/golang-clean_template/controller"
@@ -17,38 +20,100 @@ import (
 
 func main() {
 	var (
-		db                   *gorm.DB                        = config.SetUpDatabaseConnection()
-		jwtService           services.JWTService             = services.NewJWTService()
-		pembayaranRepository repository.PembayaranRepository = repository.NewPembayaranRepository(db)
-		pembayaranService    services.PembayaranService      = services.NewPembayaranService(pembayaranRepository)
-		transaksiRepository  repository.TransaksiRepository  = repository.NewTransaksiRepository(db)
-		transaksiService     services.TransaksiService       = services.NewTransaksiService(transaksiRepository)
-		transaksiController  controller.TransaksiController  = controller.NewTransaksiController(transaksiService, jwtService)
-		eventRepository      repository.EventRepository      = repository.NewEventRepository(db)
-		eventService         services.EventService           = services.NewEventService(eventRepository)
-		eventController      controller.EventController      = controller.NewEventController(eventService, transaksiService, jwtService, db)
-		userRepository       repository.UserRepository       = repository.NewUserRepository(db)
-		userService          services.UserService            = services.NewUserService(userRepository)
-		userController       controller.UserController       = controller.NewUserController(userService, transaksiService, pembayaranService, eventService, db, jwtService)
-		seederRepository     repository.SeederRepository     = repository.NewSeederRepository(db)
-		seederService        services.SeederService          = services.NewSeederService(seederRepository)
-		seederController     controller.SeederController     = controller.NewSeederController(seederService)
-		penarikanRepository  repository.PenarikanRepository  = repository.NewPenarikanRepository(db)
-		penarikanService services.PenarikanService = services.NewPenarikanService(penarikanRepository)
-		penarikanController controller.PenarikanController = controller.NewPenarikanController(userService, eventService, penarikanService, db, jwtService)
+		db                      *gorm.DB                              = config.SetUpDatabaseConnection()
+		revokedTokenRepository  repository.RevokedTokenRepository     = repository.NewRevokedTokenRepository(db)
+		jwtService              services.JWTService                   = services.NewJWTService(revokedTokenRepository)
+		mailer                  services.Mailer                       = services.NewMailer()
+		userRepository          repository.UserRepository             = repository.NewUserRepository(db)
+		passwordResetRepository repository.PasswordResetRepository    = repository.NewPasswordResetRepository(db)
+		emailChangeRepository   repository.EmailChangeRepository      = repository.NewEmailChangeRepository(db)
+		userService             services.UserService                  = services.NewUserService(userRepository, passwordResetRepository, emailChangeRepository, mailer, jwtService)
+		twoFactorRepository     repository.TwoFactorRepository        = repository.NewTwoFactorRepository(db)
+		twoFactorService        services.TwoFactorService             = services.NewTwoFactorService(twoFactorRepository, userRepository, jwtService)
+		pembayaranRepository    repository.PembayaranRepository       = repository.NewPembayaranRepository(db)
+		pembayaranService       services.PembayaranService            = services.NewPembayaranService(pembayaranRepository)
+		transaksiRepository     repository.TransaksiRepository        = repository.NewTransaksiRepository(db)
+		transaksiService        services.TransaksiService             = services.NewTransaksiService(transaksiRepository)
+		transaksiController     controller.TransaksiController        = controller.NewTransaksiController(transaksiService, jwtService)
+		eventRepository         repository.EventRepository            = repository.NewEventRepository(db)
+		categoryRepository      repository.CategoryRepository         = repository.NewCategoryRepository(db)
+		categoryService         services.CategoryService              = services.NewCategoryService(categoryRepository)
+		categoryController      controller.CategoryController         = controller.NewCategoryController(categoryService)
+		eventService            services.EventService                 = services.NewEventService(eventRepository, categoryRepository)
+		kycRepository           repository.KYCRepository              = repository.NewKYCRepository(db)
+		kycService              services.KYCService                   = services.NewKYCService(kycRepository, jwtService)
+		kycController           controller.KYCController              = controller.NewKYCController(kycService)
+		policyService           services.PolicyService                = services.NewPolicyService(eventRepository)
+		storage                 services.Storage                      = services.NewStorage()
+		assetRepository         repository.AssetRepository            = repository.NewAssetRepository(db)
+		assetService            services.AssetService                 = services.NewAssetService(assetRepository, storage)
+		assetController         controller.AssetController            = controller.NewAssetController(assetService)
+		eventController         controller.EventController            = controller.NewEventController(eventService, transaksiService, userService, twoFactorService, policyService, kycService, categoryService, assetService, jwtService, db)
+		refreshTokenRepository  repository.RefreshTokenRepository     = repository.NewRefreshTokenRepository(db)
+		sessionRepository       repository.SessionRepository          = repository.NewSessionRepository(db)
+		sessionService          services.SessionService               = services.NewSessionService(sessionRepository)
+		sessionController       controller.SessionController          = controller.NewSessionController(sessionService)
+		refreshTokenService     services.RefreshTokenService          = services.NewRefreshTokenService(refreshTokenRepository, sessionRepository, userRepository, jwtService)
+		loginThrottleRepository repository.LoginThrottleRepository    = repository.NewLoginThrottleRepository(db)
+		loginGuardService       services.LoginGuardService            = services.NewLoginGuardService(loginThrottleRepository, userRepository)
+		oidcRepository          repository.OIDCRepository             = repository.NewOIDCRepository(db)
+		oidcService             services.OIDCService                  = services.NewOIDCService(oidcRepository, userRepository, jwtService)
+		handoverRepository      repository.CampaignHandoverRepository = repository.NewCampaignHandoverRepository(db)
+		accountDeletionService  services.AccountDeletionService       = services.NewAccountDeletionService(userRepository, eventRepository, handoverRepository, kycService, jwtService, mailer)
+		userController          controller.UserController             = controller.NewUserController(userService, transaksiService, pembayaranService, eventService, db, jwtService, refreshTokenService, loginGuardService, twoFactorService, oidcService, accountDeletionService, sessionService)
+		apiKeyRepository        repository.APIKeyRepository           = repository.NewAPIKeyRepository(db)
+		apiKeyService           services.APIKeyService                = services.NewAPIKeyService(apiKeyRepository)
+		apiKeyController        controller.APIKeyController           = controller.NewAPIKeyController(apiKeyService)
+		seederRepository        repository.SeederRepository           = repository.NewSeederRepository(db)
+		seederService           services.SeederService                = services.NewSeederService(seederRepository)
+		seederController        controller.SeederController           = controller.NewSeederController(seederService)
+		penarikanRepository     repository.PenarikanRepository        = repository.NewPenarikanRepository(db)
+		penarikanService        services.PenarikanService             = services.NewPenarikanService(penarikanRepository)
+		penarikanController     controller.PenarikanController        = controller.NewPenarikanController(userService, eventService, penarikanService, policyService, kycService, db, jwtService)
+		kabarTerbaruRepository  repository.KabarTerbaruRepository     = repository.NewKabarTerbaruRepository(db)
+		kabarTerbaruNotifier    services.KabarTerbaruNotifier         = services.NewEmailKabarTerbaruNotifier(transaksiRepository, mailer)
+		kabarTerbaruService     services.KabarTerbaruService          = services.NewKabarTerbaruService(kabarTerbaruRepository, eventRepository, penarikanRepository, kabarTerbaruNotifier)
+		kabarTerbaruController  controller.KabarTerbaruController     = controller.NewKabarTerbaruController(kabarTerbaruService, eventService, policyService)
+		komentarRepository      repository.KomentarRepository         = repository.NewKomentarRepository(db)
+		komentarService         services.KomentarService              = services.NewKomentarService(komentarRepository, eventRepository, transaksiRepository)
+		komentarController      controller.KomentarController         = controller.NewKomentarController(komentarService, eventService)
 	)
 
 	server := gin.Default()
+	// Header X-Forwarded-For hanya dipercaya dari proxy yang terdaftar, selain
+	// itu IP client diambil dari koneksi agar throttle login tidak bisa diakali
+	if err := server.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("error setting trusted proxies: %v", err)
+	}
 	server.Use(middleware.CORSMiddleware())
-	routes.Router(server, userController, eventController, transaksiController, seederController, penarikanController, jwtService)
+	routes.Router(server, userController, eventController, transaksiController, seederController, penarikanController, kycController, apiKeyController, sessionController, categoryController, kabarTerbaruController, komentarController, assetController, jwtService, sessionService, twoFactorService, apiKeyService)
 
 	if err := config.Seeder(db); err != nil {
 		log.Fatalf("error seeding database: %v", err)
 	}
 
+	scheduler := services.NewScheduler(repository.NewJobLockRepository(db))
+	scheduler.Register(services.NewRevokedTokenSweepJob(revokedTokenRepository, jwtService, time.Hour))
+	scheduler.Register(services.NewAccountDeletionJob(accountDeletionService, time.Hour))
+	scheduler.Register(services.NewEventExpiryJob(eventService, time.Minute))
+	scheduler.Register(services.NewAssetCleanupJob(assetService, time.Hour))
+	scheduler.Start(context.Background())
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8888"
 	}
 	server.Run(":" + port)
-}
\ No newline at end of file
+}
+
+// trustedProxies membaca daftar IP atau CIDR proxy dari TRUSTED_PROXIES yang
+// dipisahkan koma. Daftar kosong berarti tidak ada proxy yang dipercaya.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/services"
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthenticateAPIKeyOrJWT menerima API key lewat header X-API-Key. Jika header
+// tersebut tidak ada, request diautentikasi seperti biasa dengan JWT.
+func AuthenticateAPIKeyOrJWT(jwtService services.JWTService, sessionService services.SessionService, apiKeyService services.APIKeyService) gin.HandlerFunc {
+	authenticate := Authenticate(jwtService, sessionService)
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(services.APIKeyHeader)
+		if key == "" {
+			authenticate(ctx)
+			return
+		}
+
+		apiKey, err := apiKeyService.ValidateAPIKey(ctx.Request.Context(), key, ctx.ClientIP())
+		if err != nil {
+			response := utils.BuildResponseFailed("Gagal Memproses Request", services.ErrInvalidAPIKey.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+		ctx.Set("apiKey", apiKey)
+		ctx.Next()
+	}
+}
+
+// RequireScopeOrRole mengizinkan API key yang memiliki scope tersebut, atau
+// JWT dengan salah satu role yang disebutkan.
+func RequireScopeOrRole(scope string, roles ...string) gin.HandlerFunc {
+	requireRole := RequireRole(roles...)
+	return func(ctx *gin.Context) {
+		value, ok := ctx.Get("apiKey")
+		if !ok {
+			requireRole(ctx)
+			return
+		}
+
+		if apiKey, ok := value.(entities.APIKey); ok && apiKey.HasScope(scope) {
+			ctx.Next()
+			return
+		}
+		response := utils.BuildResponseFailed("Gagal Memproses Request", "Akses Ditolak", nil)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func Authenticate(jwtService services.JWTService) gin.HandlerFunc {
+// Authenticate juga memastikan session pemilik token belum dicabut, sehingga
+// logout dari device lain berlaku tanpa menunggu access token kadaluarsa.
+func Authenticate(jwtService services.JWTService, sessionService services.SessionService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
@@ -17,31 +19,56 @@ func Authenticate(jwtService services.JWTService) gin.HandlerFunc {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
 			return
 		}
-		if !strings.Contains(authHeader, "Bearer ") {
+		if !strings.HasPrefix(authHeader, "Bearer ") {
 			response := utils.BuildResponseFailed("Gagal Memproses Request", "Token Tidak Valid", nil)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
 			return
 		}
-		authHeader = strings.Replace(authHeader, "Bearer ", "", -1)
-		token, err := jwtService.ValidateToken(authHeader)
+		authHeader = strings.TrimPrefix(authHeader, "Bearer ")
+		claims, err := jwtService.ParseAccessToken(authHeader)
 		if err != nil {
 			response := utils.BuildResponseFailed("Gagal Memproses Request", "Token Tidak Valid", nil)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
 			return
 		}
-		if !token.Valid {
-			response := utils.BuildResponseFailed("Gagal Memproses Request", "Akses Ditolak", nil)
+		if err := sessionService.ValidateSession(ctx.Request.Context(), claims.UserID, claims.SessionID, ctx.ClientIP()); err != nil {
+			response := utils.BuildResponseFailed("Gagal Memproses Request", err.Error(), nil)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
 			return
 		}
-		userID, err := jwtService.GetUserIDByToken(authHeader)
+		setAuthContext(ctx, authHeader, claims)
+		ctx.Next()
+	}
+}
+
+// OptionalAuthenticate dipakai pada endpoint publik yang menampilkan data
+// berbeda untuk user yang login. Token yang tidak ada atau tidak valid
+// diperlakukan sebagai pengunjung anonim.
+func OptionalAuthenticate(jwtService services.JWTService, sessionService services.SessionService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authHeader := ctx.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			ctx.Next()
+			return
+		}
+		authHeader = strings.TrimPrefix(authHeader, "Bearer ")
+		claims, err := jwtService.ParseAccessToken(authHeader)
 		if err != nil {
-			response := utils.BuildResponseFailed("Gagal Memproses Request", err.Error(), nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			ctx.Next()
 			return
 		}
-		ctx.Set("token", authHeader)
-		ctx.Set("userID", userID)
+		if err := sessionService.ValidateSession(ctx.Request.Context(), claims.UserID, claims.SessionID, ctx.ClientIP()); err != nil {
+			ctx.Next()
+			return
+		}
+		setAuthContext(ctx, authHeader, claims)
 		ctx.Next()
 	}
-}
\ No newline at end of file
+}
+
+func setAuthContext(ctx *gin.Context, token string, claims services.AccessTokenClaims) {
+	ctx.Set("token", token)
+	ctx.Set("userID", claims.UserID)
+	ctx.Set("role", claims.Role)
+	ctx.Set("sessionID", claims.SessionID)
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole harus dipasang setelah Authenticate karena membaca role
+// yang sudah disimpan di context dari claim JWT.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		role := ctx.GetString("role")
+		for _, r := range roles {
+			if r == role {
+				ctx.Next()
+				return
+			}
+		}
+		response := utils.BuildResponseFailed("Gagal Memproses Request", "Akses Ditolak", nil)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+	}
+}
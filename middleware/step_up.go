@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/golang-clean_template/services"
+	"github.com/Caknoooo/golang-clean_template/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequireStepUp harus dipasang setelah Authenticate. User yang mengaktifkan
+// autentikasi dua langkah wajib mengirim step-up token lewat header
+// X-Step-Up-Token untuk aksi sensitif seperti penarikan dana.
+func RequireStepUp(twoFactorService services.TwoFactorService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID := ctx.MustGet("userID").(uuid.UUID)
+		sessionID := ctx.MustGet("sessionID").(uuid.UUID)
+		if err := twoFactorService.CheckStepUp(ctx.Request.Context(), userID, sessionID, ctx.GetHeader(services.StepUpHeader)); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, services.ErrStepUpRequired) {
+				status = http.StatusForbidden
+			}
+			response := utils.BuildResponseFailed("Gagal Memproses Request", err.Error(), nil)
+			ctx.AbortWithStatusJSON(status, response)
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, apiKey entities.APIKey) (entities.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (entities.APIKey, error)
+	GetAllAPIKey(ctx context.Context) ([]entities.APIKey, error)
+	RevokeAPIKey(ctx context.Context, apiKeyID uuid.UUID) (bool, error)
+	TouchAPIKey(ctx context.Context, apiKeyID uuid.UUID, ip string, now time.Time, interval time.Duration) error
+}
+
+type apiKeyRepository struct {
+	connection *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{
+		connection: db,
+	}
+}
+
+func (ar *apiKeyRepository) CreateAPIKey(ctx context.Context, apiKey entities.APIKey) (entities.APIKey, error) {
+	if err := ar.connection.Create(&apiKey).Error; err != nil {
+		return entities.APIKey{}, err
+	}
+	return apiKey, nil
+}
+
+func (ar *apiKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (entities.APIKey, error) {
+	var apiKey entities.APIKey
+	if err := ar.connection.Where("prefix = ?", prefix).Take(&apiKey).Error; err != nil {
+		return entities.APIKey{}, err
+	}
+	return apiKey, nil
+}
+
+func (ar *apiKeyRepository) GetAllAPIKey(ctx context.Context) ([]entities.APIKey, error) {
+	var apiKeys []entities.APIKey
+	if err := ar.connection.Order("created_at desc").Find(&apiKeys).Error; err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (ar *apiKeyRepository) RevokeAPIKey(ctx context.Context, apiKeyID uuid.UUID) (bool, error) {
+	result := ar.connection.Model(&entities.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", apiKeyID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// TouchAPIKey mencatat pemakaian terakhir paling sering sekali per interval
+// agar setiap request tidak selalu menulis ke database.
+func (ar *apiKeyRepository) TouchAPIKey(ctx context.Context, apiKeyID uuid.UUID, ip string, now time.Time, interval time.Duration) error {
+	return ar.connection.Model(&entities.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", apiKeyID, now.Add(-interval)).
+		Updates(map[string]any{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		}).Error
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AssetRepository interface {
+	CreateAsset(ctx context.Context, asset *entities.Asset, quota int64) (bool, error)
+	GetAssetByID(ctx context.Context, assetID uuid.UUID) (entities.Asset, error)
+	GetAssetByKey(ctx context.Context, key string) (entities.Asset, error)
+	GetAssetUsage(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetOrphanAssets(ctx context.Context, createdBefore time.Time, limit int) ([]entities.Asset, error)
+	DeleteOrphanAsset(ctx context.Context, assetID uuid.UUID) (bool, error)
+}
+
+type assetRepository struct {
+	connection *gorm.DB
+}
+
+func NewAssetRepository(db *gorm.DB) AssetRepository {
+	return &assetRepository{
+		connection: db,
+	}
+}
+
+// CreateAsset menyimpan asset hanya jika total ukuran asset milik user
+// setelah ditambah asset ini tidak melebihi quota. Baris user dikunci agar
+// upload yang berjalan bersamaan tidak bisa melewati quota.
+func (ar *assetRepository) CreateAsset(ctx context.Context, asset *entities.Asset, quota int64) (bool, error) {
+	created := false
+	err := ar.connection.Transaction(func(tx *gorm.DB) error {
+		var user entities.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", asset.UserID).
+			Take(&user).Error; err != nil {
+			return err
+		}
+
+		var usage int64
+		if err := tx.Model(&entities.Asset{}).
+			Select("COALESCE(SUM(size), 0)").
+			Where("user_id = ?", asset.UserID).
+			Scan(&usage).Error; err != nil {
+			return err
+		}
+		if usage+asset.Size > quota {
+			return nil
+		}
+
+		if err := tx.Create(asset).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (ar *assetRepository) GetAssetByID(ctx context.Context, assetID uuid.UUID) (entities.Asset, error) {
+	var asset entities.Asset
+	if err := ar.connection.Where("id = ?", assetID).Take(&asset).Error; err != nil {
+		return entities.Asset{}, err
+	}
+	return asset, nil
+}
+
+// GetAssetByKey mencari asset yang file utama atau thumbnail-nya memakai key.
+func (ar *assetRepository) GetAssetByKey(ctx context.Context, key string) (entities.Asset, error) {
+	var asset entities.Asset
+	if err := ar.connection.Where("key = ? OR thumbnail_key = ?", key, key).Take(&asset).Error; err != nil {
+		return entities.Asset{}, err
+	}
+	return asset, nil
+}
+
+func (ar *assetRepository) GetAssetUsage(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var usage int64
+	if err := ar.connection.Model(&entities.Asset{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where("user_id = ?", userID).
+		Scan(&usage).Error; err != nil {
+		return 0, err
+	}
+	return usage, nil
+}
+
+// orphanAssets memilih asset yang tidak dipakai sebagai foto event maupun
+// gambar kabar terbaru. Gambar kabar terbaru hanya menyimpan URL sehingga
+// dicocokkan dengan URL asset.
+func orphanAssets(db *gorm.DB) *gorm.DB {
+	return db.
+		Where("NOT EXISTS (SELECT 1 FROM events WHERE events.foto_asset_id = assets.id)").
+		Where("NOT EXISTS (SELECT 1 FROM kabar_terbaru_gambars WHERE kabar_terbaru_gambars.url IN (assets.url, assets.thumbnail_url))")
+}
+
+func (ar *assetRepository) GetOrphanAssets(ctx context.Context, createdBefore time.Time, limit int) ([]entities.Asset, error) {
+	var assets []entities.Asset
+	if err := ar.connection.Scopes(orphanAssets).
+		Where("created_at < ?", createdBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// DeleteOrphanAsset memeriksa ulang bahwa asset belum dipakai saat dihapus,
+// karena asset bisa saja dipasang ke event setelah daftar orphan diambil.
+func (ar *assetRepository) DeleteOrphanAsset(ctx context.Context, assetID uuid.UUID) (bool, error) {
+	result := ar.connection.Scopes(orphanAssets).
+		Where("id = ?", assetID).
+		Delete(&entities.Asset{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
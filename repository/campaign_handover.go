@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CampaignHandoverRepository interface {
+	SaveHandoverOffer(ctx context.Context, handover entities.CampaignHandover) error
+	GetHandoverByID(ctx context.Context, handoverID uuid.UUID) (entities.CampaignHandover, error)
+	GetHandoverByFromUserID(ctx context.Context, fromUserID uuid.UUID) (entities.CampaignHandover, error)
+	GetHandoversByToUserID(ctx context.Context, toUserID uuid.UUID) ([]entities.CampaignHandover, error)
+	AcceptHandover(ctx context.Context, handoverID uuid.UUID, toUserID uuid.UUID, now time.Time) (bool, error)
+	DeleteHandover(ctx context.Context, handoverID uuid.UUID, toUserID uuid.UUID) (bool, error)
+	DeleteHandoverByFromUserID(ctx context.Context, fromUserID uuid.UUID) error
+}
+
+type campaignHandoverRepository struct {
+	connection *gorm.DB
+}
+
+func NewCampaignHandoverRepository(db *gorm.DB) CampaignHandoverRepository {
+	return &campaignHandoverRepository{
+		connection: db,
+	}
+}
+
+// SaveHandoverOffer mengganti tawaran sebelumnya milik user yang sama.
+// Persetujuan penerima lama tidak berlaku untuk penerima baru.
+func (hr *campaignHandoverRepository) SaveHandoverOffer(ctx context.Context, handover entities.CampaignHandover) error {
+	return hr.connection.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "from_user_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"to_user_id":  handover.ToUserID,
+			"accepted_at": nil,
+			"created_at":  handover.CreatedAt,
+			"updated_at":  handover.UpdatedAt,
+		}),
+	}).Create(&handover).Error
+}
+
+func (hr *campaignHandoverRepository) GetHandoverByID(ctx context.Context, handoverID uuid.UUID) (entities.CampaignHandover, error) {
+	var handover entities.CampaignHandover
+	if err := hr.connection.Preload("FromUser").Where("id = ?", handoverID).Take(&handover).Error; err != nil {
+		return entities.CampaignHandover{}, err
+	}
+	return handover, nil
+}
+
+func (hr *campaignHandoverRepository) GetHandoverByFromUserID(ctx context.Context, fromUserID uuid.UUID) (entities.CampaignHandover, error) {
+	var handover entities.CampaignHandover
+	if err := hr.connection.Where("from_user_id = ?", fromUserID).Take(&handover).Error; err != nil {
+		return entities.CampaignHandover{}, err
+	}
+	return handover, nil
+}
+
+func (hr *campaignHandoverRepository) GetHandoversByToUserID(ctx context.Context, toUserID uuid.UUID) ([]entities.CampaignHandover, error) {
+	var handovers []entities.CampaignHandover
+	if err := hr.connection.Preload("FromUser").Where("to_user_id = ?", toUserID).Order("created_at DESC").Find(&handovers).Error; err != nil {
+		return nil, err
+	}
+	return handovers, nil
+}
+
+func (hr *campaignHandoverRepository) AcceptHandover(ctx context.Context, handoverID uuid.UUID, toUserID uuid.UUID, now time.Time) (bool, error) {
+	result := hr.connection.Model(&entities.CampaignHandover{}).
+		Where("id = ? AND to_user_id = ? AND accepted_at IS NULL", handoverID, toUserID).
+		UpdateColumns(map[string]any{"accepted_at": now, "updated_at": now})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (hr *campaignHandoverRepository) DeleteHandover(ctx context.Context, handoverID uuid.UUID, toUserID uuid.UUID) (bool, error) {
+	result := hr.connection.Where("id = ? AND to_user_id = ?", handoverID, toUserID).Delete(&entities.CampaignHandover{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (hr *campaignHandoverRepository) DeleteHandoverByFromUserID(ctx context.Context, fromUserID uuid.UUID) error {
+	return hr.connection.Where("from_user_id = ?", fromUserID).Delete(&entities.CampaignHandover{}).Error
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	CreateCategory(ctx context.Context, category entities.CategoryEvent) (entities.CategoryEvent, error)
+	GetCategories(ctx context.Context, activeOnly bool) ([]entities.CategoryEvent, error)
+	GetCategoryByID(ctx context.Context, categoryID uint) (entities.CategoryEvent, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (entities.CategoryEvent, error)
+	UpdateCategory(ctx context.Context, category entities.CategoryEvent) error
+	DeleteCategory(ctx context.Context, categoryID uint) error
+	IsCategoryInUse(ctx context.Context, categoryID uint) (bool, error)
+	GetCategorySubtreeIDs(ctx context.Context, categoryID uint) ([]uint, error)
+}
+
+type categoryRepository struct {
+	connection *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{
+		connection: db,
+	}
+}
+
+func (cr *categoryRepository) CreateCategory(ctx context.Context, category entities.CategoryEvent) (entities.CategoryEvent, error) {
+	if err := cr.connection.Create(&category).Error; err != nil {
+		return entities.CategoryEvent{}, err
+	}
+	return category, nil
+}
+
+func (cr *categoryRepository) GetCategories(ctx context.Context, activeOnly bool) ([]entities.CategoryEvent, error) {
+	var categories []entities.CategoryEvent
+	query := cr.connection.Order("urutan, nama")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (cr *categoryRepository) GetCategoryByID(ctx context.Context, categoryID uint) (entities.CategoryEvent, error) {
+	var category entities.CategoryEvent
+	if err := cr.connection.Where("id = ?", categoryID).Take(&category).Error; err != nil {
+		return entities.CategoryEvent{}, err
+	}
+	return category, nil
+}
+
+func (cr *categoryRepository) GetCategoryBySlug(ctx context.Context, slug string) (entities.CategoryEvent, error) {
+	var category entities.CategoryEvent
+	if err := cr.connection.Where("slug = ?", slug).Take(&category).Error; err != nil {
+		return entities.CategoryEvent{}, err
+	}
+	return category, nil
+}
+
+// UpdateCategory menulis semua kolom yang bisa diubah termasuk nilai kosong,
+// misalnya parent_id yang dikosongkan.
+func (cr *categoryRepository) UpdateCategory(ctx context.Context, category entities.CategoryEvent) error {
+	return cr.connection.Model(&category).
+		Select("nama", "slug", "icon", "deskripsi", "urutan", "is_active", "parent_id").
+		Updates(&category).Error
+}
+
+func (cr *categoryRepository) DeleteCategory(ctx context.Context, categoryID uint) error {
+	return cr.connection.Delete(&entities.CategoryEvent{}, categoryID).Error
+}
+
+// IsCategoryInUse bernilai true jika kategori masih dipakai event atau masih
+// memiliki sub kategori.
+func (cr *categoryRepository) IsCategoryInUse(ctx context.Context, categoryID uint) (bool, error) {
+	var inUse bool
+	if err := cr.connection.Raw(
+		"SELECT EXISTS (SELECT 1 FROM events WHERE category_id = ?) OR EXISTS (SELECT 1 FROM category_events WHERE parent_id = ?)",
+		categoryID, categoryID,
+	).Scan(&inUse).Error; err != nil {
+		return false, err
+	}
+	return inUse, nil
+}
+
+// GetCategorySubtreeIDs mengembalikan id kategori beserta seluruh turunannya.
+func (cr *categoryRepository) GetCategorySubtreeIDs(ctx context.Context, categoryID uint) ([]uint, error) {
+	var ids []uint
+	if err := cr.connection.Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM category_events WHERE id = ?
+			UNION
+			SELECT c.id FROM category_events c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT id FROM subtree`, categoryID).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmailChangeRepository interface {
+	CreateEmailChangeToken(ctx context.Context, changeToken entities.EmailChangeToken) (entities.EmailChangeToken, error)
+	GetEmailChangeTokenByHash(ctx context.Context, tokenHash string) (entities.EmailChangeToken, error)
+	UseEmailChangeToken(ctx context.Context, changeTokenID uuid.UUID) (bool, error)
+	InvalidateEmailChangeTokenByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type emailChangeRepository struct {
+	connection *gorm.DB
+}
+
+func NewEmailChangeRepository(db *gorm.DB) EmailChangeRepository {
+	return &emailChangeRepository{
+		connection: db,
+	}
+}
+
+func (er *emailChangeRepository) CreateEmailChangeToken(ctx context.Context, changeToken entities.EmailChangeToken) (entities.EmailChangeToken, error) {
+	if err := er.connection.Create(&changeToken).Error; err != nil {
+		return entities.EmailChangeToken{}, err
+	}
+	return changeToken, nil
+}
+
+func (er *emailChangeRepository) GetEmailChangeTokenByHash(ctx context.Context, tokenHash string) (entities.EmailChangeToken, error) {
+	var changeToken entities.EmailChangeToken
+	if err := er.connection.Where("token_hash = ?", tokenHash).Take(&changeToken).Error; err != nil {
+		return entities.EmailChangeToken{}, err
+	}
+	return changeToken, nil
+}
+
+// UseEmailChangeToken menandai token sudah dipakai, hanya berhasil sekali
+// walaupun link konfirmasi dibuka beberapa kali secara bersamaan.
+func (er *emailChangeRepository) UseEmailChangeToken(ctx context.Context, changeTokenID uuid.UUID) (bool, error) {
+	result := er.connection.Model(&entities.EmailChangeToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", changeTokenID, time.Now()).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (er *emailChangeRepository) InvalidateEmailChangeTokenByUserID(ctx context.Context, userID uuid.UUID) error {
+	if err := er.connection.Model(&entities.EmailChangeToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return nil
+}
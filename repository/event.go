@@ -5,23 +5,30 @@ import (
 	"errors"
 	"fmt"
 	"time"
-	"math"
 
 	"github.com/Caknoooo/golang-clean_template/entities"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type EventRepository interface {
 	CreateEvent(ctx context.Context, event entities.Event) (entities.Event, error)
-	GetAllEvent(ctx context.Context) ([]entities.Event, error)
-	GetAllEventByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Event, error)
+	GetEvents(ctx context.Context, query entities.EventQuery) ([]entities.Event, int64, error)
+	SearchEvents(ctx context.Context, query entities.EventQuery) ([]entities.EventSearchResult, int64, error)
+	GetAllEventByUserID(ctx context.Context, userID uuid.UUID, statuses []string) ([]entities.Event, error)
 	GetEventByID(ctx context.Context, eventID uuid.UUID) (entities.Event, error)
+	GetEventOwnerID(ctx context.Context, eventID uuid.UUID) (uuid.UUID, error)
+	GetActiveEventsByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Event, error)
+	TransferEvents(ctx context.Context, fromUserID uuid.UUID, eventIDs []uuid.UUID, owner entities.Event) error
+	CancelEventsByUserID(ctx context.Context, userID uuid.UUID, statuses []string, history entities.EventStatusHistory) error
 	LikeEventByEventID(ctx context.Context, userID uuid.UUID, eventID uuid.UUID) error
-	UpdateEvent(ctx context.Context, event entities.Event, eventID uuid.UUID) error
-	PatchEvent(ctx context.Context, event entities.Event, eventID uuid.UUID) error
-	DeleteEvent(ctx context.Context, eventID uuid.UUID) error
-	Get3Event(ctx context.Context, q uint) ([]entities.Event, error)
+	UpdateEvent(ctx context.Context, event entities.Event, eventID uuid.UUID) (bool, error)
+	RecordDonation(ctx context.Context, transaksi entities.Transaksi, now time.Time) (entities.Transaksi, entities.Event, bool, error)
+	UpdateEventStatus(ctx context.Context, eventID uuid.UUID, from string, requireNoDonation bool, history entities.EventStatusHistory) (bool, error)
+	GetEventStatusHistory(ctx context.Context, eventID uuid.UUID) ([]entities.EventStatusHistory, error)
+	GetEventsDueForExpiry(ctx context.Context, now time.Time, limit int) ([]entities.Event, error)
+	DeleteEvent(ctx context.Context, eventID uuid.UUID) (bool, error)
 	GetEventForService(ctx context.Context) ([]entities.Event, error)
 }
 
@@ -38,82 +45,179 @@ func NewEventRepository(db *gorm.DB) EventRepository {
 func (er *eventRepository) CreateEvent(ctx context.Context, event entities.Event) (entities.Event, error) {
 	event.SisaDonasi = event.JumlahDonasi
 	if err := er.connection.Create(&event).Error; err != nil {
-		return entities.Event{}, nil
+		return entities.Event{}, err
+	}
+	if err := er.refreshSearchVector(event.ID); err != nil {
+		return entities.Event{}, err
 	}
 
 	// if err := er.connection.Preload("Event")
 	return event, nil
 }
 
-func (er *eventRepository) GetAllEvent(ctx context.Context) ([]entities.Event, error) {
-	var events []entities.Event
-	if err := er.connection.Preload("User").Preload("Likes").Find(&events).Error; err != nil {
-		return nil, err
-	}
-	return events, nil
+// eventSortColumns memetakan pilihan urutan ke kolom dan arahnya. id selalu
+// dipakai sebagai pengurut kedua agar cursor stabil untuk nilai yang sama.
+var eventSortColumns = map[string]struct {
+	column string
+	desc   bool
+}{
+	entities.EventSortNewest:     {"created_at", true},
+	entities.EventSortMostLiked:  {"like_count", true},
+	entities.EventSortEndingSoon: {"expired_donasi", false},
+	entities.EventSortMostFunded: {"jumlah_donasi", true},
 }
 
-func (er *eventRepository) GetAllEventByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Event, error) {
-	var events []entities.Event
-	if err := er.connection.Preload("User").Preload("Likes").Where("user_id = ?", userID).Find(&events).Error; err != nil {
-		return nil, err
+func applyEventFilter(db *gorm.DB, query entities.EventQuery) *gorm.DB {
+	if query.CategoryIDs != nil {
+		db = db.Where("category_id IN ?", query.CategoryIDs)
 	}
-	return events, nil
+	if query.Statuses != nil {
+		db = db.Where("status IN ?", query.Statuses)
+	}
+	if query.UserID != nil {
+		db = db.Where("user_id = ?", *query.UserID)
+	}
+	return db
 }
 
-func (er *eventRepository) GetEventByID(ctx context.Context, eventID uuid.UUID) (entities.Event, error) {
-	var updateEvent entities.Event
-	if err := er.connection.First(&updateEvent, eventID).Error; err != nil {
-		return entities.Event{}, err
+// GetEvents mengembalikan satu halaman event beserta jumlah seluruh event yang
+// cocok dengan filter.
+func (er *eventRepository) GetEvents(ctx context.Context, query entities.EventQuery) ([]entities.Event, int64, error) {
+	sort, ok := eventSortColumns[query.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown event sort %q", query.Sort)
 	}
 
-	if !updateEvent.ExpiredDonasi.IsZero() && updateEvent.ExpiredDonasi.Before(time.Now()) {
-		updateEvent.Is_expired = true
-		if err := er.connection.Save(&updateEvent).Error; err != nil {
-			return entities.Event{}, err
-		}
-	}
+	filtered := applyEventFilter(er.connection.Model(&entities.Event{}), query)
 
-	timeLeft := TimeLeft(updateEvent.ExpiredDonasi)
-	updateEvent.SisaHariDonasi = timeLeft
-	
-	if updateEvent.JumlahDonasi >= updateEvent.MaxDonasi {
-		updateEvent.Is_target_full = true
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	fmt.Println(updateEvent.ExpiredDonasi)
+	direction, operator := "ASC", ">"
+	if sort.desc {
+		direction, operator = "DESC", "<"
+	}
 
-	expiredDonasiStr  := updateEvent.ExpiredDonasi.Format(time.RFC3339)
-	expiredDonasiLocal, _ := time.ParseInLocation(time.RFC3339, expiredDonasiStr, time.Local)
+	page := filtered.Session(&gorm.Session{})
+	if query.Cursor != nil {
+		page = page.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sort.column, operator), query.Cursor.Value, query.Cursor.ID)
+	} else if query.Offset > 0 {
+		page = page.Offset(query.Offset)
+	}
 
-	updateEvent.ExpiredDonasi = expiredDonasiLocal
+	var events []entities.Event
+	if err := page.Preload("User").Preload("Likes").Preload("Category").
+		Order(fmt.Sprintf("%s %s, id %s", sort.column, direction, direction)).
+		Limit(query.Limit).
+		Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
 
-	if err := er.connection.Save(&updateEvent).Error; err != nil {
-		return entities.Event{}, err
+// GetAllEventByUserID mengembalikan event milik user. statuses bernilai nil
+// berarti semua status ikut dikembalikan.
+func (er *eventRepository) GetAllEventByUserID(ctx context.Context, userID uuid.UUID, statuses []string) ([]entities.Event, error) {
+	var events []entities.Event
+	query := er.connection.Preload("User").Preload("Likes").Preload("Category").Where("user_id = ?", userID)
+	if statuses != nil {
+		query = query.Where("status IN ?", statuses)
 	}
+	if err := query.Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
 
+func (er *eventRepository) GetEventByID(ctx context.Context, eventID uuid.UUID) (entities.Event, error) {
 	var event entities.Event
-	if err := er.connection.Preload("User").Preload("Likes").Where("id = ?", eventID).Take(&event).Error; err != nil {
+	query := er.connection.Preload("User").Preload("Likes").Preload("Category").
+		Preload("KabarTerbaru", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at DESC, id")
+		})
+	if err := preloadKabarTerbaru(query, "KabarTerbaru.").Where("id = ?", eventID).Take(&event).Error; err != nil {
 		return entities.Event{}, err
 	}
-	fmt.Println(event.ExpiredDonasi)
 	return event, nil
 }
 
-func TimeLeft(expiredTime time.Time) string {
-	timeLeft := expiredTime.Sub(time.Now())
-
-	fmt.Print(timeLeft.Hours() / 24)
-	if timeLeft.Hours()/24 <= 0 {
-		return "Waktu Habis"
+func (er *eventRepository) GetEventOwnerID(ctx context.Context, eventID uuid.UUID) (uuid.UUID, error) {
+	var event entities.Event
+	if err := er.connection.Select("id", "user_id").Take(&event, "id = ?", eventID).Error; err != nil {
+		return uuid.Nil, err
 	}
+	return event.UserID, nil
+}
 
-	if timeLeft.Hours()/24 < 1 {
-		return "<1 Hari"
+// activeEvents memfilter event yang masih menerima donasi atau masih
+// menyimpan dana yang belum ditarik.
+func activeEvents(db *gorm.DB) *gorm.DB {
+	return db.Where("events.status = ? OR (events.status IN ? AND events.sisa_donasi > 0)", entities.EventStatusActive, entities.EventWithdrawableStatuses)
+}
+
+func (er *eventRepository) GetActiveEventsByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Event, error) {
+	var events []entities.Event
+	if err := er.connection.
+		Where("user_id = ?", userID).
+		Scopes(activeEvents).
+		Find(&events).Error; err != nil {
+		return nil, err
 	}
+	return events, nil
+}
 
-	dayLeft := int(math.Round(timeLeft.Hours() / 24))
-	return fmt.Sprintf("%v Hari", dayLeft)
+// TransferEvents memindahkan event ke pemilik baru beserta identitas pembuat
+// yang diambil dari owner.
+func (er *eventRepository) TransferEvents(ctx context.Context, fromUserID uuid.UUID, eventIDs []uuid.UUID, owner entities.Event) error {
+	return er.connection.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.Event{}).
+			Where("id IN ? AND user_id = ?", eventIDs, fromUserID).
+			Select("user_id", "nama_depan_pembuat", "nama_belakang_pembuat", "nomor_ktp", "nomor_telepon_pembuat", "pekerjaan", "asal_instansi").
+			Updates(&owner)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(eventIDs)) {
+			return errors.New("event sudah berubah pemilik")
+		}
+		return nil
+	})
+}
+
+// CancelEventsByUserID membatalkan event milik user yang berstatus salah satu
+// dari statuses dan mencatat riwayat status untuk setiap event.
+func (er *eventRepository) CancelEventsByUserID(ctx context.Context, userID uuid.UUID, statuses []string, history entities.EventStatusHistory) error {
+	return er.connection.Transaction(func(tx *gorm.DB) error {
+		var events []entities.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "status").
+			Where("user_id = ? AND status IN ?", userID, statuses).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		eventIDs := make([]uuid.UUID, 0, len(events))
+		histories := make([]entities.EventStatusHistory, 0, len(events))
+		for _, event := range events {
+			eventIDs = append(eventIDs, event.ID)
+			entry := history
+			entry.EventID = event.ID
+			entry.FromStatus = event.Status
+			histories = append(histories, entry)
+		}
+
+		if err := tx.Model(&entities.Event{}).
+			Where("id IN ?", eventIDs).
+			UpdateColumns(map[string]any{"status": history.ToStatus, "updated_at": history.CreatedAt}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&histories).Error
+	})
 }
 
 func (er *eventRepository) LikeEventByEventID(ctx context.Context, userID uuid.UUID, eventID uuid.UUID) error {
@@ -137,78 +241,139 @@ func (er *eventRepository) LikeEventByEventID(ctx context.Context, userID uuid.U
 		return err
 	}
 
-	var event entities.Event
-	if err := er.connection.Where("id = ?", eventID).Find(&event).Error; err != nil {
+	if err := er.connection.Model(&entities.Event{}).Where("id = ?", eventID).
+		UpdateColumn("like_count", gorm.Expr("like_count + 1")).Error; err != nil {
 		return err
 	}
 
-	event.LikeCount++
-	er.UpdateEvent(ctx, event, like.EventID)
-
 	return nil
 }
 
-func (er *eventRepository) UpdateEvent(ctx context.Context, event entities.Event, eventID uuid.UUID) error {
-	if err := er.connection.Where("id = ?", eventID).Updates(&event).Error; err != nil {
-		return err
+// UpdateEvent hanya mengubah event yang masih draft, agar isi event yang
+// sudah direview tidak bisa diganti diam-diam. updated bernilai false jika
+// event tidak ditemukan atau statusnya bukan draft.
+func (er *eventRepository) UpdateEvent(ctx context.Context, event entities.Event, eventID uuid.UUID) (bool, error) {
+	result := er.connection.Where("id = ? AND status = ?", eventID, entities.EventStatusDraft).Updates(&event)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	if event.JudulEvent != "" || event.DeskripsiEvent != "" {
+		if err := er.refreshSearchVector(eventID); err != nil {
+			return false, err
+		}
 	}
 
-	return nil
+	return true, nil
 }
 
-func (er *eventRepository) PatchEvent(ctx context.Context, event entities.Event, eventID uuid.UUID) error {
-	if err := er.connection.Where("id = ?", eventID).Updates(&event).Error; err != nil {
-		return err
+// RecordDonation menyimpan transaksi dan menambah total donasi dalam satu
+// transaksi database. Donasi hanya diterima selama event berstatus active dan
+// belum melewati batas waktu; accepted bernilai false jika syarat itu tidak
+// terpenuhi.
+func (er *eventRepository) RecordDonation(ctx context.Context, transaksi entities.Transaksi, now time.Time) (entities.Transaksi, entities.Event, bool, error) {
+	if transaksi.Jumlah_Donasi_Event <= 0 {
+		return entities.Transaksi{}, entities.Event{}, false, errors.New("Jumlah Donasi Harus Lebih Dari 0")
 	}
+	var event entities.Event
+	accepted := false
+	err := er.connection.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&event).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND status = ? AND expired_donasi > ?", transaksi.EventID, entities.EventStatusActive, now).
+			UpdateColumns(map[string]any{
+				"jumlah_donasi": gorm.Expr("jumlah_donasi + ?", transaksi.Jumlah_Donasi_Event),
+				"sisa_donasi":   gorm.Expr("sisa_donasi + ?", transaksi.Jumlah_Donasi_Event),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
 
-	// Ambil event yang telah diperbarui dari database
-	var updatedEvent entities.Event
-	if err := er.connection.First(&updatedEvent, eventID).Error; err != nil {
-		return err
-	}
-
-	fmt.Printf("%+v\n", updatedEvent)
-
-	// Cek apakah event telah kadaluwarsa
-	if !updatedEvent.ExpiredDonasi.IsZero() && updatedEvent.ExpiredDonasi.Before(time.Now()) {
-		updatedEvent.Is_expired = true
-		if err := er.connection.Save(&updatedEvent).Error; err != nil {
+		if err := tx.Create(&transaksi).Error; err != nil {
 			return err
 		}
-		return errors.New("Event Has Expired")
-	}
-
-	if updatedEvent.JumlahDonasi >= updatedEvent.MaxDonasi {
-		updatedEvent.Is_target_full = true
+		accepted = true
+		return tx.Preload("Pembayaran").Preload("User").First(&transaksi, "id = ?", transaksi.ID).Error
+	})
+	if err != nil {
+		return entities.Transaksi{}, entities.Event{}, false, err
 	}
+	return transaksi, event, accepted, nil
+}
 
-	// Tambahkan IsDone dan update lagi
-	updatedEvent.IsDone++
-	if err := er.connection.Save(&updatedEvent).Error; err != nil {
-		return err
-	}
+// UpdateEventStatus mengubah status hanya jika status saat ini masih from,
+// lalu mencatat riwayatnya. Jika requireNoDonation bernilai true, event juga
+// harus belum menerima donasi. updated bernilai false jika event sudah diubah
+// oleh request lain.
+func (er *eventRepository) UpdateEventStatus(ctx context.Context, eventID uuid.UUID, from string, requireNoDonation bool, history entities.EventStatusHistory) (bool, error) {
+	updated := false
+	err := er.connection.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&entities.Event{}).Where("id = ? AND status = ?", eventID, from)
+		if requireNoDonation {
+			query = query.Where("jumlah_donasi = 0")
+		}
+		result := query.
+			UpdateColumns(map[string]any{"status": history.ToStatus, "updated_at": history.CreatedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
 
-	return nil
+		history.EventID = eventID
+		history.FromStatus = from
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+		updated = true
+		return nil
+	})
+	return updated, err
 }
 
-func (er *eventRepository) DeleteEvent(ctx context.Context, eventID uuid.UUID) error {
-	if err := er.connection.Delete(&entities.Event{}, &eventID).Error; err != nil {
-		return err
+func (er *eventRepository) GetEventStatusHistory(ctx context.Context, eventID uuid.UUID) ([]entities.EventStatusHistory, error) {
+	var histories []entities.EventStatusHistory
+	if err := er.connection.Where("event_id = ?", eventID).Order("created_at, id").Find(&histories).Error; err != nil {
+		return nil, err
 	}
-	return nil
+	return histories, nil
 }
 
-func (er *eventRepository) Get3Event(ctx context.Context, q uint) ([]entities.Event, error) {
+// GetEventsDueForExpiry mengembalikan event active yang batas waktunya sudah lewat.
+func (er *eventRepository) GetEventsDueForExpiry(ctx context.Context, now time.Time, limit int) ([]entities.Event, error) {
 	var events []entities.Event
-	if err := er.connection.Preload("User").Preload("Likes").Limit(int(q)).Find(&events).Error; err != nil {
+	if err := er.connection.
+		Where("status = ? AND expired_donasi <= ?", entities.EventStatusActive, now).
+		Order("expired_donasi").
+		Limit(limit).
+		Find(&events).Error; err != nil {
 		return nil, err
 	}
 	return events, nil
 }
 
+// DeleteEvent hanya menghapus event yang belum pernah tayang dan belum
+// menerima donasi. deleted bernilai false jika syarat itu tidak terpenuhi,
+// event lain harus ditutup lewat status cancelled.
+func (er *eventRepository) DeleteEvent(ctx context.Context, eventID uuid.UUID) (bool, error) {
+	result := er.connection.
+		Where("id = ? AND status IN ? AND jumlah_donasi = 0", eventID, entities.EventDeletableStatuses).
+		Delete(&entities.Event{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 func (er *eventRepository) GetEventForService(ctx context.Context) ([]entities.Event, error) {
 	var events []entities.Event
-	if err := er.connection.Preload("User").Preload("Likes").Order("created_at desc").Limit(6).Find(&events).Error; err != nil {
+	if err := er.connection.Preload("User").Preload("Likes").Where("status IN ?", entities.EventPublicStatuses).Order("created_at desc").Limit(6).Find(&events).Error; err != nil {
 		return nil, err
 	}
 	return events, nil
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// eventSearchConfig adalah text search configuration yang dibuat saat migrasi.
+// Isinya salinan konfigurasi indonesian (stemmer Snowball) jika tersedia di
+// server PostgreSQL, atau simple jika tidak.
+const eventSearchConfig = "event_search"
+
+// eventSearchVectorSQL memberi bobot lebih tinggi pada judul dibanding deskripsi.
+const eventSearchVectorSQL = "setweight(to_tsvector('" + eventSearchConfig + "', coalesce(judul_event, '')), 'A') || " +
+	"setweight(to_tsvector('" + eventSearchConfig + "', coalesce(deskripsi_event, '')), 'B')"
+
+// Penanda highlight memakai karakter private use agar hasil ts_headline bisa
+// di-escape dulu sebelum diberi tag HTML.
+const (
+	EventSearchHighlightStart = "\ue000"
+	EventSearchHighlightStop  = "\ue001"
+)
+
+// MigrateEventSearch menyiapkan kolom search_vector beserta GIN index-nya dan
+// mengisi event lama yang belum memiliki vector.
+func MigrateEventSearch(db *gorm.DB) error {
+	var configExists bool
+	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = ?)", eventSearchConfig).Scan(&configExists).Error; err != nil {
+		return err
+	}
+	if !configExists {
+		var hasIndonesian bool
+		if err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'indonesian')").Scan(&hasIndonesian).Error; err != nil {
+			return err
+		}
+		base := "simple"
+		if hasIndonesian {
+			base = "indonesian"
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE TEXT SEARCH CONFIGURATION %s (COPY = %s)", eventSearchConfig, base)).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := db.Exec("ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector").Error; err != nil {
+		return err
+	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING GIN (search_vector)").Error; err != nil {
+		return err
+	}
+	return db.Exec("UPDATE events SET search_vector = " + eventSearchVectorSQL + " WHERE search_vector IS NULL").Error
+}
+
+// refreshSearchVector dipanggil setiap kali judul atau deskripsi event ditulis.
+func (er *eventRepository) refreshSearchVector(eventID uuid.UUID) error {
+	return er.connection.Exec("UPDATE events SET search_vector = "+eventSearchVectorSQL+" WHERE id = ?", eventID).Error
+}
+
+// SearchEvents mencari event berdasarkan judul dan deskripsi. Query ditulis
+// dengan sintaks websearch, misalnya "banjir -jakarta" atau "\"bantuan sekolah\"".
+func (er *eventRepository) SearchEvents(ctx context.Context, query entities.EventQuery) ([]entities.EventSearchResult, int64, error) {
+	tsQuery := fmt.Sprintf("websearch_to_tsquery('%s', ?)", eventSearchConfig)
+	filtered := applyEventFilter(er.connection.Model(&entities.Event{}), query).
+		Where("search_vector @@ "+tsQuery, query.Search)
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	options := fmt.Sprintf(`StartSel="%s", StopSel="%s"`, EventSearchHighlightStart, EventSearchHighlightStop)
+	var results []entities.EventSearchResult
+	if err := filtered.Session(&gorm.Session{}).
+		Select(
+			fmt.Sprintf("events.*, ts_rank(search_vector, %[1]s) AS search_rank, "+
+				"ts_headline('%[2]s', coalesce(judul_event, ''), %[1]s, ?) AS headline_judul, "+
+				"ts_headline('%[2]s', coalesce(deskripsi_event, ''), %[1]s, ?) AS headline_deskripsi", tsQuery, eventSearchConfig),
+			query.Search, query.Search, options+", HighlightAll=true", query.Search, options+", MaxFragments=2, MaxWords=30, MinWords=10",
+		).
+		Order("search_rank DESC, id ASC").
+		Offset(query.Offset).
+		Limit(query.Limit).
+		Scan(&results).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := er.attachSearchCategories(results); err != nil {
+		return nil, 0, err
+	}
+	return results, total, nil
+}
+
+// attachSearchCategories mengisi kategori hasil pencarian dengan satu query,
+// karena Scan tidak menjalankan Preload.
+func (er *eventRepository) attachSearchCategories(results []entities.EventSearchResult) error {
+	ids := make([]uint, 0, len(results))
+	for _, result := range results {
+		if result.CategoryID != nil {
+			ids = append(ids, *result.CategoryID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var categories []entities.CategoryEvent
+	if err := er.connection.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]*entities.CategoryEvent, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+	for i := range results {
+		if results[i].CategoryID != nil {
+			results[i].Category = byID[*results[i].CategoryID]
+		}
+	}
+	return nil
+}
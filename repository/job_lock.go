@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type JobLockRepository interface {
+	RunWithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
+}
+
+type jobLockRepository struct {
+	connection *gorm.DB
+}
+
+func NewJobLockRepository(db *gorm.DB) JobLockRepository {
+	return &jobLockRepository{
+		connection: db,
+	}
+}
+
+// RunWithLock menjalankan fn hanya jika advisory lock untuk name berhasil
+// diambil, sehingga sebuah job tidak berjalan bersamaan di beberapa instance.
+// Lock dilepas otomatis saat transaksi selesai, termasuk jika koneksi putus.
+func (jr *jobLockRepository) RunWithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	tx := jr.connection.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", "job:"+name).Scan(&locked).Error; err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	return true, fn(ctx)
+}
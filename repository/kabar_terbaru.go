@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type KabarTerbaruRepository interface {
+	CreateKabarTerbaru(ctx context.Context, kabar entities.KabarTerbaru) (entities.KabarTerbaru, error)
+	GetKabarTerbaruByEventID(ctx context.Context, eventID uuid.UUID) ([]entities.KabarTerbaru, error)
+	GetKabarTerbaruByID(ctx context.Context, kabarID uuid.UUID) (entities.KabarTerbaru, error)
+	UpdateKabarTerbaru(ctx context.Context, kabar entities.KabarTerbaru, replaceGambar bool) (entities.KabarTerbaru, error)
+	DeleteKabarTerbaru(ctx context.Context, kabarID uuid.UUID) error
+}
+
+type kabarTerbaruRepository struct {
+	connection *gorm.DB
+}
+
+func NewKabarTerbaruRepository(db *gorm.DB) KabarTerbaruRepository {
+	return &kabarTerbaruRepository{
+		connection: db,
+	}
+}
+
+// preloadKabarTerbaru memuat gambar sesuai urutan dan penarikan yang ditautkan.
+func preloadKabarTerbaru(db *gorm.DB, prefix string) *gorm.DB {
+	return db.
+		Preload(prefix+"Gambar", func(db *gorm.DB) *gorm.DB {
+			return db.Order("urutan, id")
+		}).
+		Preload(prefix + "HistoryPenarikan")
+}
+
+func (kr *kabarTerbaruRepository) CreateKabarTerbaru(ctx context.Context, kabar entities.KabarTerbaru) (entities.KabarTerbaru, error) {
+	if err := kr.connection.Create(&kabar).Error; err != nil {
+		return entities.KabarTerbaru{}, err
+	}
+	return kr.GetKabarTerbaruByID(ctx, kabar.ID)
+}
+
+func (kr *kabarTerbaruRepository) GetKabarTerbaruByEventID(ctx context.Context, eventID uuid.UUID) ([]entities.KabarTerbaru, error) {
+	var kabar []entities.KabarTerbaru
+	if err := preloadKabarTerbaru(kr.connection, "").
+		Where("event_id = ?", eventID).
+		Order("created_at DESC, id").
+		Find(&kabar).Error; err != nil {
+		return nil, err
+	}
+	return kabar, nil
+}
+
+func (kr *kabarTerbaruRepository) GetKabarTerbaruByID(ctx context.Context, kabarID uuid.UUID) (entities.KabarTerbaru, error) {
+	var kabar entities.KabarTerbaru
+	if err := preloadKabarTerbaru(kr.connection, "").Where("id = ?", kabarID).Take(&kabar).Error; err != nil {
+		return entities.KabarTerbaru{}, err
+	}
+	return kabar, nil
+}
+
+// UpdateKabarTerbaru menyimpan perubahan kabar. Jika replaceGambar bernilai
+// true, seluruh gambar lama diganti dengan kabar.Gambar.
+func (kr *kabarTerbaruRepository) UpdateKabarTerbaru(ctx context.Context, kabar entities.KabarTerbaru, replaceGambar bool) (entities.KabarTerbaru, error) {
+	err := kr.connection.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&kabar).
+			Select("judul", "isi", "history_penarikan_id", "updated_at").
+			Updates(&kabar).Error; err != nil {
+			return err
+		}
+		if !replaceGambar {
+			return nil
+		}
+
+		if err := tx.Where("kabar_terbaru_id = ?", kabar.ID).Delete(&entities.KabarTerbaruGambar{}).Error; err != nil {
+			return err
+		}
+		if len(kabar.Gambar) == 0 {
+			return nil
+		}
+		for i := range kabar.Gambar {
+			kabar.Gambar[i].ID = 0
+			kabar.Gambar[i].KabarTerbaruID = kabar.ID
+		}
+		return tx.Create(&kabar.Gambar).Error
+	})
+	if err != nil {
+		return entities.KabarTerbaru{}, err
+	}
+	return kr.GetKabarTerbaruByID(ctx, kabar.ID)
+}
+
+func (kr *kabarTerbaruRepository) DeleteKabarTerbaru(ctx context.Context, kabarID uuid.UUID) error {
+	return kr.connection.Delete(&entities.KabarTerbaru{}, "id = ?", kabarID).Error
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type KomentarRepository interface {
+	CreateKomentar(ctx context.Context, komentar entities.Komentar) (entities.Komentar, error)
+	GetKomentarByID(ctx context.Context, komentarID uuid.UUID) (entities.Komentar, error)
+	GetKomentarByEventID(ctx context.Context, eventID uuid.UUID, includeHidden bool, limit int, offset int) ([]entities.Komentar, int64, error)
+	GetReportedKomentar(ctx context.Context, limit int, offset int) ([]entities.Komentar, int64, error)
+	IncrementKomentarRate(ctx context.Context, userID uuid.UUID, now time.Time, window time.Duration) (entities.KomentarRateLimit, error)
+	UpdateKomentarIsi(ctx context.Context, komentarID uuid.UUID, isi string, editedAt time.Time) error
+	SetKomentarHidden(ctx context.Context, komentarID uuid.UUID, hiddenAt *time.Time, hiddenByID *uuid.UUID, reason string) error
+	ReportKomentar(ctx context.Context, report entities.KomentarReport, hideThreshold int, hideReason string) (bool, error)
+	DeleteKomentar(ctx context.Context, komentarID uuid.UUID, deletedAt time.Time) error
+}
+
+type komentarRepository struct {
+	connection *gorm.DB
+}
+
+func NewKomentarRepository(db *gorm.DB) KomentarRepository {
+	return &komentarRepository{
+		connection: db,
+	}
+}
+
+func (kr *komentarRepository) CreateKomentar(ctx context.Context, komentar entities.Komentar) (entities.Komentar, error) {
+	if err := kr.connection.Omit(clause.Associations).Create(&komentar).Error; err != nil {
+		return entities.Komentar{}, err
+	}
+	return kr.GetKomentarByID(ctx, komentar.ID)
+}
+
+func (kr *komentarRepository) GetKomentarByID(ctx context.Context, komentarID uuid.UUID) (entities.Komentar, error) {
+	var komentar entities.Komentar
+	if err := kr.connection.Preload("User").Where("id = ?", komentarID).Take(&komentar).Error; err != nil {
+		return entities.Komentar{}, err
+	}
+	return komentar, nil
+}
+
+// GetKomentarByEventID mengambil komentar utama dari yang terbaru beserta
+// seluruh balasannya dari yang terlama. Komentar yang disembunyikan hanya
+// ikut jika includeHidden bernilai true. Komentar utama yang sudah dihapus
+// tetap ikut selama masih memiliki balasan.
+func (kr *komentarRepository) GetKomentarByEventID(ctx context.Context, eventID uuid.UUID, includeHidden bool, limit int, offset int) ([]entities.Komentar, int64, error) {
+	visible := func(db *gorm.DB) *gorm.DB {
+		if includeHidden {
+			return db
+		}
+		return db.Where("hidden_at IS NULL")
+	}
+
+	hasBalasan := "EXISTS (SELECT 1 FROM komentars balasan WHERE balasan.parent_id = komentars.id AND balasan.deleted_at IS NULL"
+	if !includeHidden {
+		hasBalasan += " AND balasan.hidden_at IS NULL"
+	}
+	hasBalasan += ")"
+
+	query := visible(kr.connection.Model(&entities.Komentar{}).Where("event_id = ? AND parent_id IS NULL", eventID)).
+		Where("deleted_at IS NULL OR " + hasBalasan)
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var komentar []entities.Komentar
+	if err := query.
+		Preload("User").
+		Preload("Balasan", func(db *gorm.DB) *gorm.DB {
+			return visible(db).Where("deleted_at IS NULL").Order("created_at, id")
+		}).
+		Preload("Balasan.User").
+		Order("created_at DESC, id").
+		Limit(limit).
+		Offset(offset).
+		Find(&komentar).Error; err != nil {
+		return nil, 0, err
+	}
+	return komentar, total, nil
+}
+
+// GetReportedKomentar dipakai admin untuk meninjau komentar yang dilaporkan,
+// diurutkan dari laporan terbanyak.
+func (kr *komentarRepository) GetReportedKomentar(ctx context.Context, limit int, offset int) ([]entities.Komentar, int64, error) {
+	query := kr.connection.Model(&entities.Komentar{}).Where("report_count > 0 AND deleted_at IS NULL")
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var komentar []entities.Komentar
+	if err := query.
+		Preload("User").
+		Order("report_count DESC, created_at DESC, id").
+		Limit(limit).
+		Offset(offset).
+		Find(&komentar).Error; err != nil {
+		return nil, 0, err
+	}
+	return komentar, total, nil
+}
+
+// IncrementKomentarRate menaikkan counter komentar user secara atomik dan
+// mengembalikan nilainya. Counter dimulai ulang jika window sebelumnya sudah
+// lewat.
+func (kr *komentarRepository) IncrementKomentarRate(ctx context.Context, userID uuid.UUID, now time.Time, window time.Duration) (entities.KomentarRateLimit, error) {
+	rate := entities.KomentarRateLimit{
+		UserID:      userID,
+		Total:       1,
+		WindowStart: now,
+	}
+	expired := gorm.Expr("komentar_rate_limits.window_start <= ?", now.Add(-window))
+	if err := kr.connection.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"total":        gorm.Expr("CASE WHEN ? THEN 1 ELSE komentar_rate_limits.total + 1 END", expired),
+				"window_start": gorm.Expr("CASE WHEN ? THEN ?::timestamptz ELSE komentar_rate_limits.window_start END", expired, now),
+			}),
+		},
+		clause.Returning{},
+	).Create(&rate).Error; err != nil {
+		return entities.KomentarRateLimit{}, err
+	}
+	return rate, nil
+}
+
+func (kr *komentarRepository) UpdateKomentarIsi(ctx context.Context, komentarID uuid.UUID, isi string, editedAt time.Time) error {
+	return kr.connection.Model(&entities.Komentar{}).Where("id = ?", komentarID).Updates(map[string]any{
+		"isi":        isi,
+		"edited_at":  editedAt,
+		"updated_at": editedAt,
+	}).Error
+}
+
+// SetKomentarHidden menyembunyikan komentar, atau menampilkannya kembali jika
+// hiddenAt bernilai nil.
+func (kr *komentarRepository) SetKomentarHidden(ctx context.Context, komentarID uuid.UUID, hiddenAt *time.Time, hiddenByID *uuid.UUID, reason string) error {
+	return kr.connection.Model(&entities.Komentar{}).Where("id = ?", komentarID).Updates(map[string]any{
+		"hidden_at":     hiddenAt,
+		"hidden_by_id":  hiddenByID,
+		"hidden_reason": reason,
+	}).Error
+}
+
+// ReportKomentar menyimpan laporan dan menaikkan report_count. Komentar
+// otomatis disembunyikan ketika jumlah laporan mencapai hideThreshold.
+// Nilai false berarti user sudah pernah melaporkan komentar ini.
+func (kr *komentarRepository) ReportKomentar(ctx context.Context, report entities.KomentarReport, hideThreshold int, hideReason string) (bool, error) {
+	reported := false
+	err := kr.connection.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&report)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		reported = true
+
+		autoHide := gorm.Expr("hidden_at IS NULL AND report_count + 1 >= ?", hideThreshold)
+		return tx.Model(&entities.Komentar{}).Where("id = ?", report.KomentarID).Updates(map[string]any{
+			"report_count":  gorm.Expr("report_count + 1"),
+			"hidden_at":     gorm.Expr("CASE WHEN ? THEN ?::timestamptz ELSE hidden_at END", autoHide, report.CreatedAt),
+			"hidden_reason": gorm.Expr("CASE WHEN ? THEN ? ELSE hidden_reason END", autoHide, hideReason),
+		}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return reported, nil
+}
+
+// DeleteKomentar mengosongkan isi komentar dan menandainya sudah dihapus.
+// Balasan dan laporan terhadap komentar tersebut tetap disimpan.
+func (kr *komentarRepository) DeleteKomentar(ctx context.Context, komentarID uuid.UUID, deletedAt time.Time) error {
+	return kr.connection.Model(&entities.Komentar{}).Where("id = ?", komentarID).Updates(map[string]any{
+		"isi":        "",
+		"deleted_at": deletedAt,
+		"updated_at": deletedAt,
+	}).Error
+}
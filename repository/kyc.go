@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type KYCRepository interface {
+	SaveKYC(ctx context.Context, kyc entities.UserKYC) (entities.UserKYC, error)
+	GetKYCByID(ctx context.Context, kycID uuid.UUID) (entities.UserKYC, error)
+	GetKYCByUserID(ctx context.Context, userID uuid.UUID) (entities.UserKYC, error)
+	GetKYCByStatus(ctx context.Context, status string) ([]entities.UserKYC, error)
+	IsNIKUsed(ctx context.Context, nikHash string, userID uuid.UUID) (bool, error)
+	ReviewKYC(ctx context.Context, kycID uuid.UUID, status string, reason string, reviewerID uuid.UUID) (bool, error)
+	ApproveKYC(ctx context.Context, kycID uuid.UUID, reviewerID uuid.UUID, fromRole string, toRole string) (bool, error)
+}
+
+type kycRepository struct {
+	connection *gorm.DB
+}
+
+func NewKYCRepository(db *gorm.DB) KYCRepository {
+	return &kycRepository{
+		connection: db,
+	}
+}
+
+// SaveKYC membuat pengajuan baru atau menimpa pengajuan lama milik user yang
+// sama ketika user mengajukan ulang setelah ditolak.
+func (kr *kycRepository) SaveKYC(ctx context.Context, kyc entities.UserKYC) (entities.UserKYC, error) {
+	var existing entities.UserKYC
+	err := kr.connection.Where("user_id = ?", kyc.UserID).Take(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.UserKYC{}, err
+	}
+	if err == nil {
+		kyc.ID = existing.ID
+		kyc.CreatedAt = existing.CreatedAt
+	}
+
+	if err := kr.connection.Save(&kyc).Error; err != nil {
+		return entities.UserKYC{}, err
+	}
+	return kyc, nil
+}
+
+func (kr *kycRepository) GetKYCByID(ctx context.Context, kycID uuid.UUID) (entities.UserKYC, error) {
+	var kyc entities.UserKYC
+	if err := kr.connection.Where("id = ?", kycID).Take(&kyc).Error; err != nil {
+		return entities.UserKYC{}, err
+	}
+	return kyc, nil
+}
+
+func (kr *kycRepository) GetKYCByUserID(ctx context.Context, userID uuid.UUID) (entities.UserKYC, error) {
+	var kyc entities.UserKYC
+	if err := kr.connection.Where("user_id = ?", userID).Take(&kyc).Error; err != nil {
+		return entities.UserKYC{}, err
+	}
+	return kyc, nil
+}
+
+func (kr *kycRepository) GetKYCByStatus(ctx context.Context, status string) ([]entities.UserKYC, error) {
+	var kycs []entities.UserKYC
+	if err := kr.connection.Preload("User").Where("status = ?", status).Order("submitted_at asc").Find(&kycs).Error; err != nil {
+		return nil, err
+	}
+	return kycs, nil
+}
+
+func (kr *kycRepository) IsNIKUsed(ctx context.Context, nikHash string, userID uuid.UUID) (bool, error) {
+	var count int64
+	if err := kr.connection.Model(&entities.UserKYC{}).Where("nik_hash = ? AND user_id <> ?", nikHash, userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ReviewKYC hanya mengubah pengajuan yang masih pending sehingga dua admin
+// tidak bisa memberi keputusan berbeda untuk pengajuan yang sama.
+func (kr *kycRepository) ReviewKYC(ctx context.Context, kycID uuid.UUID, status string, reason string, reviewerID uuid.UUID) (bool, error) {
+	result := kr.connection.Model(&entities.UserKYC{}).
+		Where("id = ? AND status = ?", kycID, entities.KYCStatusPending).
+		Updates(map[string]any{
+			"status":           status,
+			"rejection_reason": reason,
+			"reviewed_at":      time.Now(),
+			"reviewed_by":      reviewerID,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ApproveKYC menyetujui pengajuan yang masih pending dan menaikkan role
+// pemiliknya dari fromRole ke toRole dalam satu transaksi. Role yang sudah
+// berbeda dari fromRole, misalnya admin, tidak diubah.
+func (kr *kycRepository) ApproveKYC(ctx context.Context, kycID uuid.UUID, reviewerID uuid.UUID, fromRole string, toRole string) (bool, error) {
+	approved := false
+	err := kr.connection.Transaction(func(tx *gorm.DB) error {
+		var kyc entities.UserKYC
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", kycID, entities.KYCStatusPending).
+			Take(&kyc).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		if err := tx.Model(&kyc).Updates(map[string]any{
+			"status":           entities.KYCStatusApproved,
+			"rejection_reason": "",
+			"reviewed_at":      time.Now(),
+			"reviewed_by":      reviewerID,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entities.User{}).
+			Where("id = ? AND role = ?", kyc.UserID, fromRole).
+			Update("role", toRole).Error; err != nil {
+			return err
+		}
+		approved = true
+		return nil
+	})
+	return approved, err
+}
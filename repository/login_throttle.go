@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginThrottleRepository interface {
+	GetLoginThrottle(ctx context.Context, key string) (entities.LoginThrottle, error)
+	UpdateLoginThrottle(ctx context.Context, key string, fn func(throttle *entities.LoginThrottle) error) error
+	LockLoginThrottle(ctx context.Context, key string, lockedUntil time.Time) error
+	ResetLoginThrottle(ctx context.Context, key string) error
+	CreateLoginAttempt(ctx context.Context, attempt entities.LoginAttempt) error
+	GetLoginAttempts(ctx context.Context, email string, limit int) ([]entities.LoginAttempt, error)
+}
+
+type loginThrottleRepository struct {
+	connection *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) LoginThrottleRepository {
+	return &loginThrottleRepository{
+		connection: db,
+	}
+}
+
+func (lr *loginThrottleRepository) GetLoginThrottle(ctx context.Context, key string) (entities.LoginThrottle, error) {
+	var throttle entities.LoginThrottle
+	if err := lr.connection.Where("key = ?", key).Take(&throttle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.LoginThrottle{Key: key}, nil
+		}
+		return entities.LoginThrottle{}, err
+	}
+	return throttle, nil
+}
+
+// UpdateLoginThrottle menjalankan fn terhadap throttle yang dikunci dengan
+// SELECT ... FOR UPDATE, sehingga request paralel untuk key yang sama diproses
+// bergantian walaupun API dijalankan di beberapa instance. Perubahan hanya
+// disimpan jika fn tidak mengembalikan error.
+func (lr *loginThrottleRepository) UpdateLoginThrottle(ctx context.Context, key string, fn func(throttle *entities.LoginThrottle) error) error {
+	return lr.connection.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entities.LoginThrottle{Key: key}).Error; err != nil {
+			return err
+		}
+
+		var throttle entities.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).Take(&throttle).Error; err != nil {
+			return err
+		}
+		if err := fn(&throttle); err != nil {
+			return err
+		}
+		return tx.Save(&throttle).Error
+	})
+}
+
+func (lr *loginThrottleRepository) LockLoginThrottle(ctx context.Context, key string, lockedUntil time.Time) error {
+	if err := lr.connection.Model(&entities.LoginThrottle{}).Where("key = ?", key).Update("locked_until", lockedUntil).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (lr *loginThrottleRepository) ResetLoginThrottle(ctx context.Context, key string) error {
+	if err := lr.connection.Where("key = ?", key).Delete(&entities.LoginThrottle{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (lr *loginThrottleRepository) CreateLoginAttempt(ctx context.Context, attempt entities.LoginAttempt) error {
+	if err := lr.connection.Create(&attempt).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (lr *loginThrottleRepository) GetLoginAttempts(ctx context.Context, email string, limit int) ([]entities.LoginAttempt, error) {
+	var attempts []entities.LoginAttempt
+	query := lr.connection.Order("created_at desc").Limit(limit)
+	if email != "" {
+		query = query.Where("email = ?", email)
+	}
+	if err := query.Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
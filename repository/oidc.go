@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OIDCRepository interface {
+	CreateLoginState(ctx context.Context, state entities.OIDCLoginState) error
+	ConsumeLoginState(ctx context.Context, stateHash string, now time.Time) (entities.OIDCLoginState, error)
+	GetUserIdentity(ctx context.Context, issuer string, subject string) (entities.UserIdentity, error)
+	CreateUserIdentity(ctx context.Context, identity entities.UserIdentity) (entities.UserIdentity, error)
+}
+
+type oidcRepository struct {
+	connection *gorm.DB
+}
+
+func NewOIDCRepository(db *gorm.DB) OIDCRepository {
+	return &oidcRepository{
+		connection: db,
+	}
+}
+
+func (ir *oidcRepository) CreateLoginState(ctx context.Context, state entities.OIDCLoginState) error {
+	// State yang tidak pernah diselesaikan dibersihkan setiap ada login baru
+	if err := ir.connection.Where("expires_at < ?", state.CreatedAt).Delete(&entities.OIDCLoginState{}).Error; err != nil {
+		return err
+	}
+	return ir.connection.Create(&state).Error
+}
+
+// ConsumeLoginState menghapus state sekaligus mengembalikannya, sehingga satu
+// state hanya bisa dipakai untuk satu callback.
+func (ir *oidcRepository) ConsumeLoginState(ctx context.Context, stateHash string, now time.Time) (entities.OIDCLoginState, error) {
+	var states []entities.OIDCLoginState
+	if err := ir.connection.Clauses(clause.Returning{}).
+		Where("state_hash = ? AND expires_at > ?", stateHash, now).
+		Delete(&states).Error; err != nil {
+		return entities.OIDCLoginState{}, err
+	}
+	if len(states) == 0 {
+		return entities.OIDCLoginState{}, gorm.ErrRecordNotFound
+	}
+	return states[0], nil
+}
+
+func (ir *oidcRepository) GetUserIdentity(ctx context.Context, issuer string, subject string) (entities.UserIdentity, error) {
+	var identity entities.UserIdentity
+	if err := ir.connection.Where("issuer = ? AND subject = ?", issuer, subject).Take(&identity).Error; err != nil {
+		return entities.UserIdentity{}, err
+	}
+	return identity, nil
+}
+
+func (ir *oidcRepository) CreateUserIdentity(ctx context.Context, identity entities.UserIdentity) (entities.UserIdentity, error) {
+	if err := ir.connection.Create(&identity).Error; err != nil {
+		return entities.UserIdentity{}, err
+	}
+	return identity, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	CreatePasswordResetToken(ctx context.Context, resetToken entities.PasswordResetToken) (entities.PasswordResetToken, error)
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (entities.PasswordResetToken, error)
+	UsePasswordResetToken(ctx context.Context, resetTokenID uuid.UUID) (bool, error)
+	InvalidatePasswordResetTokenByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type passwordResetRepository struct {
+	connection *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{
+		connection: db,
+	}
+}
+
+func (pr *passwordResetRepository) CreatePasswordResetToken(ctx context.Context, resetToken entities.PasswordResetToken) (entities.PasswordResetToken, error) {
+	if err := pr.connection.Create(&resetToken).Error; err != nil {
+		return entities.PasswordResetToken{}, err
+	}
+	return resetToken, nil
+}
+
+func (pr *passwordResetRepository) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (entities.PasswordResetToken, error) {
+	var resetToken entities.PasswordResetToken
+	if err := pr.connection.Where("token_hash = ?", tokenHash).Take(&resetToken).Error; err != nil {
+		return entities.PasswordResetToken{}, err
+	}
+	return resetToken, nil
+}
+
+// UsePasswordResetToken menandai token sudah dipakai, hanya berhasil sekali
+// walaupun ada beberapa request reset yang bersamaan.
+func (pr *passwordResetRepository) UsePasswordResetToken(ctx context.Context, resetTokenID uuid.UUID) (bool, error) {
+	result := pr.connection.Model(&entities.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", resetTokenID, time.Now()).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (pr *passwordResetRepository) InvalidatePasswordResetTokenByUserID(ctx context.Context, userID uuid.UUID) error {
+	if err := pr.connection.Model(&entities.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, refreshToken entities.RefreshToken) (entities.RefreshToken, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (entities.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, refreshTokenID uuid.UUID, replacedByID uuid.UUID) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeRefreshTokenByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type refreshTokenRepository struct {
+	connection *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{
+		connection: db,
+	}
+}
+
+func (rr *refreshTokenRepository) CreateRefreshToken(ctx context.Context, refreshToken entities.RefreshToken) (entities.RefreshToken, error) {
+	if err := rr.connection.Create(&refreshToken).Error; err != nil {
+		return entities.RefreshToken{}, err
+	}
+	return refreshToken, nil
+}
+
+func (rr *refreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (entities.RefreshToken, error) {
+	var refreshToken entities.RefreshToken
+	if err := rr.connection.Where("token_hash = ?", tokenHash).Take(&refreshToken).Error; err != nil {
+		return entities.RefreshToken{}, err
+	}
+	return refreshToken, nil
+}
+
+// RotateRefreshToken hanya berhasil untuk token yang belum dicabut, sehingga
+// dua request refresh yang bersamaan tidak bisa sama-sama lolos.
+func (rr *refreshTokenRepository) RotateRefreshToken(ctx context.Context, refreshTokenID uuid.UUID, replacedByID uuid.UUID) (bool, error) {
+	result := rr.connection.Model(&entities.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", refreshTokenID).
+		Updates(map[string]any{
+			"revoked_at":     time.Now(),
+			"replaced_by_id": replacedByID,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeRefreshTokenFamily ikut mencabut session karena satu family refresh
+// token mewakili satu session login.
+func (rr *refreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	return rr.connection.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&entities.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", familyID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entities.UserSession{}).
+			Where("id = ? AND revoked_at IS NULL", familyID).
+			Update("revoked_at", now).Error
+	})
+}
+
+func (rr *refreshTokenRepository) RevokeRefreshTokenByUserID(ctx context.Context, userID uuid.UUID) error {
+	return rr.connection.Transaction(func(tx *gorm.DB) error {
+		return revokeRefreshTokenByUserID(tx, userID, time.Now())
+	})
+}
+
+// revokeRefreshTokenByUserID mencabut seluruh refresh token dan sesi milik
+// user. db harus berupa transaksi agar keduanya dicabut bersamaan.
+func revokeRefreshTokenByUserID(db *gorm.DB, userID uuid.UUID, now time.Time) error {
+	if err := db.Model(&entities.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return db.Model(&entities.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevokedTokenRepository interface {
+	RevokeToken(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error
+	RevokeAllUserToken(ctx context.Context, userID uuid.UUID, revokedBefore time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+	DeleteExpiredRevokedToken(ctx context.Context, now time.Time, maxTokenAge time.Duration) (int64, error)
+}
+
+type revokedTokenRepository struct {
+	connection *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) RevokedTokenRepository {
+	return &revokedTokenRepository{
+		connection: db,
+	}
+}
+
+func (rr *revokedTokenRepository) RevokeToken(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	revoked := entities.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+	if err := rr.connection.Clauses(clause.OnConflict{DoNothing: true}).Create(&revoked).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *revokedTokenRepository) RevokeAllUserToken(ctx context.Context, userID uuid.UUID, revokedBefore time.Time) error {
+	return revokeAllUserToken(rr.connection, userID, revokedBefore)
+}
+
+// revokeAllUserToken juga dipakai repository lain yang perlu mencabut access
+// token di dalam transaksinya sendiri.
+func revokeAllUserToken(db *gorm.DB, userID uuid.UUID, revokedBefore time.Time) error {
+	revocation := entities.UserTokenRevocation{
+		UserID:        userID,
+		RevokedBefore: revokedBefore,
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_before"}),
+	}).Create(&revocation).Error
+}
+
+func (rr *revokedTokenRepository) IsTokenRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	var count int64
+	if jti != "" {
+		if err := rr.connection.Model(&entities.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	if err := rr.connection.Model(&entities.UserTokenRevocation{}).
+		Where("user_id = ? AND revoked_before > ?", userID, issuedAt).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteExpiredRevokedToken menghapus jti yang sudah kadaluarsa dan pencabutan
+// per user yang lebih tua dari umur maksimal access token.
+func (rr *revokedTokenRepository) DeleteExpiredRevokedToken(ctx context.Context, now time.Time, maxTokenAge time.Duration) (int64, error) {
+	result := rr.connection.Where("expires_at < ?", now).Delete(&entities.RevokedToken{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	deleted := result.RowsAffected
+
+	result = rr.connection.Where("revoked_before < ?", now.Add(-maxTokenAge)).Delete(&entities.UserTokenRevocation{})
+	if result.Error != nil {
+		return deleted, result.Error
+	}
+	return deleted + result.RowsAffected, nil
+}
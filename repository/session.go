@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session entities.UserSession) error
+	GetSessionByID(ctx context.Context, sessionID uuid.UUID) (entities.UserSession, error)
+	GetActiveSessionsByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]entities.UserSession, error)
+	TouchSession(ctx context.Context, sessionID uuid.UUID, ip string, now time.Time, interval time.Duration) error
+	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (bool, error)
+	RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) error
+}
+
+type sessionRepository struct {
+	connection *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{
+		connection: db,
+	}
+}
+
+// CreateSession juga dipanggil setiap refresh token dirotasi. Session yang
+// sudah ada cukup diperpanjang masa berlakunya.
+func (sr *sessionRepository) CreateSession(ctx context.Context, session entities.UserSession) error {
+	return sr.connection.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expires_at"}),
+	}).Create(&session).Error
+}
+
+func (sr *sessionRepository) GetSessionByID(ctx context.Context, sessionID uuid.UUID) (entities.UserSession, error) {
+	var session entities.UserSession
+	if err := sr.connection.Where("id = ?", sessionID).Take(&session).Error; err != nil {
+		return entities.UserSession{}, err
+	}
+	return session, nil
+}
+
+func (sr *sessionRepository) GetActiveSessionsByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]entities.UserSession, error) {
+	var sessions []entities.UserSession
+	if err := sr.connection.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at desc").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// TouchSession mencatat aktivitas terakhir paling sering sekali per interval
+// agar setiap request tidak selalu menulis ke database.
+func (sr *sessionRepository) TouchSession(ctx context.Context, sessionID uuid.UUID, ip string, now time.Time, interval time.Duration) error {
+	return sr.connection.Model(&entities.UserSession{}).
+		Where("id = ? AND last_seen_at < ?", sessionID, now.Add(-interval)).
+		Updates(map[string]any{
+			"last_seen_at": now,
+			"ip_address":   ip,
+		}).Error
+}
+
+// RevokeSession mencabut session beserta refresh token di family yang sama.
+func (sr *sessionRepository) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (bool, error) {
+	revoked := false
+	err := sr.connection.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entities.UserSession{}).
+			Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+			Update("revoked_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&entities.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", sessionID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		revoked = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
+func (sr *sessionRepository) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) error {
+	return sr.connection.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&entities.UserSession{}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, currentSessionID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entities.RefreshToken{}).
+			Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, currentSessionID).
+			Update("revoked_at", now).Error
+	})
+}
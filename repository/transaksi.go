@@ -14,6 +14,7 @@ type TransaksiRepository interface {
 	GetTransaksiByID(ctx context.Context, transaksiID uuid.UUID) (entities.Transaksi, error)
 	GetAllTransaksiByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Transaksi, error)
 	GetAllEventLastTransaksi(ctx context.Context, eventID uuid.UUID) ([]entities.Transaksi, error)
+	GetDonorEmailsByEventID(ctx context.Context, eventID uuid.UUID) ([]string, error)
 }
 
 type transaksiRepository struct {
@@ -75,3 +76,17 @@ func (tr *transaksiRepository) GetAllEventLastTransaksi(ctx context.Context, eve
 	}
 	return transaksi, nil
 }
+
+// GetDonorEmailsByEventID mengembalikan email unik semua donatur event, tanpa
+// akun yang sudah dianonimkan.
+func (tr *transaksiRepository) GetDonorEmailsByEventID(ctx context.Context, eventID uuid.UUID) ([]string, error) {
+	var emails []string
+	if err := tr.connection.Model(&entities.Transaksi{}).
+		Distinct("users.email").
+		Joins("JOIN users ON users.id = transaksis.user_id").
+		Where("transaksis.event_id = ? AND users.anonymized_at IS NULL", eventID).
+		Pluck("users.email", &emails).Error; err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
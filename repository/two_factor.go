@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TwoFactorRepository interface {
+	SaveTwoFactor(ctx context.Context, twoFactor entities.UserTwoFactor) error
+	GetTwoFactorByUserID(ctx context.Context, userID uuid.UUID) (entities.UserTwoFactor, error)
+	EnableTwoFactor(ctx context.Context, userID uuid.UUID, step int64) error
+	UseTwoFactorStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	DeleteTwoFactor(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+}
+
+type twoFactorRepository struct {
+	connection *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{
+		connection: db,
+	}
+}
+
+func (tr *twoFactorRepository) SaveTwoFactor(ctx context.Context, twoFactor entities.UserTwoFactor) error {
+	if err := tr.connection.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret_encrypted", "enabled_at", "last_used_step", "updated_at"}),
+	}).Create(&twoFactor).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tr *twoFactorRepository) GetTwoFactorByUserID(ctx context.Context, userID uuid.UUID) (entities.UserTwoFactor, error) {
+	var twoFactor entities.UserTwoFactor
+	if err := tr.connection.Where("user_id = ?", userID).Take(&twoFactor).Error; err != nil {
+		return entities.UserTwoFactor{}, err
+	}
+	return twoFactor, nil
+}
+
+func (tr *twoFactorRepository) EnableTwoFactor(ctx context.Context, userID uuid.UUID, step int64) error {
+	if err := tr.connection.Model(&entities.UserTwoFactor{}).Where("user_id = ?", userID).Updates(map[string]any{
+		"enabled_at":     time.Now(),
+		"last_used_step": step,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// UseTwoFactorStep mencatat step TOTP yang baru dipakai, dan gagal jika step
+// tersebut atau yang lebih baru sudah pernah dipakai sehingga kode tidak bisa
+// digunakan dua kali.
+func (tr *twoFactorRepository) UseTwoFactorStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	result := tr.connection.Model(&entities.UserTwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (tr *twoFactorRepository) DeleteTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	return tr.connection.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&entities.UserTwoFactor{}).Error
+	})
+}
+
+func (tr *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	return tr.connection.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]entities.TwoFactorRecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, entities.TwoFactorRecoveryCode{
+				CodeHash: hash,
+				UserID:   userID,
+			})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (tr *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	result := tr.connection.Model(&entities.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
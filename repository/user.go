@@ -2,10 +2,15 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Caknoooo/golang-clean_template/entities"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
@@ -13,8 +18,16 @@ type UserRepository interface {
 	GetAllUser(ctx context.Context) ([]entities.User, error)
 	GetUserByID(ctx context.Context, userID uuid.UUID) (entities.User, error)
 	GetUserByEmail(ctx context.Context, email string) (entities.User, error)
-	UpdateUser(ctx context.Context, user entities.User) (error)
-	DeleteUser(ctx context.Context, userID uuid.UUID) (error) 
+	UpdateUser(ctx context.Context, user entities.User) error
+	ScheduleDeletion(ctx context.Context, userID uuid.UUID, scheduledAt time.Time) error
+	CancelDeletion(ctx context.Context, userID uuid.UUID) (bool, error)
+	GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]entities.User, error)
+	AnonymizeUser(ctx context.Context, userID uuid.UUID, now time.Time) (bool, []string, error)
+	UpdateUserRole(ctx context.Context, userID uuid.UUID, role string) error
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID) error
+	UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error
+	UpdatePasswordAndRevokeTokens(ctx context.Context, userID uuid.UUID, hashedPassword string, now time.Time) error
+	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error
 }
 
 type userRepository struct {
@@ -65,9 +78,185 @@ func (ur *userRepository) UpdateUser(ctx context.Context, user entities.User) (e
 	return nil
 }
 
-func (ur *userRepository) DeleteUser(ctx context.Context, userID uuid.UUID) (error) {
-	if err := ur.connection.Delete(&entities.User{}, &userID).Error; err != nil {
+func (ur *userRepository) ScheduleDeletion(ctx context.Context, userID uuid.UUID, scheduledAt time.Time) error {
+	if err := ur.connection.Model(&entities.User{}).Where("id = ? AND anonymized_at IS NULL", userID).Update("deletion_scheduled_at", scheduledAt).Error; err != nil {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (ur *userRepository) CancelDeletion(ctx context.Context, userID uuid.UUID) (bool, error) {
+	result := ur.connection.Model(&entities.User{}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL AND anonymized_at IS NULL", userID).
+		Update("deletion_scheduled_at", nil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetUsersDueForDeletion melewati user yang masih memiliki campaign aktif
+// tanpa penerima yang sudah menyetujui penyerahan. User tersebut harus
+// ditunda, dan jika tidak disaring di sini batch akan penuh oleh user yang
+// sama setiap kali job berjalan.
+func (ur *userRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]entities.User, error) {
+	var users []entities.User
+	hasActiveEvents := ur.connection.Model(&entities.Event{}).Select("1").Where("events.user_id = users.id").Scopes(activeEvents)
+	hasAcceptedHandover := ur.connection.Model(&entities.CampaignHandover{}).Select("1").Where("campaign_handovers.from_user_id = users.id AND campaign_handovers.accepted_at IS NOT NULL")
+	if err := ur.connection.
+		Where("deletion_scheduled_at <= ? AND anonymized_at IS NULL", now).
+		Where("NOT EXISTS (?) OR EXISTS (?)", hasActiveEvents, hasAcceptedHandover).
+		Order("deletion_scheduled_at").Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// AnonymizeUser mengganti data pribadi user dengan tombstone dan menghapus
+// seluruh kredensial, data KYC serta log login-nya. Transaksi, penarikan dan
+// event tetap menunjuk ke baris user yang sama sehingga riwayat keuangan
+// tidak hilang. kycFiles berisi dokumen KYC yang harus dihapus dari disk
+// setelah transaksi selesai.
+func (ur *userRepository) AnonymizeUser(ctx context.Context, userID uuid.UUID, now time.Time) (bool, []string, error) {
+	anonymized := false
+	var kycFiles []string
+	err := ur.connection.Transaction(func(tx *gorm.DB) error {
+		var user entities.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "email").
+			Where("id = ? AND deletion_scheduled_at <= ? AND anonymized_at IS NULL", userID, now).
+			Take(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		if err := tx.Model(&user).Updates(map[string]any{
+			"nama":                  "Pengguna Terhapus",
+			"email":                 fmt.Sprintf("deleted+%s@deleted.invalid", userID),
+			"no_telp":               "",
+			"password":              "",
+			"confirm_password":      "",
+			"email_verified_at":     nil,
+			"deletion_scheduled_at": nil,
+			"anonymized_at":         now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&entities.Event{}).Where("user_id = ?", userID).Updates(map[string]any{
+			"nama_depan_pembuat":    "Pengguna",
+			"nama_belakang_pembuat": "Terhapus",
+			"nomor_telepon_pembuat": "",
+			"nomor_ktp":             "",
+		}).Error; err != nil {
+			return err
+		}
+
+		var kycs []entities.UserKYC
+		if err := tx.Clauses(clause.Returning{}).Where("user_id = ?", userID).Delete(&kycs).Error; err != nil {
+			return err
+		}
+		for _, kyc := range kycs {
+			kycFiles = append(kycFiles, kyc.KTPPath, kyc.SelfiePath)
+		}
+
+		for _, model := range []any{
+			&entities.RefreshToken{},
+			&entities.UserSession{},
+			&entities.PasswordResetToken{},
+			&entities.EmailChangeToken{},
+			&entities.TwoFactorRecoveryCode{},
+			&entities.UserTwoFactor{},
+			&entities.UserIdentity{},
+			&entities.LoginAttempt{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// Percobaan login yang gagal tidak selalu memiliki user_id, sehingga
+		// log dan throttle akun juga dihapus berdasarkan email
+		email := strings.ToLower(strings.TrimSpace(user.Email))
+		if err := tx.Where("LOWER(TRIM(email)) = ?", email).Delete(&entities.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("key = ?", "account:"+email).Delete(&entities.LoginThrottle{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("from_user_id = ? OR to_user_id = ?", userID, userID).Delete(&entities.CampaignHandover{}).Error; err != nil {
+			return err
+		}
+
+		anonymized = true
+		return nil
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	return anonymized, kycFiles, nil
+}
+
+func (ur *userRepository) UpdateUserRole(ctx context.Context, userID uuid.UUID, role string) error {
+	if err := ur.connection.Model(&entities.User{}).Where("id = ?", userID).Update("role", role).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ur *userRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	if err := ur.connection.Model(&entities.User{}).Where("id = ?", userID).Update("email_verified_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdatePassword menerima password yang sudah di-hash karena hook BeforeCreate
+// tidak dijalankan saat update.
+func (ur *userRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
+	if err := ur.connection.Model(&entities.User{}).Where("id = ?", userID).Updates(map[string]any{
+		"password":         hashedPassword,
+		"confirm_password": hashedPassword,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdatePasswordAndRevokeTokens mengganti password dan mencabut seluruh
+// refresh token, sesi, access token serta permintaan perubahan email milik
+// user dalam satu transaksi, sehingga password baru tidak tersimpan tanpa
+// sesi lama ikut dicabut.
+func (ur *userRepository) UpdatePasswordAndRevokeTokens(ctx context.Context, userID uuid.UUID, hashedPassword string, now time.Time) error {
+	return ur.connection.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.User{}).Where("id = ?", userID).Updates(map[string]any{
+			"password":         hashedPassword,
+			"confirm_password": hashedPassword,
+		}).Error; err != nil {
+			return err
+		}
+		if err := revokeRefreshTokenByUserID(tx, userID, now); err != nil {
+			return err
+		}
+		if err := tx.Model(&entities.EmailChangeToken{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return revokeAllUserToken(tx, userID, now)
+	})
+}
+
+// UpdateEmail dipanggil setelah alamat baru dikonfirmasi, sehingga email baru
+// langsung dianggap terverifikasi.
+func (ur *userRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	if err := ur.connection.Model(&entities.User{}).Where("id = ?", userID).Updates(map[string]any{
+		"email":             email,
+		"email_verified_at": time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 type PenarikanRepository interface {
 	CreatePenarikan(ctx context.Context, penarikan entities.HistoryPenarikan) (entities.HistoryPenarikan, error)
 	GetPenarikanByUser(ctx context.Context, userID uuid.UUID) ([]entities.HistoryPenarikan, error)
+	GetPenarikanByID(ctx context.Context, penarikanID uint) (entities.HistoryPenarikan, error)
 }
 
 type penarikanRepository struct {
@@ -24,20 +25,24 @@ func NewPenarikanRepository(db *gorm.DB) PenarikanRepository {
 	}
 }
 
-func (pr *penarikanRepository) CreatePenarikan(ctx context.Context, penarikan entities.HistoryPenarikan) (entities.HistoryPenarikan, error){
-	var updateEvent entities.Event
-	if err := pr.connection.First(&updateEvent, penarikan.EventID).Error; err != nil {
-		return entities.HistoryPenarikan{}, err
-	}
-
-	if updateEvent.SisaDonasi - penarikan.Jumlah_Penarikan < 0 {
-		return entities.HistoryPenarikan{}, errors.New("Penarikan Melebihi Batas Saldo Yang Tersisa")
-	} else {
-		updateEvent.SisaDonasi -= penarikan.Jumlah_Penarikan
-		pr.connection.Save(&updateEvent)
+func (pr *penarikanRepository) CreatePenarikan(ctx context.Context, penarikan entities.HistoryPenarikan) (entities.HistoryPenarikan, error) {
+	if penarikan.Jumlah_Penarikan <= 0 {
+		return entities.HistoryPenarikan{}, errors.New("Jumlah Penarikan Harus Lebih Dari 0")
 	}
-
-	if err := pr.connection.Create(&penarikan).Error; err != nil {
+	err := pr.connection.Transaction(func(tx *gorm.DB) error {
+		// Saldo dikurangi secara atomik dan hanya untuk event yang dananya boleh ditarik
+		result := tx.Model(&entities.Event{}).
+			Where("id = ? AND status IN ? AND sisa_donasi >= ?", penarikan.EventID, entities.EventWithdrawableStatuses, penarikan.Jumlah_Penarikan).
+			UpdateColumn("sisa_donasi", gorm.Expr("sisa_donasi - ?", penarikan.Jumlah_Penarikan))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("Penarikan Melebihi Batas Saldo Yang Tersisa")
+		}
+		return tx.Create(&penarikan).Error
+	})
+	if err != nil {
 		return entities.HistoryPenarikan{}, err
 	}
 	return penarikan, nil
@@ -49,4 +54,12 @@ func (pr *penarikanRepository) GetPenarikanByUser(ctx context.Context, userID uu
 		return nil, err
 	}
 	return penarikan, nil
-}
\ No newline at end of file
+}
+
+func (pr *penarikanRepository) GetPenarikanByID(ctx context.Context, penarikanID uint) (entities.HistoryPenarikan, error) {
+	var penarikan entities.HistoryPenarikan
+	if err := pr.connection.Where("id = ?", penarikanID).Take(&penarikan).Error; err != nil {
+		return entities.HistoryPenarikan{}, err
+	}
+	return penarikan, nil
+}
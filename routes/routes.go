@@ -2,47 +2,104 @@ package routes
 
 import (
 	"github.com/Caknoooo/golang-clean_template/controller"
+	"github.com/Caknoooo/golang-clean_template/entities"
 	"github.com/Caknoooo/golang-clean_template/middleware"
 	"github.com/Caknoooo/golang-clean_template/services"
 	"github.com/gin-gonic/gin"
 )
 
-func Router(route *gin.Engine, UserController controller.UserController, EventController controller.EventController, TransaksiController controller.TransaksiController, SeederController controller.SeederController, PenarikanController controller.PenarikanController, jwtService services.JWTService) {
+func Router(route *gin.Engine, UserController controller.UserController, EventController controller.EventController, TransaksiController controller.TransaksiController, SeederController controller.SeederController, PenarikanController controller.PenarikanController, KYCController controller.KYCController, APIKeyController controller.APIKeyController, SessionController controller.SessionController, CategoryController controller.CategoryController, KabarTerbaruController controller.KabarTerbaruController, KomentarController controller.KomentarController, AssetController controller.AssetController, jwtService services.JWTService, sessionService services.SessionService, twoFactorService services.TwoFactorService, apiKeyService services.APIKeyService) {
 	routes := route.Group("/api/user")
 	{
 		routes.POST("", UserController.RegisterUser)
-		routes.GET("", middleware.Authenticate(jwtService), UserController.GetAllUser)
+		routes.GET("", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin), UserController.GetAllUser)
 		routes.POST("/login", UserController.LoginUser)
-		routes.DELETE("/", middleware.Authenticate(jwtService), UserController.DeleteUser)
-		routes.PUT("/", middleware.Authenticate(jwtService), UserController.UpdateUser)
-		routes.GET("/me", middleware.Authenticate(jwtService), UserController.MeUser)
-		routes.POST("/transaksi/:event_id", middleware.Authenticate(jwtService), UserController.CreateTransaksiUser)
-		routes.PUT("/transaksi/:event_id", middleware.Authenticate(jwtService), UserController.CreateTransaksiUser)
-		routes.GET("/transaksi", middleware.Authenticate(jwtService), UserController.GetTransaksiUser)
+		routes.POST("/login/2fa", UserController.LoginTwoFactor)
+		routes.GET("/oidc/login", UserController.OIDCLogin)
+		routes.GET("/oidc/callback", UserController.OIDCCallback)
+		routes.POST("/oidc/reauth", middleware.Authenticate(jwtService, sessionService), UserController.OIDCReauth)
+		routes.POST("/refresh", UserController.RefreshToken)
+		routes.GET("/verify", UserController.VerifyEmail)
+		routes.POST("/verify/resend", UserController.ResendVerificationEmail)
+		routes.POST("/password/forgot", UserController.ForgotPassword)
+		routes.POST("/password/reset", UserController.ResetPassword)
+		routes.PUT("/password", middleware.Authenticate(jwtService, sessionService), UserController.ChangePassword)
+		routes.POST("/email/change", middleware.Authenticate(jwtService, sessionService), UserController.ChangeEmail)
+		routes.GET("/email/confirm", UserController.ConfirmEmailChange)
+		routes.POST("/logout", middleware.Authenticate(jwtService, sessionService), UserController.LogoutUser)
+		routes.POST("/logout/all", middleware.Authenticate(jwtService, sessionService), UserController.LogoutAllUser)
+		routes.DELETE("/", middleware.Authenticate(jwtService, sessionService), middleware.RequireStepUp(twoFactorService), UserController.DeleteUser)
+		routes.POST("/deletion/cancel", middleware.Authenticate(jwtService, sessionService), UserController.CancelDeletion)
+		routes.GET("/handover", middleware.Authenticate(jwtService, sessionService), UserController.GetHandoverOffers)
+		routes.PUT("/handover/:id/accept", middleware.Authenticate(jwtService, sessionService), UserController.AcceptHandover)
+		routes.PUT("/handover/:id/reject", middleware.Authenticate(jwtService, sessionService), UserController.RejectHandover)
+		routes.PUT("/", middleware.Authenticate(jwtService, sessionService), UserController.UpdateUser)
+		routes.GET("/me", middleware.Authenticate(jwtService, sessionService), UserController.MeUser)
+		routes.POST("/transaksi/:event_id", middleware.Authenticate(jwtService, sessionService), UserController.CreateTransaksiUser)
+		routes.PUT("/transaksi/:event_id", middleware.Authenticate(jwtService, sessionService), UserController.CreateTransaksiUser)
+		routes.GET("/transaksi", middleware.Authenticate(jwtService, sessionService), UserController.GetTransaksiUser)
+		routes.PUT("/promote/:user_id", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin), UserController.PromoteUser)
+		routes.PUT("/demote/:user_id", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin), UserController.DemoteUser)
+		routes.PUT("/unlock/:user_id", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin), UserController.UnlockUser)
+		routes.GET("/login-attempts", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin), UserController.GetLoginAttempts)
+		routes.POST("/2fa/setup", middleware.Authenticate(jwtService, sessionService), UserController.SetupTwoFactor)
+		routes.POST("/2fa/enable", middleware.Authenticate(jwtService, sessionService), UserController.EnableTwoFactor)
+		routes.POST("/2fa/disable", middleware.Authenticate(jwtService, sessionService), UserController.DisableTwoFactor)
+		routes.POST("/2fa/recovery-codes", middleware.Authenticate(jwtService, sessionService), UserController.RegenerateRecoveryCodes)
+		routes.POST("/2fa/step-up", middleware.Authenticate(jwtService, sessionService), UserController.StepUp)
+		routes.GET("/sessions", middleware.Authenticate(jwtService, sessionService), SessionController.GetSessions)
+		routes.POST("/sessions/revoke-others", middleware.Authenticate(jwtService, sessionService), SessionController.RevokeOtherSessions)
+		routes.DELETE("/sessions/:session_id", middleware.Authenticate(jwtService, sessionService), SessionController.RevokeSession)
 	}
 
 	eventRoutes := route.Group("/api/event")
 	{
-		eventRoutes.POST("", middleware.Authenticate(jwtService), EventController.CreateEvent)
-		eventRoutes.GET("", EventController.GetAllEvent)
-		eventRoutes.GET("/get3event", EventController.Get3Event)
-		eventRoutes.POST("/post3event", EventController.Post3Event)
-		eventRoutes.GET("/service", EventController.GetEventForService)
-		eventRoutes.GET("/user/:user_id", middleware.Authenticate(jwtService), EventController.GetAllEventByUserID)
-		eventRoutes.GET("/get/:id", EventController.GetEventByID)
-		eventRoutes.PUT("/:id", middleware.Authenticate(jwtService), EventController.UpdateEvent)
-		eventRoutes.DELETE("/:id", middleware.Authenticate(jwtService), EventController.DeleteEvent)
-		eventRoutes.GET("/like/:user_id/:event_id", middleware.Authenticate(jwtService), EventController.LikeEventByEventID)
+		eventRoutes.POST("", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleCampaigner, entities.RoleAdmin), EventController.CreateEvent)
+		eventRoutes.GET("", middleware.OptionalAuthenticate(jwtService, sessionService), EventController.GetAllEvent)
+		eventRoutes.GET("/search", middleware.OptionalAuthenticate(jwtService, sessionService), EventController.SearchEvents)
+		eventRoutes.GET("/service", middleware.AuthenticateAPIKeyOrJWT(jwtService, sessionService, apiKeyService), middleware.RequireScopeOrRole(entities.ScopeEventsRead, entities.RoleUser, entities.RoleCampaigner, entities.RoleAdmin), EventController.GetEventForService)
+		eventRoutes.GET("/user/:user_id", middleware.Authenticate(jwtService, sessionService), EventController.GetAllEventByUserID)
+		eventRoutes.GET("/get/:id", middleware.OptionalAuthenticate(jwtService, sessionService), EventController.GetEventByID)
+		eventRoutes.PUT("/:id", middleware.Authenticate(jwtService, sessionService), EventController.UpdateEvent)
+		eventRoutes.PUT("/:id/status", middleware.Authenticate(jwtService, sessionService), EventController.UpdateEventStatus)
+		eventRoutes.GET("/:id/status/history", middleware.Authenticate(jwtService, sessionService), EventController.GetEventStatusHistory)
+		eventRoutes.DELETE("/:id", middleware.Authenticate(jwtService, sessionService), EventController.DeleteEvent)
+		eventRoutes.GET("/:id/kabar", middleware.OptionalAuthenticate(jwtService, sessionService), KabarTerbaruController.GetKabarTerbaruByEventID)
+		eventRoutes.POST("/:id/kabar", middleware.Authenticate(jwtService, sessionService), KabarTerbaruController.CreateKabarTerbaru)
+		eventRoutes.PUT("/:id/kabar/:kabar_id", middleware.Authenticate(jwtService, sessionService), KabarTerbaruController.UpdateKabarTerbaru)
+		eventRoutes.DELETE("/:id/kabar/:kabar_id", middleware.Authenticate(jwtService, sessionService), KabarTerbaruController.DeleteKabarTerbaru)
+		eventRoutes.GET("/:id/komentar", middleware.OptionalAuthenticate(jwtService, sessionService), KomentarController.GetKomentarByEventID)
+		eventRoutes.POST("/:id/komentar", middleware.Authenticate(jwtService, sessionService), KomentarController.CreateKomentar)
+		eventRoutes.POST("/like/:event_id", middleware.Authenticate(jwtService, sessionService), EventController.LikeEventByEventID)
 		eventRoutes.GET("/last/:event_id", EventController.GetAllEventLastTransaksi)
 	}
 
-	transaksiRoutes := route.Group("/api/transaksi")
+	komentarRoutes := route.Group("/api/komentar", middleware.Authenticate(jwtService, sessionService))
+	{
+		komentarRoutes.GET("/reported", middleware.RequireRole(entities.RoleAdmin), KomentarController.GetReportedKomentar)
+		komentarRoutes.PUT("/:id", KomentarController.UpdateKomentar)
+		komentarRoutes.DELETE("/:id", KomentarController.DeleteKomentar)
+		komentarRoutes.POST("/:id/report", KomentarController.ReportKomentar)
+		komentarRoutes.PUT("/:id/hide", KomentarController.HideKomentar)
+		komentarRoutes.PUT("/:id/unhide", KomentarController.UnhideKomentar)
+	}
+
+	categoryRoutes := route.Group("/api/category")
+	{
+		categoryRoutes.GET("", CategoryController.GetCategoryTree)
+		categoryRoutes.GET("/all", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin), CategoryController.GetAllCategories)
+		categoryRoutes.POST("", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin), CategoryController.CreateCategory)
+		categoryRoutes.PUT("/:id", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin), CategoryController.UpdateCategory)
+		categoryRoutes.DELETE("/:id", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin), CategoryController.DeleteCategory)
+	}
+
+	transaksiRoutes := route.Group("/api/transaksi", middleware.AuthenticateAPIKeyOrJWT(jwtService, sessionService, apiKeyService), middleware.RequireScopeOrRole(entities.ScopeTransaksiRead, entities.RoleAdmin))
 	{
 		transaksiRoutes.GET("", TransaksiController.GetAllTransaksi)
 		transaksiRoutes.GET("/get/:id", TransaksiController.GetTransaksiByID)
 	}
 
-	seederRoutes := route.Group("/api/seeder")
+	seederRoutes := route.Group("/api/seeder", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin))
 	{
 		seederRoutes.GET("/category", SeederController.GetAllCategory)
 		seederRoutes.GET("/bank", SeederController.GetAllBank)
@@ -54,7 +111,30 @@ func Router(route *gin.Engine, UserController controller.UserController, EventCo
 
 	penarikanRoutes := route.Group("/api/penarikan")
 	{
-		penarikanRoutes.POST("", middleware.Authenticate(jwtService), PenarikanController.CreatePenarikan)
-		penarikanRoutes.GET("", middleware.Authenticate(jwtService), PenarikanController.GetPenarikanByUser)
+		penarikanRoutes.POST("", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleCampaigner, entities.RoleAdmin), middleware.RequireStepUp(twoFactorService), PenarikanController.CreatePenarikan)
+		penarikanRoutes.GET("", middleware.Authenticate(jwtService, sessionService), PenarikanController.GetPenarikanByUser)
+	}
+
+	kycRoutes := route.Group("/api/kyc", middleware.Authenticate(jwtService, sessionService))
+	{
+		kycRoutes.POST("", KYCController.SubmitKYC)
+		kycRoutes.GET("/me", KYCController.GetMyKYC)
+		kycRoutes.GET("", middleware.RequireRole(entities.RoleAdmin), KYCController.GetKYCQueue)
+		kycRoutes.GET("/:id", middleware.RequireRole(entities.RoleAdmin), KYCController.GetKYCByID)
+		kycRoutes.GET("/:id/document/:document", middleware.RequireRole(entities.RoleAdmin), KYCController.GetKYCDocument)
+		kycRoutes.PUT("/:id/approve", middleware.RequireRole(entities.RoleAdmin), KYCController.ApproveKYC)
+		kycRoutes.PUT("/:id/reject", middleware.RequireRole(entities.RoleAdmin), KYCController.RejectKYC)
+	}
+
+	apiKeyRoutes := route.Group("/api/api-key", middleware.Authenticate(jwtService, sessionService), middleware.RequireRole(entities.RoleAdmin))
+	{
+		apiKeyRoutes.POST("", APIKeyController.CreateAPIKey)
+		apiKeyRoutes.GET("", APIKeyController.GetAllAPIKey)
+		apiKeyRoutes.DELETE("/:id", APIKeyController.RevokeAPIKey)
+	}
+
+	route.POST("/api/asset", middleware.Authenticate(jwtService, sessionService), AssetController.UploadAsset)
+	if services.UsesLocalStorage() {
+		route.GET(services.LocalStorageRoute+"/*key", AssetController.ServeFile)
 	}
 }
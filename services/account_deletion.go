@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AccountDeletionService interface {
+	RequestDeletion(ctx context.Context, userID uuid.UUID, deletionDTO dto.AccountDeletionRequestDTO, reauthenticated bool) (dto.AccountDeletionResponse, error)
+	CancelDeletion(ctx context.Context, userID uuid.UUID) error
+	GetHandoverOffers(ctx context.Context, userID uuid.UUID) ([]dto.CampaignHandoverResponse, error)
+	AcceptHandover(ctx context.Context, userID uuid.UUID, handoverID uuid.UUID) error
+	RejectHandover(ctx context.Context, userID uuid.UUID, handoverID uuid.UUID) error
+	ProcessDueDeletions(ctx context.Context) (int, error)
+}
+
+var (
+	ErrDeletionAlreadyScheduled  = errors.New("Penghapusan Akun Sudah Dijadwalkan")
+	ErrNoPendingDeletion         = errors.New("Tidak Ada Penghapusan Akun Yang Dijadwalkan")
+	ErrHandoverNotFound          = errors.New("Tawaran Penyerahan Campaign Tidak Ditemukan")
+	ErrHandoverAlreadyAccepted   = errors.New("Tawaran Penyerahan Campaign Sudah Diterima")
+	ErrHandoverRecipientInvalid  = errors.New("Penerima Campaign Tidak Valid")
+	ErrHandoverRecipientNoKYC    = errors.New("Penerima Campaign Belum Lolos KYC")
+	ErrHandoverRecipientNotFound = errors.New("Penerima Campaign Tidak Ditemukan")
+)
+
+// ActiveCampaignError dikembalikan ketika user masih memiliki campaign aktif
+// dan tidak menunjuk penerima campaign.
+type ActiveCampaignError struct {
+	Events []dto.AccountDeletionCampaign
+}
+
+func (e *ActiveCampaignError) Error() string {
+	return "Masih Ada Campaign Aktif, Selesaikan Atau Serahkan Ke Campaigner Lain"
+}
+
+const accountDeletionBatchSize = 100
+
+type accountDeletionService struct {
+	userRepository     repository.UserRepository
+	eventRepository    repository.EventRepository
+	handoverRepository repository.CampaignHandoverRepository
+	kycService         KYCService
+	jwtService         JWTService
+	mailer             Mailer
+	gracePeriod        time.Duration
+}
+
+func NewAccountDeletionService(ur repository.UserRepository, er repository.EventRepository, hr repository.CampaignHandoverRepository, ks KYCService, jwt JWTService, mailer Mailer) AccountDeletionService {
+	return &accountDeletionService{
+		userRepository:     ur,
+		eventRepository:    er,
+		handoverRepository: hr,
+		kycService:         ks,
+		jwtService:         jwt,
+		mailer:             mailer,
+		gracePeriod:        getAccountDeletionGracePeriod(),
+	}
+}
+
+func getAccountDeletionGracePeriod() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_DAYS"))
+	if err != nil || days < 0 {
+		days = 14
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func toAccountDeletionCampaigns(events []entities.Event) []dto.AccountDeletionCampaign {
+	campaigns := make([]dto.AccountDeletionCampaign, 0, len(events))
+	for _, event := range events {
+		campaigns = append(campaigns, dto.AccountDeletionCampaign{
+			ID:            event.ID,
+			JudulEvent:    event.JudulEvent,
+			SisaDonasi:    event.SisaDonasi,
+			ExpiredDonasi: event.ExpiredDonasi,
+		})
+	}
+	return campaigns
+}
+
+func (ads *accountDeletionService) RequestDeletion(ctx context.Context, userID uuid.UUID, deletionDTO dto.AccountDeletionRequestDTO, reauthenticated bool) (dto.AccountDeletionResponse, error) {
+	user, err := ads.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return dto.AccountDeletionResponse{}, err
+	}
+	if user.DeletionScheduledAt != nil {
+		return dto.AccountDeletionResponse{}, ErrDeletionAlreadyScheduled
+	}
+
+	if !reauthenticated {
+		if ok, _ := helpers.CheckPassword(user.Password, []byte(deletionDTO.Password)); !ok {
+			return dto.AccountDeletionResponse{}, errors.New("Password Saat Ini Salah")
+		}
+	}
+
+	now := time.Now()
+	activeEvents, err := ads.eventRepository.GetActiveEventsByUserID(ctx, userID)
+	if err != nil {
+		return dto.AccountDeletionResponse{}, err
+	}
+
+	res := dto.AccountDeletionResponse{}
+	var recipient entities.User
+	if len(activeEvents) > 0 {
+		if deletionDTO.HandoverEmail == "" {
+			return dto.AccountDeletionResponse{}, &ActiveCampaignError{Events: toAccountDeletionCampaigns(activeEvents)}
+		}
+		recipient, err = ads.userRepository.GetUserByEmail(ctx, deletionDTO.HandoverEmail)
+		if err != nil {
+			return dto.AccountDeletionResponse{}, ErrHandoverRecipientNotFound
+		}
+		if err := ads.checkHandoverRecipient(ctx, userID, recipient); err != nil {
+			return dto.AccountDeletionResponse{}, err
+		}
+		// Campaign belum dipindahkan sampai penerima menyetujui dan masa
+		// tenggang berakhir, sehingga pembatalan penghapusan akun cukup
+		// menghapus tawaran ini.
+		if err := ads.handoverRepository.SaveHandoverOffer(ctx, entities.CampaignHandover{
+			FromUserID: userID,
+			ToUserID:   recipient.ID,
+			Timestamp:  entities.Timestamp{CreatedAt: now, UpdatedAt: now},
+		}); err != nil {
+			return dto.AccountDeletionResponse{}, err
+		}
+		res.HandoverEmail = recipient.Email
+		res.HandoverEvents = toAccountDeletionCampaigns(activeEvents)
+	}
+
+	scheduledAt := now.Add(ads.gracePeriod)
+	if err := ads.userRepository.ScheduleDeletion(ctx, userID, scheduledAt); err != nil {
+		return dto.AccountDeletionResponse{}, err
+	}
+	res.DeletionScheduledAt = scheduledAt
+
+	body := fmt.Sprintf("Akun Anda dijadwalkan untuk dihapus pada %s. Login dan batalkan penghapusan sebelum tanggal tersebut jika permintaan ini bukan dari Anda.", scheduledAt.Format("02 January 2006 15:04 MST"))
+	if err := ads.mailer.Send(ctx, user.Email, "Penghapusan Akun", body); err != nil {
+		log.Printf("error sending account deletion email to user %s: %v", user.ID, err)
+	}
+	if res.HandoverEmail != "" {
+		body := fmt.Sprintf("%s menawarkan %d campaign untuk Anda kelola karena akunnya akan dihapus pada %s. Login untuk menerima atau menolak tawaran tersebut.", user.Nama, len(activeEvents), scheduledAt.Format("02 January 2006 15:04 MST"))
+		if err := ads.mailer.Send(ctx, recipient.Email, "Tawaran Penyerahan Campaign", body); err != nil {
+			log.Printf("error sending campaign handover email to user %s: %v", recipient.ID, err)
+		}
+	}
+	return res, nil
+}
+
+// checkHandoverRecipient memastikan penerima masih boleh mengelola campaign.
+// Error selain ErrHandoverRecipientInvalid dan ErrHandoverRecipientNoKYC
+// berasal dari database.
+func (ads *accountDeletionService) checkHandoverRecipient(ctx context.Context, fromUserID uuid.UUID, recipient entities.User) error {
+	if recipient.ID == fromUserID || recipient.DeletionScheduledAt != nil || recipient.AnonymizedAt != nil {
+		return ErrHandoverRecipientInvalid
+	}
+	if _, err := ads.kycService.GetApprovedIdentity(ctx, recipient.ID); err != nil {
+		if errors.Is(err, ErrKYCNotApproved) {
+			return ErrHandoverRecipientNoKYC
+		}
+		return err
+	}
+	return nil
+}
+
+func (ads *accountDeletionService) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	cancelled, err := ads.userRepository.CancelDeletion(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrNoPendingDeletion
+	}
+	return ads.handoverRepository.DeleteHandoverByFromUserID(ctx, userID)
+}
+
+func (ads *accountDeletionService) GetHandoverOffers(ctx context.Context, userID uuid.UUID) ([]dto.CampaignHandoverResponse, error) {
+	handovers, err := ads.handoverRepository.GetHandoversByToUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.CampaignHandoverResponse, 0, len(handovers))
+	for _, handover := range handovers {
+		events, err := ads.eventRepository.GetActiveEventsByUserID(ctx, handover.FromUserID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, dto.CampaignHandoverResponse{
+			ID:                  handover.ID,
+			FromNama:            handover.FromUser.Nama,
+			DeletionScheduledAt: handover.FromUser.DeletionScheduledAt,
+			AcceptedAt:          handover.AcceptedAt,
+			Events:              toAccountDeletionCampaigns(events),
+			CreatedAt:           handover.CreatedAt,
+		})
+	}
+	return res, nil
+}
+
+func (ads *accountDeletionService) AcceptHandover(ctx context.Context, userID uuid.UUID, handoverID uuid.UUID) error {
+	handover, err := ads.getHandoverOffer(ctx, userID, handoverID)
+	if err != nil {
+		return err
+	}
+	if handover.AcceptedAt != nil {
+		return ErrHandoverAlreadyAccepted
+	}
+
+	recipient, err := ads.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := ads.checkHandoverRecipient(ctx, handover.FromUserID, recipient); err != nil {
+		return err
+	}
+
+	accepted, err := ads.handoverRepository.AcceptHandover(ctx, handoverID, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		return ErrHandoverNotFound
+	}
+	return nil
+}
+
+func (ads *accountDeletionService) RejectHandover(ctx context.Context, userID uuid.UUID, handoverID uuid.UUID) error {
+	handover, err := ads.getHandoverOffer(ctx, userID, handoverID)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := ads.handoverRepository.DeleteHandover(ctx, handoverID, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrHandoverNotFound
+	}
+
+	// Pemilik campaign perlu tahu agar bisa menunjuk penerima lain, karena
+	// penghapusan akunnya tertunda selama campaign masih aktif
+	body := "Penerima menolak tawaran penyerahan campaign Anda. Penghapusan akun akan ditunda sampai campaign selesai atau diserahkan ke campaigner lain."
+	if err := ads.mailer.Send(ctx, handover.FromUser.Email, "Tawaran Penyerahan Campaign Ditolak", body); err != nil {
+		log.Printf("error sending campaign handover rejection email to user %s: %v", handover.FromUserID, err)
+	}
+	return nil
+}
+
+// getHandoverOffer menganggap tawaran untuk user lain tidak ada.
+func (ads *accountDeletionService) getHandoverOffer(ctx context.Context, userID uuid.UUID, handoverID uuid.UUID) (entities.CampaignHandover, error) {
+	handover, err := ads.handoverRepository.GetHandoverByID(ctx, handoverID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.CampaignHandover{}, ErrHandoverNotFound
+		}
+		return entities.CampaignHandover{}, err
+	}
+	if handover.ToUserID != userID {
+		return entities.CampaignHandover{}, ErrHandoverNotFound
+	}
+	return handover, nil
+}
+
+// applyHandover memindahkan campaign aktif ke penerima yang sudah menyetujui
+// tawaran. Hanya nama dan data pekerjaan penerima yang disalin ke event, NIK
+// dan nomor telepon tidak ikut disalin. Tawaran dicabut jika penerima tidak
+// lagi memenuhi syarat, agar akun tidak terus diproses ulang oleh job.
+func (ads *accountDeletionService) applyHandover(ctx context.Context, user entities.User) error {
+	handover, err := ads.handoverRepository.GetHandoverByFromUserID(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if handover.AcceptedAt == nil {
+		return nil
+	}
+
+	recipient, err := ads.userRepository.GetUserByID(ctx, handover.ToUserID)
+	if err != nil {
+		return err
+	}
+	if err := ads.checkHandoverRecipient(ctx, user.ID, recipient); err != nil {
+		if errors.Is(err, ErrHandoverRecipientInvalid) || errors.Is(err, ErrHandoverRecipientNoKYC) {
+			log.Printf("revoking campaign handover of user %s: %v", user.ID, err)
+			return ads.handoverRepository.DeleteHandoverByFromUserID(ctx, user.ID)
+		}
+		return err
+	}
+	identity, err := ads.kycService.GetApprovedIdentity(ctx, recipient.ID)
+	if err != nil {
+		return err
+	}
+
+	events, err := ads.eventRepository.GetActiveEventsByUserID(ctx, user.ID)
+	if err != nil || len(events) == 0 {
+		return err
+	}
+	eventIDs := make([]uuid.UUID, 0, len(events))
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
+	}
+	return ads.eventRepository.TransferEvents(ctx, user.ID, eventIDs, entities.Event{
+		UserID:           recipient.ID,
+		NamaDepanPembuat: recipient.Nama,
+		Pekerjaan:        identity.Pekerjaan,
+		AsalInstansi:     identity.AsalInstansi,
+	})
+}
+
+// ProcessDueDeletions menganonimkan akun yang masa tenggangnya sudah habis.
+// Campaign aktif diserahkan ke penerima yang sudah menyetujui tawaran. Akun
+// yang masih memiliki campaign aktif dilewati sampai campaign tersebut
+// selesai.
+func (ads *accountDeletionService) ProcessDueDeletions(ctx context.Context) (int, error) {
+	now := time.Now()
+	users, err := ads.userRepository.GetUsersDueForDeletion(ctx, now, accountDeletionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, user := range users {
+		if err := ads.applyHandover(ctx, user); err != nil {
+			return processed, err
+		}
+		activeEvents, err := ads.eventRepository.GetActiveEventsByUserID(ctx, user.ID)
+		if err != nil {
+			return processed, err
+		}
+		if len(activeEvents) > 0 {
+			log.Printf("postponing deletion of user %s: %d active campaign", user.ID, len(activeEvents))
+			continue
+		}
+
+		// Event yang belum pernah tayang tidak punya donasi, cukup dibatalkan
+		if err := ads.eventRepository.CancelEventsByUserID(ctx, user.ID, entities.EventDeletableStatuses, entities.EventStatusHistory{
+			ToStatus:  entities.EventStatusCancelled,
+			Reason:    "Akun pembuat campaign dihapus",
+			CreatedAt: now,
+		}); err != nil {
+			return processed, err
+		}
+
+		anonymized, kycFiles, err := ads.userRepository.AnonymizeUser(ctx, user.ID, now)
+		if err != nil {
+			return processed, err
+		}
+		if !anonymized {
+			continue
+		}
+		removeKYCFiles(kycFiles)
+		if err := ads.jwtService.InvalidateAllUserToken(user.ID); err != nil {
+			log.Printf("error revoking token of deleted user %s: %v", user.ID, err)
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// removeKYCFiles menghapus dokumen KYC milik user yang sudah dianonimkan
+// beserta foldernya jika sudah kosong. Kegagalan hanya dicatat karena data
+// user di database sudah terhapus.
+func removeKYCFiles(files []string) {
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("error removing kyc file %s: %v", file, err)
+			continue
+		}
+		os.Remove(filepath.Dir(file))
+	}
+}
+
+// NewAccountDeletionJob menjalankan ProcessDueDeletions secara berkala.
+func NewAccountDeletionJob(accountDeletionService AccountDeletionService, interval time.Duration) Job {
+	return Job{
+		Name:     "account_deletion",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			processed, err := accountDeletionService.ProcessDueDeletions(ctx)
+			if err != nil {
+				return err
+			}
+			if processed > 0 {
+				log.Printf("anonymized %d account", processed)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (fr *fakeUserRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]entities.User, error) {
+	var users []entities.User
+	for _, user := range fr.users {
+		if user.DeletionScheduledAt != nil && !user.DeletionScheduledAt.After(now) && user.AnonymizedAt == nil {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (fr *fakeUserRepository) AnonymizeUser(ctx context.Context, userID uuid.UUID, now time.Time) (bool, []string, error) {
+	user := fr.users[userID]
+	if user.AnonymizedAt != nil {
+		return false, nil, nil
+	}
+	user.Nama = "Pengguna Terhapus"
+	user.Email = "deleted+" + userID.String() + "@deleted.invalid"
+	user.AnonymizedAt = &now
+	user.DeletionScheduledAt = nil
+	fr.users[userID] = user
+	return true, fr.kycFiles[userID], nil
+}
+
+// fakeDeletionEventRepository menyimpan event aktif per user.
+type fakeDeletionEventRepository struct {
+	repository.EventRepository
+	active map[uuid.UUID][]entities.Event
+}
+
+func (fr *fakeDeletionEventRepository) GetActiveEventsByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Event, error) {
+	return fr.active[userID], nil
+}
+
+func (fr *fakeDeletionEventRepository) CancelEventsByUserID(ctx context.Context, userID uuid.UUID, statuses []string, history entities.EventStatusHistory) error {
+	return nil
+}
+
+// fakeCampaignHandoverRepository tidak memiliki tawaran penyerahan apa pun.
+type fakeCampaignHandoverRepository struct {
+	repository.CampaignHandoverRepository
+}
+
+func (fr *fakeCampaignHandoverRepository) GetHandoverByFromUserID(ctx context.Context, fromUserID uuid.UUID) (entities.CampaignHandover, error) {
+	return entities.CampaignHandover{}, gorm.ErrRecordNotFound
+}
+
+func TestProcessDueDeletionsScrubsUser(t *testing.T) {
+	dir := t.TempDir()
+	past := time.Now().Add(-time.Hour)
+	deleted := entities.User{ID: uuid.New(), Nama: "Hapus", Email: "hapus@mail.com", DeletionScheduledAt: &past}
+	postponed := entities.User{ID: uuid.New(), Nama: "Tunda", Email: "tunda@mail.com", DeletionScheduledAt: &past}
+
+	// Dokumen KYC disimpan per user seperti kycService.saveDocument
+	userDir := filepath.Join(dir, deleted.ID.String())
+	if err := os.MkdirAll(userDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	ktp := filepath.Join(userDir, "ktp.jpg")
+	selfie := filepath.Join(userDir, "selfie.jpg")
+	for _, file := range []string{ktp, selfie} {
+		if err := os.WriteFile(file, []byte("dokumen"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	users := newFakeUserRepository(deleted, postponed)
+	users.kycFiles = map[uuid.UUID][]string{deleted.ID: {ktp, selfie}}
+	revoked := newFakeRevokedTokenRepository()
+	service := &accountDeletionService{
+		userRepository:     users,
+		eventRepository:    &fakeDeletionEventRepository{active: map[uuid.UUID][]entities.Event{postponed.ID: {{ID: uuid.New(), UserID: postponed.ID}}}},
+		handoverRepository: &fakeCampaignHandoverRepository{},
+		jwtService:         NewJWTService(revoked),
+	}
+
+	processed, err := service.ProcessDueDeletions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed != 1 {
+		t.Fatalf("processed = %d, want 1", processed)
+	}
+
+	if got := users.users[deleted.ID]; got.AnonymizedAt == nil || got.Email == deleted.Email {
+		t.Error("user tidak dianonimkan")
+	}
+	if _, ok := revoked.revokedBefore[deleted.ID]; !ok {
+		t.Error("token user yang dihapus tidak dicabut")
+	}
+	if _, err := os.Stat(userDir); !os.IsNotExist(err) {
+		t.Errorf("dokumen KYC masih tersimpan di disk: %v", err)
+	}
+
+	// User dengan campaign aktif ditunda tanpa mengubah datanya
+	if got := users.users[postponed.ID]; got.AnonymizedAt != nil || got.Email != postponed.Email {
+		t.Error("user dengan campaign aktif ikut dianonimkan")
+	}
+	if _, ok := revoked.revokedBefore[postponed.ID]; ok {
+		t.Error("token user yang ditunda ikut dicabut")
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+)
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, creatorID uuid.UUID, apiKeyDTO dto.APIKeyCreateDTO) (dto.APIKeyCreatedResponse, error)
+	GetAllAPIKey(ctx context.Context) ([]dto.APIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, apiKeyID uuid.UUID) error
+	ValidateAPIKey(ctx context.Context, key string, ip string) (entities.APIKey, error)
+}
+
+const (
+	APIKeyHeader        = "X-API-Key"
+	apiKeyPrefix        = "fdl"
+	apiKeyTouchInterval = time.Minute
+)
+
+var ErrInvalidAPIKey = errors.New("API Key Tidak Valid")
+
+type apiKeyService struct {
+	apiKeyRepository repository.APIKeyRepository
+}
+
+func NewAPIKeyService(ar repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepository: ar,
+	}
+}
+
+// CreateAPIKey membuat key dengan format fdl_<prefix>_<secret>. Prefix dipakai
+// untuk mencari key di database, sedangkan key lengkap hanya disimpan hash-nya.
+func (as *apiKeyService) CreateAPIKey(ctx context.Context, creatorID uuid.UUID, apiKeyDTO dto.APIKeyCreateDTO) (dto.APIKeyCreatedResponse, error) {
+	for _, scope := range apiKeyDTO.Scopes {
+		if !entities.IsValidScope(scope) {
+			return dto.APIKeyCreatedResponse{}, fmt.Errorf("Scope %s Tidak Dikenali", scope)
+		}
+	}
+	if apiKeyDTO.ExpiresAt != nil && apiKeyDTO.ExpiresAt.Before(time.Now()) {
+		return dto.APIKeyCreatedResponse{}, errors.New("Tanggal Kadaluarsa Sudah Lewat")
+	}
+
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return dto.APIKeyCreatedResponse{}, err
+	}
+	prefix := hex.EncodeToString(prefixBytes)
+
+	secret, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return dto.APIKeyCreatedResponse{}, err
+	}
+	key := apiKeyPrefix + "_" + prefix + "_" + secret
+
+	apiKey, err := as.apiKeyRepository.CreateAPIKey(ctx, entities.APIKey{
+		Name:      apiKeyDTO.Name,
+		Prefix:    prefix,
+		KeyHash:   helpers.HashToken(key),
+		Scopes:    strings.Join(apiKeyDTO.Scopes, ","),
+		ExpiresAt: apiKeyDTO.ExpiresAt,
+		CreatedBy: creatorID,
+	})
+	if err != nil {
+		return dto.APIKeyCreatedResponse{}, err
+	}
+
+	return dto.APIKeyCreatedResponse{
+		APIKeyResponse: toAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (as *apiKeyService) GetAllAPIKey(ctx context.Context) ([]dto.APIKeyResponse, error) {
+	apiKeys, err := as.apiKeyRepository.GetAllAPIKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.APIKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		res = append(res, toAPIKeyResponse(apiKey))
+	}
+	return res, nil
+}
+
+func (as *apiKeyService) RevokeAPIKey(ctx context.Context, apiKeyID uuid.UUID) error {
+	revoked, err := as.apiKeyRepository.RevokeAPIKey(ctx, apiKeyID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.New("API Key Tidak Ditemukan Atau Sudah Dicabut")
+	}
+	return nil
+}
+
+func (as *apiKeyService) ValidateAPIKey(ctx context.Context, key string, ip string) (entities.APIKey, error) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return entities.APIKey{}, ErrInvalidAPIKey
+	}
+
+	apiKey, err := as.apiKeyRepository.GetAPIKeyByPrefix(ctx, parts[1])
+	if err != nil {
+		return entities.APIKey{}, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(helpers.HashToken(key))) != 1 {
+		return entities.APIKey{}, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(now)) {
+		return entities.APIKey{}, ErrInvalidAPIKey
+	}
+
+	if err := as.apiKeyRepository.TouchAPIKey(ctx, apiKey.ID, ip, now, apiKeyTouchInterval); err != nil {
+		return entities.APIKey{}, err
+	}
+	return apiKey, nil
+}
+
+func toAPIKeyResponse(apiKey entities.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.ScopeList(),
+		ExpiresAt:  apiKey.ExpiresAt,
+		RevokedAt:  apiKey.RevokedAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		LastUsedIP: apiKey.LastUsedIP,
+		CreatedBy:  apiKey.CreatedBy,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
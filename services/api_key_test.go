@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeAPIKeyRepository menyimpan API key di memori berdasarkan prefix-nya.
+type fakeAPIKeyRepository struct {
+	repository.APIKeyRepository
+	keys    map[string]entities.APIKey
+	touched int
+}
+
+func (fr *fakeAPIKeyRepository) CreateAPIKey(ctx context.Context, apiKey entities.APIKey) (entities.APIKey, error) {
+	apiKey.ID = uuid.New()
+	fr.keys[apiKey.Prefix] = apiKey
+	return apiKey, nil
+}
+
+func (fr *fakeAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (entities.APIKey, error) {
+	apiKey, ok := fr.keys[prefix]
+	if !ok {
+		return entities.APIKey{}, gorm.ErrRecordNotFound
+	}
+	return apiKey, nil
+}
+
+func (fr *fakeAPIKeyRepository) RevokeAPIKey(ctx context.Context, apiKeyID uuid.UUID) (bool, error) {
+	for prefix, apiKey := range fr.keys {
+		if apiKey.ID == apiKeyID && apiKey.RevokedAt == nil {
+			now := time.Now()
+			apiKey.RevokedAt = &now
+			fr.keys[prefix] = apiKey
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (fr *fakeAPIKeyRepository) TouchAPIKey(ctx context.Context, apiKeyID uuid.UUID, ip string, now time.Time, interval time.Duration) error {
+	fr.touched++
+	return nil
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	repo := &fakeAPIKeyRepository{keys: map[string]entities.APIKey{}}
+	service := NewAPIKeyService(repo)
+	ctx := context.Background()
+
+	created, err := service.CreateAPIKey(ctx, uuid.New(), dto.APIKeyCreateDTO{Name: "service", Scopes: []string{entities.ScopeEventsRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(created.Key, apiKeyPrefix+"_"+created.Prefix+"_") {
+		t.Fatalf("format key = %q", created.Key)
+	}
+	if repo.keys[created.Prefix].KeyHash == created.Key {
+		t.Fatal("key disimpan tanpa di-hash")
+	}
+
+	apiKey, err := service.ValidateAPIKey(ctx, created.Key, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("ValidateAPIKey: %v", err)
+	}
+	if !apiKey.HasScope(entities.ScopeEventsRead) || apiKey.HasScope(entities.ScopeTransaksiRead) {
+		t.Errorf("scope = %v", apiKey.ScopeList())
+	}
+	if repo.touched != 1 {
+		t.Errorf("pemakaian tidak dicatat: touched = %d", repo.touched)
+	}
+
+	invalid := []string{
+		"",
+		"bukan-api-key",
+		created.Key + "x",
+		strings.Replace(created.Key, apiKeyPrefix+"_", "abc_", 1),
+		apiKeyPrefix + "_00000000_" + strings.SplitN(created.Key, "_", 3)[2],
+	}
+	for _, key := range invalid {
+		if _, err := service.ValidateAPIKey(ctx, key, "10.0.0.1"); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("ValidateAPIKey(%q): err = %v, want ErrInvalidAPIKey", key, err)
+		}
+	}
+
+	if err := service.RevokeAPIKey(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ValidateAPIKey(ctx, created.Key, "10.0.0.1"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("key yang dicabut: err = %v, want ErrInvalidAPIKey", err)
+	}
+	if repo.touched != 1 {
+		t.Errorf("key yang tidak valid ikut dicatat: touched = %d", repo.touched)
+	}
+}
+
+func TestValidateAPIKeyExpired(t *testing.T) {
+	repo := &fakeAPIKeyRepository{keys: map[string]entities.APIKey{}}
+	service := NewAPIKeyService(repo)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour)
+	created, err := service.CreateAPIKey(ctx, uuid.New(), dto.APIKeyCreateDTO{Name: "service", Scopes: []string{entities.ScopeEventsRead}, ExpiresAt: &expiresAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiKey := repo.keys[created.Prefix]
+	past := time.Now().Add(-time.Minute)
+	apiKey.ExpiresAt = &past
+	repo.keys[created.Prefix] = apiKey
+	if _, err := service.ValidateAPIKey(ctx, created.Key, "10.0.0.1"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("key kadaluarsa: err = %v, want ErrInvalidAPIKey", err)
+	}
+}
+
+func TestCreateAPIKeyRejectsInvalidInput(t *testing.T) {
+	service := NewAPIKeyService(&fakeAPIKeyRepository{keys: map[string]entities.APIKey{}})
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		apiKeyDTO dto.APIKeyCreateDTO
+	}{
+		{"scope tidak dikenal", dto.APIKeyCreateDTO{Name: "service", Scopes: []string{"users:write"}}},
+		{"kadaluarsa di masa lalu", dto.APIKeyCreateDTO{Name: "service", Scopes: []string{entities.ScopeEventsRead}, ExpiresAt: &past}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CreateAPIKey(context.Background(), uuid.New(), tt.apiKeyDTO); err == nil {
+				t.Error("CreateAPIKey menerima input yang tidak valid")
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AssetService interface {
+	UploadImage(ctx context.Context, userID uuid.UUID, file *multipart.FileHeader) (entities.Asset, error)
+	GetOwnedAsset(ctx context.Context, actor Actor, assetID uuid.UUID) (entities.Asset, error)
+	OpenFile(ctx context.Context, key string) (io.ReadCloser, error)
+	DeleteOrphanAssets(ctx context.Context) (int, error)
+	MaxUploadSize() int64
+}
+
+var (
+	ErrAssetFileRequired = errors.New("File Gambar Wajib Diunggah")
+	ErrAssetTooLarge     = errors.New("Ukuran File Terlalu Besar")
+	ErrAssetNotFound     = errors.New("Gambar Tidak Ditemukan")
+	ErrAssetQuotaFull    = errors.New("Kuota Penyimpanan Gambar Sudah Penuh")
+)
+
+// orphanAssetBatch membatasi jumlah asset yang dihapus dalam satu kali job.
+const orphanAssetBatch = 100
+
+type assetService struct {
+	assetRepository repository.AssetRepository
+	storage         Storage
+	maxUploadSize   int64
+	thumbnailSize   int
+	quota           int64
+	orphanAge       time.Duration
+}
+
+func NewAssetService(ar repository.AssetRepository, storage Storage) AssetService {
+	return &assetService{
+		assetRepository: ar,
+		storage:         storage,
+		maxUploadSize:   int64(helpers.GetEnvInt("ASSET_MAX_UPLOAD_MB", 5)) << 20,
+		thumbnailSize:   helpers.GetEnvInt("ASSET_THUMBNAIL_SIZE", 320),
+		quota:           int64(helpers.GetEnvInt("ASSET_QUOTA_MB", 100)) << 20,
+		orphanAge:       time.Duration(helpers.GetEnvInt("ASSET_ORPHAN_HOURS", 24)) * time.Hour,
+	}
+}
+
+func (as *assetService) MaxUploadSize() int64 {
+	return as.maxUploadSize
+}
+
+// UploadImage memvalidasi gambar, membuang metadata EXIF, membuat thumbnail
+// lalu menyimpan keduanya ke storage. Total ukuran gambar milik user dibatasi
+// oleh ASSET_QUOTA_MB.
+func (as *assetService) UploadImage(ctx context.Context, userID uuid.UUID, file *multipart.FileHeader) (entities.Asset, error) {
+	if file == nil {
+		return entities.Asset{}, ErrAssetFileRequired
+	}
+	if file.Size > as.maxUploadSize {
+		return entities.Asset{}, fmt.Errorf("%w, Maksimal %dMB", ErrAssetTooLarge, as.maxUploadSize>>20)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return entities.Asset{}, err
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, as.maxUploadSize+1))
+	if err != nil {
+		return entities.Asset{}, err
+	}
+	if int64(len(data)) > as.maxUploadSize {
+		return entities.Asset{}, fmt.Errorf("%w, Maksimal %dMB", ErrAssetTooLarge, as.maxUploadSize>>20)
+	}
+
+	image, err := helpers.ProcessImage(data, as.thumbnailSize)
+	if err != nil {
+		return entities.Asset{}, err
+	}
+
+	// Pemeriksaan awal agar file tidak sempat diunggah ke storage, quota
+	// tetap diperiksa ulang saat asset disimpan
+	usage, err := as.assetRepository.GetAssetUsage(ctx, userID)
+	if err != nil {
+		return entities.Asset{}, err
+	}
+	if usage+int64(len(image.Data)) > as.quota {
+		return entities.Asset{}, as.quotaError()
+	}
+
+	assetID := uuid.New()
+	key := fmt.Sprintf("assets/%s/%s%s", userID, assetID, image.Extension)
+	thumbnailKey := fmt.Sprintf("assets/%s/%s_thumb%s", userID, assetID, image.Extension)
+
+	if err := as.storage.Put(ctx, key, image.Data, image.ContentType); err != nil {
+		return entities.Asset{}, err
+	}
+	if err := as.storage.Put(ctx, thumbnailKey, image.Thumbnail, image.ContentType); err != nil {
+		as.storage.Delete(ctx, key)
+		return entities.Asset{}, err
+	}
+
+	asset := entities.Asset{
+		ID:           assetID,
+		Key:          key,
+		ThumbnailKey: thumbnailKey,
+		URL:          as.storage.URL(key),
+		ThumbnailURL: as.storage.URL(thumbnailKey),
+		ContentType:  image.ContentType,
+		Size:         int64(len(image.Data)),
+		Width:        image.Width,
+		Height:       image.Height,
+		UserID:       userID,
+	}
+	created, err := as.assetRepository.CreateAsset(ctx, &asset, as.quota)
+	if err != nil || !created {
+		// File yang tidak tercatat di database tidak akan pernah dipakai
+		as.storage.Delete(ctx, key)
+		as.storage.Delete(ctx, thumbnailKey)
+		if err != nil {
+			return entities.Asset{}, err
+		}
+		return entities.Asset{}, as.quotaError()
+	}
+	return asset, nil
+}
+
+func (as *assetService) quotaError() error {
+	return fmt.Errorf("%w, Maksimal %dMB", ErrAssetQuotaFull, as.quota>>20)
+}
+
+// GetOwnedAsset hanya mengembalikan asset milik actor, kecuali untuk admin.
+// Asset milik user lain dianggap tidak ada agar ID-nya tidak bisa ditebak.
+func (as *assetService) GetOwnedAsset(ctx context.Context, actor Actor, assetID uuid.UUID) (entities.Asset, error) {
+	asset, err := as.assetRepository.GetAssetByID(ctx, assetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Asset{}, ErrAssetNotFound
+		}
+		return entities.Asset{}, err
+	}
+	if asset.UserID != actor.UserID && !actor.IsAdmin() {
+		return entities.Asset{}, ErrAssetNotFound
+	}
+	return asset, nil
+}
+
+// OpenFile hanya membuka file yang tercatat sebagai asset, sehingga file lain
+// di folder storage tidak ikut tersaji.
+func (as *assetService) OpenFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	if _, err := as.assetRepository.GetAssetByKey(ctx, key); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStorageObjectNotFound
+		}
+		return nil, err
+	}
+	return as.storage.Get(ctx, key)
+}
+
+// DeleteOrphanAssets menghapus asset yang sudah lebih lama dari
+// ASSET_ORPHAN_HOURS tetapi tidak pernah dipakai oleh event maupun kabar
+// terbaru. Baris asset dihapus lebih dulu agar file tidak pernah hilang dari
+// asset yang masih tercatat.
+func (as *assetService) DeleteOrphanAssets(ctx context.Context) (int, error) {
+	assets, err := as.assetRepository.GetOrphanAssets(ctx, time.Now().Add(-as.orphanAge), orphanAssetBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, asset := range assets {
+		ok, err := as.assetRepository.DeleteOrphanAsset(ctx, asset.ID)
+		if err != nil {
+			return deleted, err
+		}
+		if !ok {
+			continue
+		}
+		deleted++
+		if err := as.storage.Delete(ctx, asset.Key); err != nil {
+			log.Printf("failed to delete file %s: %v", asset.Key, err)
+		}
+		if err := as.storage.Delete(ctx, asset.ThumbnailKey); err != nil {
+			log.Printf("failed to delete file %s: %v", asset.ThumbnailKey, err)
+		}
+	}
+	return deleted, nil
+}
+
+// NewAssetCleanupJob menjalankan DeleteOrphanAssets secara berkala.
+func NewAssetCleanupJob(assetService AssetService, interval time.Duration) Job {
+	return Job{
+		Name:     "asset_cleanup",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			deleted, err := assetService.DeleteOrphanAssets(ctx)
+			if err != nil {
+				return err
+			}
+			if deleted > 0 {
+				log.Printf("deleted %d orphan asset", deleted)
+			}
+			return nil
+		},
+	}
+}
+
+func ToAssetResponse(asset entities.Asset) dto.AssetResponse {
+	return dto.AssetResponse{
+		ID:           asset.ID,
+		URL:          asset.URL,
+		ThumbnailURL: asset.ThumbnailURL,
+		ContentType:  asset.ContentType,
+		Size:         asset.Size,
+		Width:        asset.Width,
+		Height:       asset.Height,
+		CreatedAt:    asset.CreatedAt,
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeAssetRepository menyimpan asset di memori dan menerapkan quota dengan
+// cara yang sama seperti repository aslinya.
+type fakeAssetRepository struct {
+	repository.AssetRepository
+	assets []entities.Asset
+}
+
+func (fr *fakeAssetRepository) CreateAsset(ctx context.Context, asset *entities.Asset, quota int64) (bool, error) {
+	usage, _ := fr.GetAssetUsage(ctx, asset.UserID)
+	if usage+asset.Size > quota {
+		return false, nil
+	}
+	fr.assets = append(fr.assets, *asset)
+	return true, nil
+}
+
+func (fr *fakeAssetRepository) GetAssetByKey(ctx context.Context, key string) (entities.Asset, error) {
+	for _, asset := range fr.assets {
+		if asset.Key == key || asset.ThumbnailKey == key {
+			return asset, nil
+		}
+	}
+	return entities.Asset{}, gorm.ErrRecordNotFound
+}
+
+func (fr *fakeAssetRepository) GetAssetUsage(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var usage int64
+	for _, asset := range fr.assets {
+		if asset.UserID == userID {
+			usage += asset.Size
+		}
+	}
+	return usage, nil
+}
+
+func newTestAssetService(t *testing.T, quota int64) (*assetService, *fakeAssetRepository) {
+	t.Helper()
+	repo := &fakeAssetRepository{}
+	return &assetService{
+		assetRepository: repo,
+		storage:         NewLocalStorage(t.TempDir(), "http://localhost/uploads"),
+		maxUploadSize:   1 << 20,
+		thumbnailSize:   16,
+		quota:           quota,
+	}, repo
+}
+
+// multipartImage membuat file upload berisi gambar PNG berukuran size x size.
+func multipartImage(t *testing.T, size int) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "foto.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(part, image.NewRGBA(image.Rect(0, 0, size, size))); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/asset", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+	return req.MultipartForm.File["file"][0]
+}
+
+func TestAssetOpenFileOnlyServesAssets(t *testing.T) {
+	service, _ := newTestAssetService(t, 1<<20)
+	ctx := context.Background()
+
+	asset, err := service.UploadImage(ctx, uuid.New(), multipartImage(t, 32))
+	if err != nil {
+		t.Fatalf("UploadImage: %v", err)
+	}
+	if err := service.storage.Put(ctx, "lain/catatan.txt", []byte("bukan asset"), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{asset.Key, asset.ThumbnailKey} {
+		file, err := service.OpenFile(ctx, key)
+		if err != nil {
+			t.Errorf("OpenFile(%q): %v", key, err)
+			continue
+		}
+		data, _ := io.ReadAll(file)
+		file.Close()
+		if len(data) == 0 {
+			t.Errorf("OpenFile(%q) kosong", key)
+		}
+	}
+	for _, key := range []string{"lain/catatan.txt", "assets", "../" + asset.Key} {
+		if _, err := service.OpenFile(ctx, key); !errors.Is(err, ErrStorageObjectNotFound) {
+			t.Errorf("OpenFile(%q): err = %v, want ErrStorageObjectNotFound", key, err)
+		}
+	}
+}
+
+func TestAssetUploadQuota(t *testing.T) {
+	service, repo := newTestAssetService(t, 0)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	first, err := service.UploadImage(ctx, userID, multipartImage(t, 32))
+	if !errors.Is(err, ErrAssetQuotaFull) {
+		t.Fatalf("UploadImage dengan quota 0: err = %v, want ErrAssetQuotaFull", err)
+	}
+	if first.ID != uuid.Nil || len(repo.assets) != 0 {
+		t.Fatal("asset tetap tersimpan walaupun quota penuh")
+	}
+
+	service.quota = 1 << 20
+	asset, err := service.UploadImage(ctx, userID, multipartImage(t, 32))
+	if err != nil {
+		t.Fatalf("UploadImage: %v", err)
+	}
+
+	// Quota yang tepat habis oleh asset pertama menolak upload berikutnya
+	service.quota = asset.Size
+	if _, err := service.UploadImage(ctx, userID, multipartImage(t, 32)); !errors.Is(err, ErrAssetQuotaFull) {
+		t.Errorf("UploadImage melebihi quota: err = %v, want ErrAssetQuotaFull", err)
+	}
+	if _, err := service.UploadImage(ctx, uuid.New(), multipartImage(t, 32)); err != nil {
+		t.Errorf("quota user lain ikut terpakai: %v", err)
+	}
+	if len(repo.assets) != 2 {
+		t.Errorf("jumlah asset = %d, want 2", len(repo.assets))
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"gorm.io/gorm"
+)
+
+type CategoryService interface {
+	GetCategoryTree(ctx context.Context) ([]entities.CategoryEvent, error)
+	GetAllCategories(ctx context.Context) ([]entities.CategoryEvent, error)
+	CreateCategory(ctx context.Context, categoryDTO dto.CategoryCreateDTO) (entities.CategoryEvent, error)
+	UpdateCategory(ctx context.Context, categoryID uint, categoryDTO dto.CategoryUpdateDTO) (entities.CategoryEvent, error)
+	DeleteCategory(ctx context.Context, categoryID uint) error
+	GetActiveCategory(ctx context.Context, categoryID uint) (entities.CategoryEvent, error)
+}
+
+var (
+	ErrCategoryNotFound      = errors.New("Kategori Event Tidak Ditemukan")
+	ErrCategorySlugTaken     = errors.New("Slug Kategori Sudah Digunakan")
+	ErrCategoryInUse         = errors.New("Kategori Masih Dipakai Event Atau Sub Kategori, Nonaktifkan Saja")
+	ErrCategoryInvalidParent = errors.New("Parent Kategori Tidak Valid")
+)
+
+type categoryService struct {
+	categoryRepository repository.CategoryRepository
+}
+
+func NewCategoryService(cr repository.CategoryRepository) CategoryService {
+	return &categoryService{
+		categoryRepository: cr,
+	}
+}
+
+// GetCategoryTree mengembalikan kategori aktif dalam bentuk pohon. Sub kategori
+// dari kategori yang nonaktif ikut disembunyikan.
+func (cs *categoryService) GetCategoryTree(ctx context.Context) ([]entities.CategoryEvent, error) {
+	categories, err := cs.categoryRepository.GetCategories(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]entities.CategoryEvent)
+	var roots []entities.CategoryEvent
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var attach func(nodes []entities.CategoryEvent) []entities.CategoryEvent
+	attach = func(nodes []entities.CategoryEvent) []entities.CategoryEvent {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+	return attach(roots), nil
+}
+
+func (cs *categoryService) GetAllCategories(ctx context.Context) ([]entities.CategoryEvent, error) {
+	return cs.categoryRepository.GetCategories(ctx, false)
+}
+
+func (cs *categoryService) CreateCategory(ctx context.Context, categoryDTO dto.CategoryCreateDTO) (entities.CategoryEvent, error) {
+	slug := helpers.Slugify(categoryDTO.Slug)
+	if slug == "" {
+		slug = helpers.Slugify(categoryDTO.Nama)
+	}
+	if err := cs.ensureSlugAvailable(ctx, slug, 0); err != nil {
+		return entities.CategoryEvent{}, err
+	}
+
+	if categoryDTO.ParentID != nil {
+		if _, err := cs.getCategory(ctx, *categoryDTO.ParentID); err != nil {
+			return entities.CategoryEvent{}, ErrCategoryInvalidParent
+		}
+	}
+
+	isActive := true
+	if categoryDTO.IsActive != nil {
+		isActive = *categoryDTO.IsActive
+	}
+	return cs.categoryRepository.CreateCategory(ctx, entities.CategoryEvent{
+		Nama:      categoryDTO.Nama,
+		Slug:      slug,
+		Icon:      categoryDTO.Icon,
+		Deskripsi: categoryDTO.Deskripsi,
+		Urutan:    categoryDTO.Urutan,
+		IsActive:  &isActive,
+		ParentID:  categoryDTO.ParentID,
+	})
+}
+
+func (cs *categoryService) UpdateCategory(ctx context.Context, categoryID uint, categoryDTO dto.CategoryUpdateDTO) (entities.CategoryEvent, error) {
+	category, err := cs.getCategory(ctx, categoryID)
+	if err != nil {
+		return entities.CategoryEvent{}, err
+	}
+
+	if categoryDTO.Nama != nil {
+		category.Nama = *categoryDTO.Nama
+	}
+	if categoryDTO.Slug != nil {
+		slug := helpers.Slugify(*categoryDTO.Slug)
+		if slug == "" {
+			slug = helpers.Slugify(category.Nama)
+		}
+		if err := cs.ensureSlugAvailable(ctx, slug, category.ID); err != nil {
+			return entities.CategoryEvent{}, err
+		}
+		category.Slug = slug
+	}
+	if categoryDTO.Icon != nil {
+		category.Icon = *categoryDTO.Icon
+	}
+	if categoryDTO.Deskripsi != nil {
+		category.Deskripsi = *categoryDTO.Deskripsi
+	}
+	if categoryDTO.Urutan != nil {
+		category.Urutan = *categoryDTO.Urutan
+	}
+	if categoryDTO.IsActive != nil {
+		category.IsActive = categoryDTO.IsActive
+	}
+	if categoryDTO.ParentID != nil {
+		if *categoryDTO.ParentID == 0 {
+			category.ParentID = nil
+		} else {
+			// Parent baru tidak boleh kategori itu sendiri maupun turunannya
+			subtree, err := cs.categoryRepository.GetCategorySubtreeIDs(ctx, category.ID)
+			if err != nil {
+				return entities.CategoryEvent{}, err
+			}
+			for _, id := range subtree {
+				if id == *categoryDTO.ParentID {
+					return entities.CategoryEvent{}, ErrCategoryInvalidParent
+				}
+			}
+			if _, err := cs.getCategory(ctx, *categoryDTO.ParentID); err != nil {
+				return entities.CategoryEvent{}, ErrCategoryInvalidParent
+			}
+			category.ParentID = categoryDTO.ParentID
+		}
+	}
+
+	if err := cs.categoryRepository.UpdateCategory(ctx, category); err != nil {
+		return entities.CategoryEvent{}, err
+	}
+	return category, nil
+}
+
+func (cs *categoryService) DeleteCategory(ctx context.Context, categoryID uint) error {
+	if _, err := cs.getCategory(ctx, categoryID); err != nil {
+		return err
+	}
+
+	inUse, err := cs.categoryRepository.IsCategoryInUse(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrCategoryInUse
+	}
+	return cs.categoryRepository.DeleteCategory(ctx, categoryID)
+}
+
+// GetActiveCategory dipakai saat membuat atau mengubah event, sehingga event
+// baru tidak bisa memakai kategori yang sudah dinonaktifkan.
+func (cs *categoryService) GetActiveCategory(ctx context.Context, categoryID uint) (entities.CategoryEvent, error) {
+	category, err := cs.getCategory(ctx, categoryID)
+	if err != nil {
+		return entities.CategoryEvent{}, err
+	}
+	if !category.Active() {
+		return entities.CategoryEvent{}, ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (cs *categoryService) getCategory(ctx context.Context, categoryID uint) (entities.CategoryEvent, error) {
+	category, err := cs.categoryRepository.GetCategoryByID(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.CategoryEvent{}, ErrCategoryNotFound
+		}
+		return entities.CategoryEvent{}, err
+	}
+	return category, nil
+}
+
+func (cs *categoryService) ensureSlugAvailable(ctx context.Context, slug string, categoryID uint) error {
+	if slug == "" {
+		return errors.New("Slug Kategori Tidak Valid")
+	}
+	existing, err := cs.categoryRepository.GetCategoryBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != categoryID {
+		return ErrCategorySlugTaken
+	}
+	return nil
+}
@@ -2,52 +2,197 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Caknoooo/golang-clean_template/dto"
 	"github.com/Caknoooo/golang-clean_template/entities"
 	"github.com/Caknoooo/golang-clean_template/repository"
 	"github.com/google/uuid"
 	"github.com/mashingan/smapping"
+	"gorm.io/gorm"
 )
 
 type EventService interface {
 	CreateEvent(ctx context.Context, eventDTO dto.EventCreateDTO) (entities.Event, error)
-	GetAllEvent(ctx context.Context) ([]entities.Event, error)
-	GetAllEventByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Event, error)
+	GetEvents(ctx context.Context, listQuery dto.EventListQuery) ([]entities.Event, dto.PaginationMeta, error)
+	SearchEvents(ctx context.Context, searchQuery dto.EventSearchQuery) ([]entities.EventSearchResult, dto.PaginationMeta, error)
+	GetAllEventByUserID(ctx context.Context, userID uuid.UUID, publicOnly bool) ([]entities.Event, error)
 	GetEventByID(ctx context.Context, eventID uuid.UUID) (entities.Event, error)
 	LikeEventByEventID(ctx context.Context, userID uuid.UUID, eventID uuid.UUID) error
 	UpdateEvent(ctx context.Context, eventDTO dto.EventUpdateDTO, eventID uuid.UUID) error
-	PatchEvent(ctx context.Context, eventDTO dto.EventUpdateDTO, eventID uuid.UUID) error
+	TransitionStatus(ctx context.Context, actor *Actor, eventID uuid.UUID, to string, reason string) (entities.Event, error)
+	GetStatusHistory(ctx context.Context, eventID uuid.UUID) ([]entities.EventStatusHistory, error)
+	RecordDonation(ctx context.Context, transaksiDTO dto.TransaksiCreateDTO) (entities.Transaksi, entities.Event, error)
+	ExpireDueEvents(ctx context.Context) (int, error)
 	DeleteEvent(ctx context.Context, eventID uuid.UUID) error
-	Get3Event(ctx context.Context, q uint) ([]entities.Event, error)
-	GetEventForService(ctx context.Context) ([]entities.Event, error)
+	GetEventForService(ctx context.Context) ([]dto.EventResponseServiceDTO, error)
 }
 
 type eventService struct {
-	eventRepository repository.EventRepository
+	eventRepository    repository.EventRepository
+	categoryRepository repository.CategoryRepository
 }
 
-func NewEventService(er repository.EventRepository) EventService {
+func NewEventService(er repository.EventRepository, cr repository.CategoryRepository) EventService {
 	return &eventService{
-		eventRepository: er,
+		eventRepository:    er,
+		categoryRepository: cr,
 	}
 }
 
 func (es *eventService) CreateEvent(ctx context.Context, eventDTO dto.EventCreateDTO) (entities.Event, error) {
+	// smapping tidak bisa memetakan pointer ke uuid, sehingga FotoAssetID diisi terpisah
+	fotoAssetID := eventDTO.FotoAssetID
+	eventDTO.FotoAssetID = nil
+
 	event := entities.Event{}
 	err := smapping.FillStruct(&event, smapping.MapFields(eventDTO))
 	if err != nil {
 		return entities.Event{}, err
 	}
+	event.FotoAssetID = fotoAssetID
+	// Event baru selalu dimulai sebagai draft sampai diajukan untuk review
+	event.Status = entities.EventStatusDraft
 	return es.eventRepository.CreateEvent(ctx, event)
 }
 
-func (es *eventService) GetAllEvent(ctx context.Context) ([]entities.Event, error) {
-	return es.eventRepository.GetAllEvent(ctx)
+func (es *eventService) GetEvents(ctx context.Context, listQuery dto.EventListQuery) ([]entities.Event, dto.PaginationMeta, error) {
+	sort := listQuery.Sort
+	if sort == "" {
+		sort = entities.EventSortNewest
+	}
+	page, perPage := normalizePage(listQuery.Page, listQuery.PerPage)
+
+	query, err := es.toEventQuery(ctx, listQuery.EventFilterQuery)
+	if err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+	query.Sort = sort
+	// Satu baris tambahan diambil untuk mengetahui apakah masih ada halaman berikutnya
+	query.Limit = perPage + 1
+
+	if listQuery.Cursor != "" {
+		cursor, err := decodeEventCursor(listQuery.Cursor, sort)
+		if err != nil {
+			return nil, dto.PaginationMeta{}, err
+		}
+		query.Cursor = &cursor
+	} else {
+		query.Offset = (page - 1) * perPage
+	}
+
+	events, total, err := es.eventRepository.GetEvents(ctx, query)
+	if err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	meta := newPaginationMeta(page, perPage, total)
+	if listQuery.Cursor != "" {
+		meta.Page = 0
+	}
+	if len(events) > perPage {
+		events = events[:perPage]
+		meta.NextCursor = encodeEventCursor(sort, events[len(events)-1])
+	}
+	return events, meta, nil
+}
+
+func (es *eventService) SearchEvents(ctx context.Context, searchQuery dto.EventSearchQuery) ([]entities.EventSearchResult, dto.PaginationMeta, error) {
+	page, perPage := normalizePage(searchQuery.Page, searchQuery.PerPage)
+
+	query, err := es.toEventQuery(ctx, searchQuery.EventFilterQuery)
+	if err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+	query.Search = strings.TrimSpace(searchQuery.Q)
+	query.Limit = perPage
+	query.Offset = (page - 1) * perPage
+
+	results, total, err := es.eventRepository.SearchEvents(ctx, query)
+	if err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+	return results, newPaginationMeta(page, perPage, total), nil
+}
+
+func normalizePage(page int, perPage int) (int, int) {
+	if page == 0 {
+		page = 1
+	}
+	if perPage == 0 {
+		perPage = defaultEventPerPage
+	}
+	return page, perPage
 }
 
-func (es *eventService) GetAllEventByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Event, error) {
-	return es.eventRepository.GetAllEventByUserID(ctx, userID)
+func newPaginationMeta(page int, perPage int, total int64) dto.PaginationMeta {
+	return dto.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + int64(perPage) - 1) / int64(perPage),
+	}
+}
+
+func (es *eventService) toEventQuery(ctx context.Context, filter dto.EventFilterQuery) (entities.EventQuery, error) {
+	query := entities.EventQuery{
+		Statuses: entities.EventPublicStatuses,
+	}
+	if filter.Status != "" {
+		query.Statuses = []string{filter.Status}
+	}
+	query.Statuses = filterStatuses(query.Statuses, entities.EventStatusExpired, filter.Expired)
+	query.Statuses = filterStatuses(query.Statuses, entities.EventStatusFunded, filter.TargetFull)
+	if filter.Category != "" {
+		category, err := es.categoryRepository.GetCategoryBySlug(ctx, filter.Category)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entities.EventQuery{}, ErrCategoryNotFound
+			}
+			return entities.EventQuery{}, err
+		}
+		query.CategoryIDs, err = es.categoryRepository.GetCategorySubtreeIDs(ctx, category.ID)
+		if err != nil {
+			return entities.EventQuery{}, err
+		}
+	}
+	if filter.CreatorID != "" {
+		creatorID, err := uuid.Parse(filter.CreatorID)
+		if err != nil {
+			return entities.EventQuery{}, err
+		}
+		query.UserID = &creatorID
+	}
+	return query, nil
+}
+
+// filterStatuses menyisakan status yang sama dengan status jika want bernilai
+// true, atau membuang status tersebut jika want bernilai false. Hasil kosong
+// tetap berupa slice agar query tidak mengembalikan event apa pun.
+func filterStatuses(statuses []string, status string, want *bool) []string {
+	if want == nil {
+		return statuses
+	}
+	filtered := []string{}
+	for _, s := range statuses {
+		if (s == status) == *want {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+func (es *eventService) GetAllEventByUserID(ctx context.Context, userID uuid.UUID, publicOnly bool) ([]entities.Event, error) {
+	var statuses []string
+	if publicOnly {
+		statuses = entities.EventPublicStatuses
+	}
+	return es.eventRepository.GetAllEventByUserID(ctx, userID, statuses)
 }
 
 func (es *eventService) GetEventByID(ctx context.Context, eventID uuid.UUID) (entities.Event, error) {
@@ -59,29 +204,97 @@ func (es *eventService) LikeEventByEventID(ctx context.Context, userID uuid.UUID
 }
 
 func (es *eventService) UpdateEvent(ctx context.Context, eventDTO dto.EventUpdateDTO, eventID uuid.UUID) error {
+	fotoAssetID := eventDTO.FotoAssetID
+	eventDTO.FotoAssetID = nil
+
 	event := entities.Event{}
 	if err := smapping.FillStruct(&event, smapping.MapFields(eventDTO)); err != nil {
-		return nil
+		return err
 	}
-	return es.eventRepository.UpdateEvent(ctx, event, eventID)
-}
+	event.FotoAssetID = fotoAssetID
 
-func (es *eventService) PatchEvent(ctx context.Context, eventDTO dto.EventUpdateDTO, eventID uuid.UUID) error {
-	event := entities.Event{}
-	if err := smapping.FillStruct(&event, smapping.MapFields(eventDTO)); err != nil {
-		return nil
+	updated, err := es.eventRepository.UpdateEvent(ctx, event, eventID)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrEventNotEditable
 	}
-	return es.eventRepository.PatchEvent(ctx, event, eventID)
+	return nil
 }
 
 func (es *eventService) DeleteEvent(ctx context.Context, eventID uuid.UUID) error {
-	return es.eventRepository.DeleteEvent(ctx, eventID)
+	deleted, err := es.eventRepository.DeleteEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrEventNotDeletable
+	}
+	return nil
 }
 
-func (es *eventService) Get3Event(ctx context.Context, q uint) ([]entities.Event, error) {
-	return es.eventRepository.Get3Event(ctx, q)
+func (es *eventService) GetEventForService(ctx context.Context) ([]dto.EventResponseServiceDTO, error) {
+	events, err := es.eventRepository.GetEventForService(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.EventResponseServiceDTO, 0, len(events))
+	for _, event := range events {
+		res = append(res, ToEventServiceResponse(event))
+	}
+	return res, nil
+}
+
+const defaultEventPerPage = 10
+
+var ErrInvalidCursor = errors.New("Cursor Tidak Valid")
+
+type eventCursorPayload struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func encodeEventCursor(sort string, event entities.Event) string {
+	payload := eventCursorPayload{Sort: sort, ID: event.ID}
+	switch sort {
+	case entities.EventSortMostLiked:
+		payload.Value = strconv.FormatUint(event.LikeCount, 10)
+	case entities.EventSortEndingSoon:
+		payload.Value = event.ExpiredDonasi.Format(time.RFC3339Nano)
+	case entities.EventSortMostFunded:
+		payload.Value = strconv.FormatFloat(event.JumlahDonasi, 'g', -1, 64)
+	default:
+		payload.Value = event.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	raw, _ := json.Marshal(payload)
+	return base64.RawURLEncoding.EncodeToString(raw)
 }
 
-func (es *eventService) GetEventForService(ctx context.Context) ([]entities.Event, error) {
-	return es.eventRepository.GetEventForService(ctx)
+// decodeEventCursor menolak cursor yang dibuat untuk urutan lain karena nilai
+// di dalamnya tidak bisa dibandingkan dengan kolom urutan yang diminta.
+func decodeEventCursor(cursor string, sort string) (entities.EventCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return entities.EventCursor{}, ErrInvalidCursor
+	}
+	var payload eventCursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.Sort != sort {
+		return entities.EventCursor{}, ErrInvalidCursor
+	}
+
+	var value any
+	switch sort {
+	case entities.EventSortMostLiked, entities.EventSortMostFunded:
+		value, err = strconv.ParseFloat(payload.Value, 64)
+	default:
+		value, err = time.Parse(time.RFC3339Nano, payload.Value)
+	}
+	if err != nil {
+		return entities.EventCursor{}, ErrInvalidCursor
+	}
+	return entities.EventCursor{Value: value, ID: payload.ID}, nil
 }
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/repository"
+)
+
+func ToEventPublicResponse(event entities.Event) dto.EventPublicResponse {
+	res := dto.EventPublicResponse{
+		ID:                   event.ID,
+		JudulEvent:           event.JudulEvent,
+		DeskripsiEvent:       event.DeskripsiEvent,
+		CategoryID:           event.CategoryID,
+		FotoEvent:            event.FotoEvent,
+		FotoThumbnail:        event.FotoThumbnail,
+		FotoAssetID:          event.FotoAssetID,
+		MaxDonasi:            event.MaxDonasi,
+		JumlahDonasi:         event.JumlahDonasi,
+		SisaDonasi:           event.SisaDonasi,
+		LikeCount:            event.LikeCount,
+		ExpiredDonasi:        event.ExpiredDonasi,
+		SisaHariDonasi:       TimeLeft(event.ExpiredDonasi, time.Now()),
+		Status:               event.Status,
+		NamaDepanPembuat:     event.NamaDepanPembuat,
+		NamaBelakangPembuat:  event.NamaBelakangPembuat,
+		Pekerjaan:            event.Pekerjaan,
+		AsalInstansi:         event.AsalInstansi,
+		NamaDepanPenerima:    event.NamaDepanPenerima,
+		NamaBelakangPenerima: event.NamaBelakangPenerima,
+		TujuanGalangDana:     event.TujuanGalangDana,
+		LokasiTujuan:         event.LokasiTujuan,
+		UserID:               event.UserID,
+		CreatedAt:            event.CreatedAt,
+		UpdatedAt:            event.UpdatedAt,
+	}
+	// jenis_event tetap dikirim agar client lama masih bisa menampilkan nama kategori
+	if event.Category != nil {
+		res.JenisEvent = event.Category.Nama
+		res.CategorySlug = event.Category.Slug
+	}
+	if len(event.KabarTerbaru) > 0 {
+		res.KabarTerbaru = ToKabarTerbaruResponses(event.KabarTerbaru)
+	}
+	return res
+}
+
+func ToKabarTerbaruResponse(kabar entities.KabarTerbaru) dto.KabarTerbaruResponse {
+	res := dto.KabarTerbaruResponse{
+		ID:        kabar.ID,
+		Judul:     kabar.Judul,
+		Isi:       kabar.Isi,
+		Gambar:    make([]string, 0, len(kabar.Gambar)),
+		EventID:   kabar.EventID,
+		UserID:    kabar.UserID,
+		CreatedAt: kabar.CreatedAt,
+		UpdatedAt: kabar.UpdatedAt,
+	}
+	for _, gambar := range kabar.Gambar {
+		res.Gambar = append(res.Gambar, gambar.URL)
+	}
+	if kabar.HistoryPenarikan != nil {
+		res.Penarikan = &dto.KabarTerbaruPenarikanResponse{
+			ID:               kabar.HistoryPenarikan.ID,
+			JumlahPenarikan:  kabar.HistoryPenarikan.Jumlah_Penarikan,
+			NamaBank:         kabar.HistoryPenarikan.NamaBank,
+			TanggalPenarikan: kabar.HistoryPenarikan.Tanggal_Penarikan,
+		}
+	}
+	return res
+}
+
+func ToKabarTerbaruResponses(kabars []entities.KabarTerbaru) []dto.KabarTerbaruResponse {
+	res := make([]dto.KabarTerbaruResponse, 0, len(kabars))
+	for _, kabar := range kabars {
+		res = append(res, ToKabarTerbaruResponse(kabar))
+	}
+	return res
+}
+
+// TimeLeft menghitung sisa waktu donasi untuk ditampilkan, sehingga nilainya
+// selalu sesuai waktu request tanpa perlu disimpan.
+func TimeLeft(expiredTime time.Time, now time.Time) string {
+	days := expiredTime.Sub(now).Hours() / 24
+	if days <= 0 {
+		return "Waktu Habis"
+	}
+	if days < 1 {
+		return "<1 Hari"
+	}
+	return fmt.Sprintf("%v Hari", int(math.Round(days)))
+}
+
+func ToEventOwnerResponse(event entities.Event) dto.EventOwnerResponse {
+	return dto.EventOwnerResponse{
+		EventPublicResponse: ToEventPublicResponse(event),
+		RekeningEvent:       event.RekeningEvent,
+		NomorKTP:            event.NomorKTP,
+		NomorTeleponPembuat: event.NomorTeleponPembuat,
+	}
+}
+
+func ToEventServiceResponse(event entities.Event) dto.EventResponseServiceDTO {
+	return dto.EventResponseServiceDTO{
+		ID:             event.ID,
+		Nama:           event.JudulEvent,
+		DeskripsiEvent: event.DeskripsiEvent,
+		FotoEvent:      event.FotoEvent,
+		ExpiredDonasi:  event.ExpiredDonasi,
+		Status:         event.Status,
+	}
+}
+
+// ToEventResponse memilih tampilan event sesuai pemanggil. actor bernilai nil
+// untuk pengunjung yang tidak login.
+func ToEventResponse(event entities.Event, actor *Actor) any {
+	if CanViewEventDetails(actor, event) {
+		return ToEventOwnerResponse(event)
+	}
+	return ToEventPublicResponse(event)
+}
+
+func ToEventResponses(events []entities.Event, actor *Actor) []any {
+	res := make([]any, 0, len(events))
+	for _, event := range events {
+		res = append(res, ToEventResponse(event, actor))
+	}
+	return res
+}
+
+func ToEventSearchResponses(results []entities.EventSearchResult, actor *Actor) []dto.EventSearchResponse {
+	res := make([]dto.EventSearchResponse, 0, len(results))
+	for _, result := range results {
+		res = append(res, dto.EventSearchResponse{
+			Event:             ToEventResponse(result.Event, actor),
+			Rank:              result.SearchRank,
+			HeadlineJudul:     highlightSearch(result.HeadlineJudul),
+			HeadlineDeskripsi: highlightSearch(result.HeadlineDeskripsi),
+		})
+	}
+	return res
+}
+
+// highlightSearch meng-escape teks milik user lebih dulu, baru kemudian
+// mengganti penanda dari ts_headline dengan tag <mark>.
+func highlightSearch(headline string) string {
+	headline = html.EscapeString(headline)
+	headline = strings.ReplaceAll(headline, repository.EventSearchHighlightStart, "<mark>")
+	return strings.ReplaceAll(headline, repository.EventSearchHighlightStop, "</mark>")
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeLeft(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		expired time.Time
+		want    string
+	}{
+		{"sudah lewat", now.Add(-time.Hour), "Waktu Habis"},
+		{"tepat berakhir", now, "Waktu Habis"},
+		{"kurang dari sehari", now.Add(23 * time.Hour), "<1 Hari"},
+		{"tepat sehari", now.Add(24 * time.Hour), "1 Hari"},
+		{"dibulatkan ke bawah", now.Add(2*24*time.Hour + 11*time.Hour), "2 Hari"},
+		{"dibulatkan ke atas", now.Add(2*24*time.Hour + 13*time.Hour), "3 Hari"},
+		{"sebulan", now.AddDate(0, 1, 0), "31 Hari"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TimeLeft(tt.expired, now); got != tt.want {
+				t.Errorf("TimeLeft = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
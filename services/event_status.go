@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+	"github.com/mashingan/smapping"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidEventTransition    = errors.New("Perubahan Status Event Tidak Diizinkan")
+	ErrEventStatusChanged        = errors.New("Status Event Sudah Berubah, Silakan Coba Lagi")
+	ErrEventNotAcceptingDonation = errors.New("Event Tidak Sedang Menerima Donasi")
+	ErrEventDeadlinePassed       = errors.New("Batas Waktu Donasi Sudah Lewat")
+	ErrEventHasDonation          = errors.New("Event Yang Sudah Menerima Donasi Hanya Bisa Dibatalkan Admin")
+	ErrEventFundsRemaining       = errors.New("Dana Masih Tersisa, Tarik Dana Sebelum Menutup Event")
+	ErrEventNotEditable          = errors.New("Event Hanya Bisa Diubah Saat Berstatus Draft")
+	ErrEventNotDeletable         = errors.New("Hanya Event Draft Atau Menunggu Review Tanpa Donasi Yang Bisa Dihapus, Batalkan Event Lainnya")
+)
+
+// Pihak yang boleh menjalankan sebuah perubahan status.
+const (
+	transitionByOwner = 1 << iota
+	transitionByAdmin
+	transitionBySystem
+)
+
+// eventStatusTransitions berisi semua perubahan status yang diizinkan.
+// Pemilik event juga mencakup admin, karena admin boleh mengelola semua event.
+var eventStatusTransitions = map[string]map[string]int{
+	entities.EventStatusDraft: {
+		entities.EventStatusPendingReview: transitionByOwner,
+		entities.EventStatusCancelled:     transitionByOwner,
+	},
+	entities.EventStatusPendingReview: {
+		entities.EventStatusActive:    transitionByAdmin,
+		entities.EventStatusDraft:     transitionByOwner,
+		entities.EventStatusCancelled: transitionByOwner,
+	},
+	entities.EventStatusActive: {
+		entities.EventStatusFunded:    transitionBySystem,
+		entities.EventStatusExpired:   transitionBySystem,
+		entities.EventStatusCancelled: transitionByOwner,
+	},
+	entities.EventStatusFunded: {
+		entities.EventStatusClosed:    transitionByOwner,
+		entities.EventStatusCancelled: transitionByOwner,
+	},
+	entities.EventStatusExpired: {
+		entities.EventStatusClosed:    transitionByOwner,
+		entities.EventStatusCancelled: transitionByOwner,
+	},
+}
+
+const eventExpiryBatchSize = 100
+
+// canTransition mengecek apakah actor boleh memindahkan event dari status
+// from ke status to. actor bernilai nil untuk perubahan oleh sistem.
+func canTransition(actor *Actor, event entities.Event, to string) bool {
+	allowed, ok := eventStatusTransitions[event.Status][to]
+	if !ok {
+		return false
+	}
+	if actor == nil {
+		return allowed&transitionBySystem != 0
+	}
+	if allowed&transitionByAdmin != 0 {
+		return actor.IsAdmin()
+	}
+	if allowed&transitionByOwner != 0 {
+		return canManage(*actor, event.UserID)
+	}
+	return false
+}
+
+// cancelRequiresNoDonation bernilai true jika pembatalan hanya boleh dilakukan
+// selama event belum menerima donasi. Syarat ini juga ikut dicek saat status
+// diubah agar donasi yang masuk bersamaan tidak terlewat.
+func cancelRequiresNoDonation(actor *Actor, to string) bool {
+	return to == entities.EventStatusCancelled && (actor == nil || !actor.IsAdmin())
+}
+
+// checkTransitionGuard berisi syarat tambahan di luar tabel transisi.
+func checkTransitionGuard(actor *Actor, event entities.Event, to string, now time.Time) error {
+	switch to {
+	case entities.EventStatusActive:
+		if !event.ExpiredDonasi.After(now) {
+			return ErrEventDeadlinePassed
+		}
+	case entities.EventStatusCancelled:
+		if event.JumlahDonasi > 0 && cancelRequiresNoDonation(actor, to) {
+			return ErrEventHasDonation
+		}
+	case entities.EventStatusClosed:
+		if event.SisaDonasi > 0 {
+			return ErrEventFundsRemaining
+		}
+	}
+	return nil
+}
+
+func (es *eventService) TransitionStatus(ctx context.Context, actor *Actor, eventID uuid.UUID, to string, reason string) (entities.Event, error) {
+	event, err := es.eventRepository.GetEventByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Event{}, ErrResourceNotFound
+		}
+		return entities.Event{}, err
+	}
+
+	if !canTransition(actor, event, to) {
+		if actor != nil && !canManage(*actor, event.UserID) {
+			return entities.Event{}, ErrForbidden
+		}
+		return entities.Event{}, ErrInvalidEventTransition
+	}
+	now := time.Now()
+	if err := checkTransitionGuard(actor, event, to, now); err != nil {
+		return entities.Event{}, err
+	}
+
+	history := entities.EventStatusHistory{
+		ToStatus:  to,
+		Reason:    reason,
+		CreatedAt: now,
+	}
+	if actor != nil {
+		history.ActorID = &actor.UserID
+	}
+
+	updated, err := es.eventRepository.UpdateEventStatus(ctx, eventID, event.Status, cancelRequiresNoDonation(actor, to), history)
+	if err != nil {
+		return entities.Event{}, err
+	}
+	if !updated {
+		return entities.Event{}, ErrEventStatusChanged
+	}
+
+	event.Status = to
+	event.UpdatedAt = now
+	return event, nil
+}
+
+func (es *eventService) GetStatusHistory(ctx context.Context, eventID uuid.UUID) ([]entities.EventStatusHistory, error) {
+	return es.eventRepository.GetEventStatusHistory(ctx, eventID)
+}
+
+// RecordDonation mencatat transaksi donasi dan menambah total donasi event.
+// Event otomatis berpindah ke funded ketika target tercapai.
+func (es *eventService) RecordDonation(ctx context.Context, transaksiDTO dto.TransaksiCreateDTO) (entities.Transaksi, entities.Event, error) {
+	transaksi := entities.Transaksi{}
+	if err := smapping.FillStruct(&transaksi, smapping.MapFields(transaksiDTO)); err != nil {
+		return entities.Transaksi{}, entities.Event{}, err
+	}
+
+	now := time.Now()
+	result, event, accepted, err := es.eventRepository.RecordDonation(ctx, transaksi, now)
+	if err != nil {
+		return entities.Transaksi{}, entities.Event{}, err
+	}
+	if !accepted {
+		event, err := es.eventRepository.GetEventByID(ctx, transaksi.EventID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entities.Transaksi{}, entities.Event{}, ErrResourceNotFound
+			}
+			return entities.Transaksi{}, entities.Event{}, err
+		}
+		// Event yang lewat batas waktu langsung ditandai expired tanpa menunggu job
+		if event.Status == entities.EventStatusActive && !event.ExpiredDonasi.After(now) {
+			if _, err := es.TransitionStatus(ctx, nil, event.ID, entities.EventStatusExpired, "Batas waktu donasi berakhir"); err != nil && !errors.Is(err, ErrEventStatusChanged) {
+				log.Printf("error expiring event %s: %v", event.ID, err)
+			}
+			return entities.Transaksi{}, entities.Event{}, ErrEventDeadlinePassed
+		}
+		return entities.Transaksi{}, entities.Event{}, ErrEventNotAcceptingDonation
+	}
+
+	if event.MaxDonasi > 0 && event.JumlahDonasi >= event.MaxDonasi {
+		funded, err := es.TransitionStatus(ctx, nil, event.ID, entities.EventStatusFunded, "Target donasi tercapai")
+		if err != nil && !errors.Is(err, ErrEventStatusChanged) {
+			return entities.Transaksi{}, entities.Event{}, err
+		}
+		if err == nil {
+			event = funded
+		}
+	}
+	return result, event, nil
+}
+
+// ExpireDueEvents memindahkan event active yang sudah lewat batas waktu ke
+// status expired.
+func (es *eventService) ExpireDueEvents(ctx context.Context) (int, error) {
+	events, err := es.eventRepository.GetEventsDueForExpiry(ctx, time.Now(), eventExpiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, event := range events {
+		if _, err := es.TransitionStatus(ctx, nil, event.ID, entities.EventStatusExpired, "Batas waktu donasi berakhir"); err != nil {
+			if errors.Is(err, ErrEventStatusChanged) {
+				continue
+			}
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// NewEventExpiryJob menjalankan ExpireDueEvents secara berkala.
+func NewEventExpiryJob(eventService EventService, interval time.Duration) Job {
+	return Job{
+		Name:     "event_expiry",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			expired, err := eventService.ExpireDueEvents(ctx)
+			if err != nil {
+				return err
+			}
+			if expired > 0 {
+				log.Printf("expired %d event", expired)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+)
+
+func TestCanTransition(t *testing.T) {
+	ownerID := uuid.New()
+	owner := &Actor{UserID: ownerID, Role: entities.RoleCampaigner}
+	other := &Actor{UserID: uuid.New(), Role: entities.RoleCampaigner}
+	admin := &Actor{UserID: uuid.New(), Role: entities.RoleAdmin}
+
+	tests := []struct {
+		name  string
+		actor *Actor
+		from  string
+		to    string
+		want  bool
+	}{
+		{"pemilik mengajukan review", owner, entities.EventStatusDraft, entities.EventStatusPendingReview, true},
+		{"user lain mengajukan review", other, entities.EventStatusDraft, entities.EventStatusPendingReview, false},
+		{"admin mengajukan review", admin, entities.EventStatusDraft, entities.EventStatusPendingReview, true},
+		{"sistem mengajukan review", nil, entities.EventStatusDraft, entities.EventStatusPendingReview, false},
+		{"admin menyetujui", admin, entities.EventStatusPendingReview, entities.EventStatusActive, true},
+		{"pemilik menyetujui sendiri", owner, entities.EventStatusPendingReview, entities.EventStatusActive, false},
+		{"pemilik menarik ke draft", owner, entities.EventStatusPendingReview, entities.EventStatusDraft, true},
+		{"sistem menandai funded", nil, entities.EventStatusActive, entities.EventStatusFunded, true},
+		{"sistem menandai expired", nil, entities.EventStatusActive, entities.EventStatusExpired, true},
+		{"admin menandai expired", admin, entities.EventStatusActive, entities.EventStatusExpired, false},
+		{"pemilik membatalkan event aktif", owner, entities.EventStatusActive, entities.EventStatusCancelled, true},
+		{"sistem membatalkan event aktif", nil, entities.EventStatusActive, entities.EventStatusCancelled, false},
+		{"pemilik menutup event funded", owner, entities.EventStatusFunded, entities.EventStatusClosed, true},
+		{"pemilik menutup event expired", owner, entities.EventStatusExpired, entities.EventStatusClosed, true},
+		{"admin membatalkan event funded", admin, entities.EventStatusFunded, entities.EventStatusCancelled, true},
+		{"pemilik membatalkan event expired", owner, entities.EventStatusExpired, entities.EventStatusCancelled, true},
+		{"sistem membatalkan event expired", nil, entities.EventStatusExpired, entities.EventStatusCancelled, false},
+		{"draft langsung aktif", admin, entities.EventStatusDraft, entities.EventStatusActive, false},
+		{"event aktif kembali ke draft", owner, entities.EventStatusActive, entities.EventStatusDraft, false},
+		{"event closed tidak bisa berubah", admin, entities.EventStatusClosed, entities.EventStatusActive, false},
+		{"event cancelled tidak bisa berubah", admin, entities.EventStatusCancelled, entities.EventStatusDraft, false},
+		{"status tidak dikenal", admin, entities.EventStatusDraft, "archived", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := entities.Event{UserID: ownerID, Status: tt.from}
+			if got := canTransition(tt.actor, event, tt.to); got != tt.want {
+				t.Errorf("canTransition(%s -> %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTransitionGuard(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	owner := &Actor{UserID: uuid.New(), Role: entities.RoleCampaigner}
+	admin := &Actor{UserID: uuid.New(), Role: entities.RoleAdmin}
+
+	tests := []struct {
+		name  string
+		actor *Actor
+		event entities.Event
+		to    string
+		want  error
+	}{
+		{"aktif sebelum batas waktu", admin, entities.Event{ExpiredDonasi: now.Add(time.Hour)}, entities.EventStatusActive, nil},
+		{"aktif tepat di batas waktu", admin, entities.Event{ExpiredDonasi: now}, entities.EventStatusActive, ErrEventDeadlinePassed},
+		{"aktif setelah batas waktu", admin, entities.Event{ExpiredDonasi: now.Add(-time.Hour)}, entities.EventStatusActive, ErrEventDeadlinePassed},
+		{"pemilik membatalkan tanpa donasi", owner, entities.Event{}, entities.EventStatusCancelled, nil},
+		{"pemilik membatalkan dengan donasi", owner, entities.Event{JumlahDonasi: 10000}, entities.EventStatusCancelled, ErrEventHasDonation},
+		{"sistem membatalkan dengan donasi", nil, entities.Event{JumlahDonasi: 10000}, entities.EventStatusCancelled, ErrEventHasDonation},
+		{"admin membatalkan dengan donasi", admin, entities.Event{JumlahDonasi: 10000}, entities.EventStatusCancelled, nil},
+		{"menutup tanpa sisa dana", owner, entities.Event{JumlahDonasi: 10000}, entities.EventStatusClosed, nil},
+		{"menutup dengan sisa dana", owner, entities.Event{SisaDonasi: 5000}, entities.EventStatusClosed, ErrEventFundsRemaining},
+		{"status tanpa syarat tambahan", owner, entities.Event{}, entities.EventStatusPendingReview, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkTransitionGuard(tt.actor, tt.event, tt.to, now); got != tt.want {
+				t.Errorf("checkTransitionGuard = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
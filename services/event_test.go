@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+)
+
+// fakeEventRepository mencatat perubahan yang dikirim ke UpdateEvent.
+type fakeEventRepository struct {
+	repository.EventRepository
+	updated map[uuid.UUID]entities.Event
+}
+
+func (fr *fakeEventRepository) UpdateEvent(ctx context.Context, event entities.Event, eventID uuid.UUID) (bool, error) {
+	if fr.updated == nil {
+		fr.updated = map[uuid.UUID]entities.Event{}
+	}
+	fr.updated[eventID] = event
+	return true, nil
+}
+
+func TestUpdateEventChangesTitle(t *testing.T) {
+	repo := &fakeEventRepository{}
+	service := NewEventService(repo, nil)
+	eventID := uuid.New()
+	judul := "Bantu Renovasi Sekolah"
+	deskripsi := "Deskripsi baru"
+
+	if err := service.UpdateEvent(context.Background(), dto.EventUpdateDTO{JudulEvent: &judul, DeskripsiEvent: &deskripsi}, eventID); err != nil {
+		t.Fatalf("UpdateEvent: %v", err)
+	}
+	event, ok := repo.updated[eventID]
+	if !ok {
+		t.Fatal("UpdateEvent tidak memanggil repository")
+	}
+	if event.JudulEvent != judul {
+		t.Errorf("JudulEvent = %q, want %q", event.JudulEvent, judul)
+	}
+	if event.DeskripsiEvent != deskripsi {
+		t.Errorf("DeskripsiEvent = %q, want %q", event.DeskripsiEvent, deskripsi)
+	}
+	if event.RekeningEvent != "" {
+		t.Errorf("field yang tidak dikirim ikut diubah: RekeningEvent = %q", event.RekeningEvent)
+	}
+}
+
+func TestEventCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 10, 30, 0, 123456789, time.UTC)
+	expiredAt := time.Date(2024, 4, 1, 0, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+	event := entities.Event{
+		ID:            uuid.New(),
+		LikeCount:     42,
+		JumlahDonasi:  1500000.5,
+		ExpiredDonasi: expiredAt,
+		Timestamp:     entities.Timestamp{CreatedAt: createdAt},
+	}
+
+	tests := []struct {
+		sort  string
+		value any
+	}{
+		{entities.EventSortNewest, createdAt},
+		{entities.EventSortMostLiked, float64(42)},
+		{entities.EventSortEndingSoon, expiredAt},
+		{entities.EventSortMostFunded, 1500000.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			cursor, err := decodeEventCursor(encodeEventCursor(tt.sort, event), tt.sort)
+			if err != nil {
+				t.Fatalf("decodeEventCursor: %v", err)
+			}
+			if cursor.ID != event.ID {
+				t.Errorf("ID = %s, want %s", cursor.ID, event.ID)
+			}
+			switch want := tt.value.(type) {
+			case time.Time:
+				got, ok := cursor.Value.(time.Time)
+				if !ok || !got.Equal(want) {
+					t.Errorf("Value = %v, want %v", cursor.Value, want)
+				}
+			default:
+				if cursor.Value != want {
+					t.Errorf("Value = %v, want %v", cursor.Value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeEventCursorRejectsInvalidCursor(t *testing.T) {
+	event := entities.Event{ID: uuid.New(), Timestamp: entities.Timestamp{CreatedAt: time.Now()}}
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{"bukan base64", "!!!", entities.EventSortNewest},
+		{"bukan json", encode("bukan json"), entities.EventSortNewest},
+		{"urutan berbeda", encodeEventCursor(entities.EventSortNewest, event), entities.EventSortMostLiked},
+		{"waktu tidak valid", encode(`{"s":"newest","v":"kemarin","id":"` + event.ID.String() + `"}`), entities.EventSortNewest},
+		{"angka tidak valid", encode(`{"s":"most_liked","v":"banyak","id":"` + event.ID.String() + `"}`), entities.EventSortMostLiked},
+		{"id tidak valid", encode(`{"s":"most_liked","v":"1","id":"bukan-uuid"}`), entities.EventSortMostLiked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeEventCursor(tt.cursor, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestEventQueryMapsLegacyFilters(t *testing.T) {
+	service := &eventService{}
+	yes, no := true, false
+
+	tests := []struct {
+		name   string
+		filter dto.EventFilterQuery
+		want   []string
+	}{
+		{"tanpa filter", dto.EventFilterQuery{}, entities.EventPublicStatuses},
+		{"expired", dto.EventFilterQuery{Expired: &yes}, []string{entities.EventStatusExpired}},
+		{"belum expired", dto.EventFilterQuery{Expired: &no}, []string{entities.EventStatusActive, entities.EventStatusFunded, entities.EventStatusClosed}},
+		{"target penuh", dto.EventFilterQuery{TargetFull: &yes}, []string{entities.EventStatusFunded}},
+		{"aktif dan belum penuh", dto.EventFilterQuery{Expired: &no, TargetFull: &no}, []string{entities.EventStatusActive, entities.EventStatusClosed}},
+		{"status bertentangan", dto.EventFilterQuery{Status: entities.EventStatusActive, Expired: &yes}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := service.toEventQuery(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query.Statuses == nil || len(query.Statuses) != len(tt.want) {
+				t.Fatalf("Statuses = %v, want %v", query.Statuses, tt.want)
+			}
+			for i := range tt.want {
+				if query.Statuses[i] != tt.want[i] {
+					t.Fatalf("Statuses = %v, want %v", query.Statuses, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -1,41 +1,74 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"sync"
 	"time"
 
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 )
 
+func init() {
+	// iat disimpan dalam milidetik agar token yang diterbitkan tepat setelah
+	// logout dari semua device tidak ikut tercabut.
+	jwt.TimePrecision = time.Millisecond
+}
+
 type JWTService interface {
-	GenerateToken(UserID uuid.UUID, role string) string
+	GenerateToken(UserID uuid.UUID, role string, sessionID uuid.UUID) string
 	ValidateToken(token string) (*jwt.Token, error)
 	InvalidateToken(token string) error
+	InvalidateAllUserToken(userID uuid.UUID) error
 	GetUserIDByToken(token string) (uuid.UUID, error)
+	ParseAccessToken(token string) (AccessTokenClaims, error)
+	GetAccessTokenTTL() time.Duration
+	GenerateActionToken(userID uuid.UUID, email string, purpose string, ttl time.Duration) string
+	ValidateActionToken(token string, purpose string) (uuid.UUID, string, error)
+}
+
+// AccessTokenClaims adalah isi access token yang sudah diverifikasi.
+type AccessTokenClaims struct {
+	UserID    uuid.UUID
+	Role      string
+	SessionID uuid.UUID
 }
 
 type jwtCustomClaim struct {
-	UserID uuid.UUID `json:"user_id"`
-	Role   string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	Role      string    `json:"role"`
+	SessionID uuid.UUID `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// actionClaim dipakai untuk link yang dikirim lewat email, misalnya verifikasi
+// email. Purpose ikut menentukan kunci tanda tangan sehingga token untuk satu
+// keperluan tidak bisa dipakai untuk keperluan lain maupun sebagai access token.
+type actionClaim struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Email   string    `json:"email"`
+	Purpose string    `json:"purpose"`
 	jwt.RegisteredClaims
 }
 
 type jwtService struct {
-	secretKey  string
-	issuer     string
-	invalidate map[string]time.Time
-	mutex      sync.Mutex
+	secretKey              string
+	issuer                 string
+	accessTTL              time.Duration
+	revokedTokenRepository repository.RevokedTokenRepository
 }
 
-func NewJWTService() JWTService {
+func NewJWTService(rr repository.RevokedTokenRepository) JWTService {
 	return &jwtService{
-		secretKey:  getSecretKey(),
-		issuer:     "Template",
-		invalidate: make(map[string]time.Time),
+		secretKey:              getSecretKey(),
+		issuer:                 "Template",
+		accessTTL:              getAccessTokenTTL(),
+		revokedTokenRepository: rr,
 	}
 }
 
@@ -47,14 +80,24 @@ func getSecretKey() string {
 	return secretKey
 }
 
-func (j *jwtService) GenerateToken(UserID uuid.UUID, role string) string {
+func getAccessTokenTTL() time.Duration {
+	return time.Duration(helpers.GetEnvInt("JWT_ACCESS_TTL_MINUTES", 15)) * time.Minute
+}
+
+func (j *jwtService) GetAccessTokenTTL() time.Duration {
+	return j.accessTTL
+}
+
+func (j *jwtService) GenerateToken(UserID uuid.UUID, role string, sessionID uuid.UUID) string {
 	claims := jwtCustomClaim{
 		UserID,
 		role,
+		sessionID,
 		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTTL)),
 			Issuer:    j.issuer,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.NewString(),
 		},
 	}
 
@@ -74,26 +117,79 @@ func (j *jwtService) parseToken(t_ *jwt.Token) (any, error) {
 }
 
 func (j *jwtService) ValidateToken(token string) (*jwt.Token, error) {
-	// Check if token is invalidated
-	j.mutex.Lock()
-	if _, ok := j.invalidate[token]; ok {
-		j.mutex.Unlock()
-		return nil, fmt.Errorf("token has been invalidated")
+	t_Token, err := jwt.Parse(token, j.parseToken)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := j.getRegisteredClaims(t_Token)
+	if err != nil {
+		return nil, err
 	}
-	j.mutex.Unlock()
 
-	// Validate token
-	return jwt.Parse(token, j.parseToken)
+	revoked, err := j.revokedTokenRepository.IsTokenRevoked(context.Background(), claims.ID, claims.userID, claims.IssuedAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.New("token has been invalidated")
+	}
+	return t_Token, nil
 }
 
 func (j *jwtService) InvalidateToken(token string) error {
-	j.mutex.Lock()
-	defer j.mutex.Unlock()
-	if _, ok := j.invalidate[token]; ok {
-		return fmt.Errorf("token has already been invalidated")
+	t_Token, err := j.ValidateToken(token)
+	if err != nil {
+		return err
+	}
+
+	claims, err := j.getRegisteredClaims(t_Token)
+	if err != nil {
+		return err
+	}
+	if claims.ID == "" {
+		return errors.New("token does not have an id")
+	}
+	return j.revokedTokenRepository.RevokeToken(context.Background(), claims.ID, claims.userID, claims.ExpiresAt.Time)
+}
+
+func (j *jwtService) InvalidateAllUserToken(userID uuid.UUID) error {
+	return j.revokedTokenRepository.RevokeAllUserToken(context.Background(), userID, time.Now())
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	userID uuid.UUID
+}
+
+// getRegisteredClaims membaca ulang claim dari token yang sudah terverifikasi
+// dalam bentuk yang bertipe, karena jwt.Parse menghasilkan jwt.MapClaims.
+func (j *jwtService) getRegisteredClaims(t_Token *jwt.Token) (tokenClaims, error) {
+	mapClaims, ok := t_Token.Claims.(jwt.MapClaims)
+	if !ok {
+		return tokenClaims{}, errors.New("invalid token claims")
+	}
+
+	claims := tokenClaims{}
+	if jti, ok := mapClaims["jti"].(string); ok {
+		claims.ID = jti
 	}
-	j.invalidate[token] = time.Now()
-	return nil
+	if iat, ok := mapClaims["iat"].(float64); ok {
+		claims.IssuedAt = jwt.NewNumericDate(time.UnixMilli(int64(iat * 1000)))
+	} else {
+		return tokenClaims{}, errors.New("token does not have an issued at")
+	}
+	if exp, ok := mapClaims["exp"].(float64); ok {
+		claims.ExpiresAt = jwt.NewNumericDate(time.UnixMilli(int64(exp * 1000)))
+	} else {
+		return tokenClaims{}, errors.New("token does not have an expiry")
+	}
+	userID, err := uuid.Parse(fmt.Sprintf("%v", mapClaims["user_id"]))
+	if err != nil {
+		return tokenClaims{}, err
+	}
+	claims.userID = userID
+	return claims, nil
 }
 
 func (j *jwtService) GetUserIDByToken(token string) (uuid.UUID, error) {
@@ -106,3 +202,71 @@ func (j *jwtService) GetUserIDByToken(token string) (uuid.UUID, error) {
 	teamID, _ := uuid.Parse(id)
 	return teamID, nil
 }
+
+// ParseAccessToken memverifikasi token dan memeriksa pencabutannya sekali,
+// lalu mengembalikan seluruh claim yang dibutuhkan middleware. Token tanpa
+// claim sid, yaitu token yang diterbitkan sebelum session dicatat, ditolak.
+func (j *jwtService) ParseAccessToken(token string) (AccessTokenClaims, error) {
+	t_Token, err := j.ValidateToken(token)
+	if err != nil {
+		return AccessTokenClaims{}, err
+	}
+	claims := t_Token.Claims.(jwt.MapClaims)
+
+	userID, err := uuid.Parse(fmt.Sprintf("%v", claims["user_id"]))
+	if err != nil {
+		return AccessTokenClaims{}, errors.New("invalid token claims")
+	}
+	role, _ := claims["role"].(string)
+	sid, _ := claims["sid"].(string)
+	sessionID, err := uuid.Parse(sid)
+	if err != nil || sessionID == uuid.Nil {
+		return AccessTokenClaims{}, errors.New("token does not have a session")
+	}
+	return AccessTokenClaims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+	}, nil
+}
+
+func (j *jwtService) actionKey(purpose string) []byte {
+	return []byte(j.secretKey + ":" + purpose)
+}
+
+func (j *jwtService) GenerateActionToken(userID uuid.UUID, email string, purpose string, ttl time.Duration) string {
+	claims := actionClaim{
+		userID,
+		email,
+		purpose,
+		jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			Issuer:    j.issuer,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tx, err := token.SignedString(j.actionKey(purpose))
+	if err != nil {
+		log.Println(err)
+	}
+	return tx
+}
+
+func (j *jwtService) ValidateActionToken(token string, purpose string) (uuid.UUID, string, error) {
+	claims := &actionClaim{}
+	t_Token, err := jwt.ParseWithClaims(token, claims, func(t_ *jwt.Token) (any, error) {
+		if _, ok := t_.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t_.Header["alg"])
+		}
+		return j.actionKey(purpose), nil
+	})
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	if !t_Token.Valid || claims.Purpose != purpose {
+		return uuid.Nil, "", errors.New("invalid action token")
+	}
+	return claims.UserID, claims.Email, nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type KabarTerbaruService interface {
+	CreateKabarTerbaru(ctx context.Context, actor Actor, eventID uuid.UUID, kabarDTO dto.KabarTerbaruCreateDTO) (entities.KabarTerbaru, error)
+	GetKabarTerbaruByEventID(ctx context.Context, eventID uuid.UUID) ([]entities.KabarTerbaru, error)
+	UpdateKabarTerbaru(ctx context.Context, eventID uuid.UUID, kabarID uuid.UUID, kabarDTO dto.KabarTerbaruUpdateDTO) (entities.KabarTerbaru, error)
+	DeleteKabarTerbaru(ctx context.Context, eventID uuid.UUID, kabarID uuid.UUID) error
+}
+
+var (
+	ErrKabarTerbaruNotFound     = errors.New("Kabar Terbaru Tidak Ditemukan")
+	ErrKabarTerbaruEventHidden  = errors.New("Kabar Terbaru Hanya Bisa Ditulis Untuk Event Yang Sudah Tayang")
+	ErrKabarTerbaruInvalidDrawn = errors.New("Penarikan Tidak Ditemukan Pada Event Ini")
+)
+
+// KabarTerbaruNotifier dipanggil setelah kabar terbaru dipublikasikan.
+type KabarTerbaruNotifier interface {
+	NotifyKabarTerbaru(ctx context.Context, event entities.Event, kabar entities.KabarTerbaru) error
+}
+
+type kabarTerbaruService struct {
+	kabarTerbaruRepository repository.KabarTerbaruRepository
+	eventRepository        repository.EventRepository
+	penarikanRepository    repository.PenarikanRepository
+	notifier               KabarTerbaruNotifier
+}
+
+func NewKabarTerbaruService(kr repository.KabarTerbaruRepository, er repository.EventRepository, pr repository.PenarikanRepository, notifier KabarTerbaruNotifier) KabarTerbaruService {
+	return &kabarTerbaruService{
+		kabarTerbaruRepository: kr,
+		eventRepository:        er,
+		penarikanRepository:    pr,
+		notifier:               notifier,
+	}
+}
+
+func toKabarTerbaruGambar(urls []string) []entities.KabarTerbaruGambar {
+	gambar := make([]entities.KabarTerbaruGambar, 0, len(urls))
+	for i, url := range urls {
+		gambar = append(gambar, entities.KabarTerbaruGambar{URL: url, Urutan: i})
+	}
+	return gambar
+}
+
+func (ks *kabarTerbaruService) CreateKabarTerbaru(ctx context.Context, actor Actor, eventID uuid.UUID, kabarDTO dto.KabarTerbaruCreateDTO) (entities.KabarTerbaru, error) {
+	event, err := ks.eventRepository.GetEventByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.KabarTerbaru{}, ErrResourceNotFound
+		}
+		return entities.KabarTerbaru{}, err
+	}
+	if !entities.IsEventPublicStatus(event.Status) {
+		return entities.KabarTerbaru{}, ErrKabarTerbaruEventHidden
+	}
+
+	if kabarDTO.HistoryPenarikanID != nil {
+		if err := ks.checkPenarikan(ctx, eventID, *kabarDTO.HistoryPenarikanID); err != nil {
+			return entities.KabarTerbaru{}, err
+		}
+	}
+
+	kabar, err := ks.kabarTerbaruRepository.CreateKabarTerbaru(ctx, entities.KabarTerbaru{
+		Judul:              kabarDTO.Judul,
+		Isi:                kabarDTO.Isi,
+		Gambar:             toKabarTerbaruGambar(kabarDTO.Gambar),
+		HistoryPenarikanID: kabarDTO.HistoryPenarikanID,
+		EventID:            eventID,
+		UserID:             actor.UserID,
+	})
+	if err != nil {
+		return entities.KabarTerbaru{}, err
+	}
+
+	// Notifikasi dikirim di belakang agar request tidak menunggu semua email terkirim
+	go func() {
+		if err := ks.notifier.NotifyKabarTerbaru(context.Background(), event, kabar); err != nil {
+			log.Printf("error notifying donors of event %s: %v", event.ID, err)
+		}
+	}()
+	return kabar, nil
+}
+
+func (ks *kabarTerbaruService) GetKabarTerbaruByEventID(ctx context.Context, eventID uuid.UUID) ([]entities.KabarTerbaru, error) {
+	return ks.kabarTerbaruRepository.GetKabarTerbaruByEventID(ctx, eventID)
+}
+
+func (ks *kabarTerbaruService) UpdateKabarTerbaru(ctx context.Context, eventID uuid.UUID, kabarID uuid.UUID, kabarDTO dto.KabarTerbaruUpdateDTO) (entities.KabarTerbaru, error) {
+	kabar, err := ks.getKabarTerbaru(ctx, eventID, kabarID)
+	if err != nil {
+		return entities.KabarTerbaru{}, err
+	}
+
+	if kabarDTO.Judul != nil {
+		kabar.Judul = *kabarDTO.Judul
+	}
+	if kabarDTO.Isi != nil {
+		kabar.Isi = *kabarDTO.Isi
+	}
+	if kabarDTO.HistoryPenarikanID != nil {
+		if *kabarDTO.HistoryPenarikanID == 0 {
+			kabar.HistoryPenarikanID = nil
+		} else {
+			if err := ks.checkPenarikan(ctx, eventID, *kabarDTO.HistoryPenarikanID); err != nil {
+				return entities.KabarTerbaru{}, err
+			}
+			kabar.HistoryPenarikanID = kabarDTO.HistoryPenarikanID
+		}
+	}
+	if kabarDTO.Gambar != nil {
+		kabar.Gambar = toKabarTerbaruGambar(*kabarDTO.Gambar)
+	}
+	kabar.UpdatedAt = time.Now()
+
+	return ks.kabarTerbaruRepository.UpdateKabarTerbaru(ctx, kabar, kabarDTO.Gambar != nil)
+}
+
+func (ks *kabarTerbaruService) DeleteKabarTerbaru(ctx context.Context, eventID uuid.UUID, kabarID uuid.UUID) error {
+	if _, err := ks.getKabarTerbaru(ctx, eventID, kabarID); err != nil {
+		return err
+	}
+	return ks.kabarTerbaruRepository.DeleteKabarTerbaru(ctx, kabarID)
+}
+
+// getKabarTerbaru memastikan kabar memang milik event pada path.
+func (ks *kabarTerbaruService) getKabarTerbaru(ctx context.Context, eventID uuid.UUID, kabarID uuid.UUID) (entities.KabarTerbaru, error) {
+	kabar, err := ks.kabarTerbaruRepository.GetKabarTerbaruByID(ctx, kabarID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.KabarTerbaru{}, ErrKabarTerbaruNotFound
+		}
+		return entities.KabarTerbaru{}, err
+	}
+	if kabar.EventID != eventID {
+		return entities.KabarTerbaru{}, ErrKabarTerbaruNotFound
+	}
+	return kabar, nil
+}
+
+func (ks *kabarTerbaruService) checkPenarikan(ctx context.Context, eventID uuid.UUID, penarikanID uint) error {
+	penarikan, err := ks.penarikanRepository.GetPenarikanByID(ctx, penarikanID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrKabarTerbaruInvalidDrawn
+		}
+		return err
+	}
+	if penarikan.EventID != eventID {
+		return ErrKabarTerbaruInvalidDrawn
+	}
+	return nil
+}
+
+type emailKabarTerbaruNotifier struct {
+	transaksiRepository repository.TransaksiRepository
+	mailer              Mailer
+}
+
+// NewEmailKabarTerbaruNotifier mengirim email ke setiap user yang pernah
+// berdonasi pada event.
+func NewEmailKabarTerbaruNotifier(tr repository.TransaksiRepository, mailer Mailer) KabarTerbaruNotifier {
+	return &emailKabarTerbaruNotifier{
+		transaksiRepository: tr,
+		mailer:              mailer,
+	}
+}
+
+func (en *emailKabarTerbaruNotifier) NotifyKabarTerbaru(ctx context.Context, event entities.Event, kabar entities.KabarTerbaru) error {
+	emails, err := en.transaksiRepository.GetDonorEmailsByEventID(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Kabar Terbaru: %s", event.JudulEvent)
+	body := fmt.Sprintf("Ada kabar terbaru dari event \"%s\" yang Anda dukung.\n\n%s\n\n%s", event.JudulEvent, kabar.Judul, kabar.Isi)
+	for _, email := range emails {
+		if err := en.mailer.Send(ctx, email, subject, body); err != nil {
+			log.Printf("error sending kabar terbaru %s to %s: %v", kabar.ID, email, err)
+		}
+	}
+	return nil
+}
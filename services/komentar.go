@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type KomentarService interface {
+	CreateKomentar(ctx context.Context, actor Actor, eventID uuid.UUID, komentarDTO dto.KomentarCreateDTO) (entities.Komentar, error)
+	GetKomentarByEventID(ctx context.Context, eventID uuid.UUID, includeHidden bool, listQuery dto.KomentarListQuery) ([]entities.Komentar, dto.PaginationMeta, error)
+	GetReportedKomentar(ctx context.Context, listQuery dto.KomentarListQuery) ([]entities.Komentar, dto.PaginationMeta, error)
+	UpdateKomentar(ctx context.Context, actor Actor, komentarID uuid.UUID, komentarDTO dto.KomentarUpdateDTO) (entities.Komentar, error)
+	DeleteKomentar(ctx context.Context, actor Actor, komentarID uuid.UUID) error
+	ReportKomentar(ctx context.Context, actor Actor, komentarID uuid.UUID, reportDTO dto.KomentarReportDTO) error
+	HideKomentar(ctx context.Context, actor Actor, komentarID uuid.UUID, hideDTO dto.KomentarHideDTO) (entities.Komentar, error)
+	UnhideKomentar(ctx context.Context, actor Actor, komentarID uuid.UUID) (entities.Komentar, error)
+}
+
+var (
+	ErrKomentarNotFound         = errors.New("Komentar Tidak Ditemukan")
+	ErrKomentarParentInvalid    = errors.New("Komentar Yang Dibalas Tidak Ditemukan Pada Event Ini")
+	ErrKomentarTransaksiInvalid = errors.New("Doa Hanya Bisa Disertakan Pada Donasi Milik Sendiri Di Event Ini")
+	ErrKomentarDoaReply         = errors.New("Doa Tidak Bisa Dikirim Sebagai Balasan")
+	ErrKomentarAlreadyReported  = errors.New("Komentar Sudah Pernah Dilaporkan")
+	ErrKomentarEventHidden      = errors.New("Event Belum Bisa Dikomentari")
+)
+
+// KomentarBlockedError dikembalikan ketika isi komentar mengandung kata yang
+// masuk daftar KOMENTAR_BLOCKED_WORDS.
+type KomentarBlockedError struct {
+	Word string
+}
+
+func (e *KomentarBlockedError) Error() string {
+	return fmt.Sprintf("Komentar Mengandung Kata Yang Tidak Diizinkan: %s", e.Word)
+}
+
+// KomentarRateLimitedError dikembalikan ketika user mengirim terlalu banyak
+// komentar dalam satu window.
+type KomentarRateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *KomentarRateLimitedError) Error() string {
+	return fmt.Sprintf("Terlalu Banyak Komentar, Coba Lagi Dalam %d Detik", int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+const komentarAutoHideReason = "Disembunyikan otomatis karena banyak laporan"
+
+type komentarService struct {
+	komentarRepository  repository.KomentarRepository
+	eventRepository     repository.EventRepository
+	transaksiRepository repository.TransaksiRepository
+	wordFilter          *helpers.WordFilter
+	rateLimit           int
+	rateWindow          time.Duration
+	hideThreshold       int
+}
+
+func NewKomentarService(kr repository.KomentarRepository, er repository.EventRepository, tr repository.TransaksiRepository) KomentarService {
+	return &komentarService{
+		komentarRepository:  kr,
+		eventRepository:     er,
+		transaksiRepository: tr,
+		wordFilter:          helpers.NewWordFilter(helpers.ParseWordList(os.Getenv("KOMENTAR_BLOCKED_WORDS"))),
+		rateLimit:           helpers.GetEnvInt("KOMENTAR_RATE_LIMIT", 5),
+		rateWindow:          time.Duration(helpers.GetEnvInt("KOMENTAR_RATE_WINDOW_SECONDS", 60)) * time.Second,
+		hideThreshold:       helpers.GetEnvInt("KOMENTAR_REPORT_HIDE_THRESHOLD", 5),
+	}
+}
+
+func (ks *komentarService) checkIsi(isi string) error {
+	if word, found := ks.wordFilter.Find(isi); found {
+		return &KomentarBlockedError{Word: word}
+	}
+	return nil
+}
+
+// checkRateLimit memakai counter terpisah dari tabel komentar, sehingga
+// menghapus komentar tidak mengembalikan jatah komentar user.
+func (ks *komentarService) checkRateLimit(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	rate, err := ks.komentarRepository.IncrementKomentarRate(ctx, userID, now, ks.rateWindow)
+	if err != nil {
+		return err
+	}
+	if rate.Total <= ks.rateLimit {
+		return nil
+	}
+	return &KomentarRateLimitedError{RetryAfter: rate.WindowStart.Add(ks.rateWindow).Sub(now)}
+}
+
+func (ks *komentarService) CreateKomentar(ctx context.Context, actor Actor, eventID uuid.UUID, komentarDTO dto.KomentarCreateDTO) (entities.Komentar, error) {
+	isi := strings.TrimSpace(komentarDTO.Isi)
+	if err := ks.checkIsi(isi); err != nil {
+		return entities.Komentar{}, err
+	}
+
+	event, err := ks.eventRepository.GetEventByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Komentar{}, ErrResourceNotFound
+		}
+		return entities.Komentar{}, err
+	}
+	if !entities.IsEventPublicStatus(event.Status) {
+		return entities.Komentar{}, ErrKomentarEventHidden
+	}
+
+	komentar := entities.Komentar{
+		Isi:     isi,
+		EventID: eventID,
+		UserID:  actor.UserID,
+	}
+
+	if komentarDTO.ParentID != nil {
+		if komentarDTO.TransaksiID != nil {
+			return entities.Komentar{}, ErrKomentarDoaReply
+		}
+		parent, err := ks.komentarRepository.GetKomentarByID(ctx, *komentarDTO.ParentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entities.Komentar{}, ErrKomentarParentInvalid
+			}
+			return entities.Komentar{}, err
+		}
+		if parent.EventID != eventID || parent.HiddenAt != nil || parent.DeletedAt != nil {
+			return entities.Komentar{}, ErrKomentarParentInvalid
+		}
+		// Balasan untuk balasan tetap dikelompokkan di bawah komentar utama
+		if parent.ParentID != nil {
+			komentar.ParentID = parent.ParentID
+		} else {
+			komentar.ParentID = &parent.ID
+		}
+	}
+
+	if komentarDTO.TransaksiID != nil {
+		transaksi, err := ks.transaksiRepository.GetTransaksiByID(ctx, *komentarDTO.TransaksiID)
+		if err != nil {
+			return entities.Komentar{}, err
+		}
+		if transaksi.ID == uuid.Nil || transaksi.EventID != eventID || transaksi.UserID != actor.UserID {
+			return entities.Komentar{}, ErrKomentarTransaksiInvalid
+		}
+		komentar.TransaksiID = &transaksi.ID
+	}
+
+	if err := ks.checkRateLimit(ctx, actor.UserID, time.Now()); err != nil {
+		return entities.Komentar{}, err
+	}
+	return ks.komentarRepository.CreateKomentar(ctx, komentar)
+}
+
+func (ks *komentarService) GetKomentarByEventID(ctx context.Context, eventID uuid.UUID, includeHidden bool, listQuery dto.KomentarListQuery) ([]entities.Komentar, dto.PaginationMeta, error) {
+	page, perPage := normalizePage(listQuery.Page, listQuery.PerPage)
+	komentar, total, err := ks.komentarRepository.GetKomentarByEventID(ctx, eventID, includeHidden, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+	return komentar, newPaginationMeta(page, perPage, total), nil
+}
+
+func (ks *komentarService) GetReportedKomentar(ctx context.Context, listQuery dto.KomentarListQuery) ([]entities.Komentar, dto.PaginationMeta, error) {
+	page, perPage := normalizePage(listQuery.Page, listQuery.PerPage)
+	komentar, total, err := ks.komentarRepository.GetReportedKomentar(ctx, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+	return komentar, newPaginationMeta(page, perPage, total), nil
+}
+
+// getKomentar menganggap komentar yang sudah dihapus tidak ada.
+func (ks *komentarService) getKomentar(ctx context.Context, komentarID uuid.UUID) (entities.Komentar, error) {
+	komentar, err := ks.komentarRepository.GetKomentarByID(ctx, komentarID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Komentar{}, ErrKomentarNotFound
+		}
+		return entities.Komentar{}, err
+	}
+	if komentar.DeletedAt != nil {
+		return entities.Komentar{}, ErrKomentarNotFound
+	}
+	return komentar, nil
+}
+
+// UpdateKomentar dan DeleteKomentar hanya boleh dilakukan oleh penulis
+// komentar. Pemilik event dan admin cukup menyembunyikan komentar.
+func (ks *komentarService) UpdateKomentar(ctx context.Context, actor Actor, komentarID uuid.UUID, komentarDTO dto.KomentarUpdateDTO) (entities.Komentar, error) {
+	komentar, err := ks.getKomentar(ctx, komentarID)
+	if err != nil {
+		return entities.Komentar{}, err
+	}
+	if komentar.UserID != actor.UserID {
+		return entities.Komentar{}, ErrForbidden
+	}
+
+	isi := strings.TrimSpace(komentarDTO.Isi)
+	if err := ks.checkIsi(isi); err != nil {
+		return entities.Komentar{}, err
+	}
+	if err := ks.komentarRepository.UpdateKomentarIsi(ctx, komentarID, isi, time.Now()); err != nil {
+		return entities.Komentar{}, err
+	}
+	return ks.getKomentar(ctx, komentarID)
+}
+
+func (ks *komentarService) DeleteKomentar(ctx context.Context, actor Actor, komentarID uuid.UUID) error {
+	komentar, err := ks.getKomentar(ctx, komentarID)
+	if err != nil {
+		return err
+	}
+	if komentar.UserID != actor.UserID {
+		return ErrForbidden
+	}
+	return ks.komentarRepository.DeleteKomentar(ctx, komentarID, time.Now())
+}
+
+func (ks *komentarService) ReportKomentar(ctx context.Context, actor Actor, komentarID uuid.UUID, reportDTO dto.KomentarReportDTO) error {
+	komentar, err := ks.getKomentar(ctx, komentarID)
+	if err != nil {
+		return err
+	}
+	if komentar.UserID == actor.UserID {
+		return ErrForbidden
+	}
+
+	reported, err := ks.komentarRepository.ReportKomentar(ctx, entities.KomentarReport{
+		KomentarID: komentarID,
+		UserID:     actor.UserID,
+		Alasan:     strings.TrimSpace(reportDTO.Alasan),
+		CreatedAt:  time.Now(),
+	}, ks.hideThreshold, komentarAutoHideReason)
+	if err != nil {
+		return err
+	}
+	if !reported {
+		return ErrKomentarAlreadyReported
+	}
+	return nil
+}
+
+// authorizeModeration mengizinkan pemilik event dari komentar dan admin.
+func (ks *komentarService) authorizeModeration(ctx context.Context, actor Actor, komentarID uuid.UUID) (entities.Komentar, error) {
+	komentar, err := ks.getKomentar(ctx, komentarID)
+	if err != nil {
+		return entities.Komentar{}, err
+	}
+	ownerID, err := ks.eventRepository.GetEventOwnerID(ctx, komentar.EventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Komentar{}, ErrKomentarNotFound
+		}
+		return entities.Komentar{}, err
+	}
+	if !canManage(actor, ownerID) {
+		return entities.Komentar{}, ErrForbidden
+	}
+	return komentar, nil
+}
+
+func (ks *komentarService) HideKomentar(ctx context.Context, actor Actor, komentarID uuid.UUID, hideDTO dto.KomentarHideDTO) (entities.Komentar, error) {
+	if _, err := ks.authorizeModeration(ctx, actor, komentarID); err != nil {
+		return entities.Komentar{}, err
+	}
+	now := time.Now()
+	if err := ks.komentarRepository.SetKomentarHidden(ctx, komentarID, &now, &actor.UserID, strings.TrimSpace(hideDTO.Alasan)); err != nil {
+		return entities.Komentar{}, err
+	}
+	return ks.getKomentar(ctx, komentarID)
+}
+
+func (ks *komentarService) UnhideKomentar(ctx context.Context, actor Actor, komentarID uuid.UUID) (entities.Komentar, error) {
+	if _, err := ks.authorizeModeration(ctx, actor, komentarID); err != nil {
+		return entities.Komentar{}, err
+	}
+	if err := ks.komentarRepository.SetKomentarHidden(ctx, komentarID, nil, nil, ""); err != nil {
+		return entities.Komentar{}, err
+	}
+	return ks.getKomentar(ctx, komentarID)
+}
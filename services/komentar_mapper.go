@@ -0,0 +1,58 @@
+package services
+
+import (
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+)
+
+const komentarDeletedIsi = "Komentar dihapus"
+
+// ToKomentarResponse mengisi data moderasi hanya jika moderator bernilai
+// true, yaitu untuk pemilik event dan admin. Komentar yang sudah dihapus
+// hanya ditampilkan sebagai penanda tanpa isi dan penulis.
+func ToKomentarResponse(komentar entities.Komentar, moderator bool) dto.KomentarResponse {
+	if komentar.DeletedAt != nil {
+		return dto.KomentarResponse{
+			ID:        komentar.ID,
+			Isi:       komentarDeletedIsi,
+			Deleted:   true,
+			EventID:   komentar.EventID,
+			CreatedAt: komentar.CreatedAt,
+			Balasan:   toKomentarBalasanResponses(komentar.Balasan, moderator),
+		}
+	}
+
+	res := dto.KomentarResponse{
+		ID:        komentar.ID,
+		Isi:       komentar.Isi,
+		IsDoa:     komentar.TransaksiID != nil,
+		ParentID:  komentar.ParentID,
+		EventID:   komentar.EventID,
+		UserID:    komentar.UserID,
+		NamaUser:  komentar.User.Nama,
+		EditedAt:  komentar.EditedAt,
+		CreatedAt: komentar.CreatedAt,
+	}
+	res.Balasan = toKomentarBalasanResponses(komentar.Balasan, moderator)
+	if moderator {
+		res.Hidden = komentar.HiddenAt != nil
+		res.HiddenReason = komentar.HiddenReason
+		res.ReportCount = komentar.ReportCount
+	}
+	return res
+}
+
+func ToKomentarResponses(komentar []entities.Komentar, moderator bool) []dto.KomentarResponse {
+	res := make([]dto.KomentarResponse, 0, len(komentar))
+	for _, k := range komentar {
+		res = append(res, ToKomentarResponse(k, moderator))
+	}
+	return res
+}
+
+func toKomentarBalasanResponses(balasan []entities.Komentar, moderator bool) []dto.KomentarResponse {
+	if len(balasan) == 0 {
+		return nil
+	}
+	return ToKomentarResponses(balasan, moderator)
+}
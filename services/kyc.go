@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type KYCService interface {
+	SubmitKYC(ctx context.Context, userID uuid.UUID, kycDTO dto.KYCSubmitDTO, ktp *multipart.FileHeader, selfie *multipart.FileHeader) (dto.KYCResponse, error)
+	GetMyKYC(ctx context.Context, userID uuid.UUID) (dto.KYCResponse, error)
+	GetKYCQueue(ctx context.Context, status string) ([]dto.KYCAdminResponse, error)
+	GetKYCByID(ctx context.Context, kycID uuid.UUID) (dto.KYCAdminResponse, error)
+	GetKYCDocumentPath(ctx context.Context, kycID uuid.UUID, document string) (string, error)
+	ApproveKYC(ctx context.Context, reviewerID uuid.UUID, kycID uuid.UUID) error
+	RejectKYC(ctx context.Context, reviewerID uuid.UUID, kycID uuid.UUID, reason string) error
+	GetApprovedIdentity(ctx context.Context, userID uuid.UUID) (dto.KYCIdentity, error)
+}
+
+const (
+	KYCDocumentKTP     = "ktp"
+	KYCDocumentSelfie  = "selfie"
+	maxKYCDocumentSize = 5 << 20
+)
+
+var ErrKYCNotApproved = errors.New("Verifikasi Identitas (KYC) Belum Disetujui")
+
+var allowedKYCDocumentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+type kycService struct {
+	kycRepository repository.KYCRepository
+	jwtService    JWTService
+	uploadDir     string
+}
+
+func NewKYCService(kr repository.KYCRepository, jwtService JWTService) KYCService {
+	return &kycService{
+		kycRepository: kr,
+		jwtService:    jwtService,
+		uploadDir:     getKYCUploadDir(),
+	}
+}
+
+func getKYCUploadDir() string {
+	dir := os.Getenv("KYC_UPLOAD_DIR")
+	if dir == "" {
+		dir = "uploads/kyc"
+	}
+	return dir
+}
+
+func (ks *kycService) SubmitKYC(ctx context.Context, userID uuid.UUID, kycDTO dto.KYCSubmitDTO, ktp *multipart.FileHeader, selfie *multipart.FileHeader) (dto.KYCResponse, error) {
+	existing, err := ks.kycRepository.GetKYCByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.KYCResponse{}, err
+	}
+	if err == nil && existing.Status != entities.KYCStatusRejected {
+		return dto.KYCResponse{}, errors.New("Pengajuan KYC Sudah Ada")
+	}
+
+	now := time.Now()
+	info, err := helpers.ParseNIK(kycDTO.NIK, now)
+	if err != nil {
+		return dto.KYCResponse{}, err
+	}
+
+	birthDate, err := time.Parse("2006-01-02", kycDTO.TanggalLahir)
+	if err != nil {
+		return dto.KYCResponse{}, errors.New("Format Tanggal Lahir Harus YYYY-MM-DD")
+	}
+	if !birthDate.Equal(info.BirthDate) {
+		return dto.KYCResponse{}, errors.New("Tanggal Lahir Tidak Sesuai Dengan NIK")
+	}
+	if kycDTO.JenisKelamin != info.Gender {
+		return dto.KYCResponse{}, errors.New("Jenis Kelamin Tidak Sesuai Dengan NIK")
+	}
+
+	nikHash := helpers.HashNIK(kycDTO.NIK)
+	if used, err := ks.kycRepository.IsNIKUsed(ctx, nikHash, userID); err != nil {
+		return dto.KYCResponse{}, err
+	} else if used {
+		return dto.KYCResponse{}, errors.New("NIK Sudah Digunakan Akun Lain")
+	}
+
+	nikEncrypted, err := helpers.EncryptString(kycDTO.NIK)
+	if err != nil {
+		return dto.KYCResponse{}, err
+	}
+
+	ktpPath, err := ks.saveDocument(userID, KYCDocumentKTP, ktp)
+	if err != nil {
+		return dto.KYCResponse{}, err
+	}
+	selfiePath, err := ks.saveDocument(userID, KYCDocumentSelfie, selfie)
+	if err != nil {
+		os.Remove(ktpPath)
+		return dto.KYCResponse{}, err
+	}
+
+	kyc, err := ks.kycRepository.SaveKYC(ctx, entities.UserKYC{
+		NIKEncrypted: nikEncrypted,
+		NIKHash:      nikHash,
+		NamaLengkap:  kycDTO.NamaLengkap,
+		TanggalLahir: info.BirthDate,
+		JenisKelamin: info.Gender,
+		Pekerjaan:    kycDTO.Pekerjaan,
+		AsalInstansi: kycDTO.AsalInstansi,
+		KTPPath:      ktpPath,
+		SelfiePath:   selfiePath,
+		Status:       entities.KYCStatusPending,
+		SubmittedAt:  now,
+		UserID:       userID,
+	})
+	if err != nil {
+		os.Remove(ktpPath)
+		os.Remove(selfiePath)
+		return dto.KYCResponse{}, err
+	}
+
+	// Dokumen dari pengajuan yang ditolak sebelumnya tidak dibutuhkan lagi
+	if existing.ID != uuid.Nil {
+		os.Remove(existing.KTPPath)
+		os.Remove(existing.SelfiePath)
+	}
+	return toKYCResponse(kyc, helpers.MaskNIK(kycDTO.NIK)), nil
+}
+
+func (ks *kycService) GetMyKYC(ctx context.Context, userID uuid.UUID) (dto.KYCResponse, error) {
+	kyc, err := ks.kycRepository.GetKYCByUserID(ctx, userID)
+	if err != nil {
+		return dto.KYCResponse{}, err
+	}
+	nik, err := helpers.DecryptString(kyc.NIKEncrypted)
+	if err != nil {
+		return dto.KYCResponse{}, err
+	}
+	return toKYCResponse(kyc, helpers.MaskNIK(nik)), nil
+}
+
+func (ks *kycService) GetKYCQueue(ctx context.Context, status string) ([]dto.KYCAdminResponse, error) {
+	if status == "" {
+		status = entities.KYCStatusPending
+	}
+	kycs, err := ks.kycRepository.GetKYCByStatus(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.KYCAdminResponse, 0, len(kycs))
+	for _, kyc := range kycs {
+		item, err := toKYCAdminResponse(kyc)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+func (ks *kycService) GetKYCByID(ctx context.Context, kycID uuid.UUID) (dto.KYCAdminResponse, error) {
+	kyc, err := ks.kycRepository.GetKYCByID(ctx, kycID)
+	if err != nil {
+		return dto.KYCAdminResponse{}, err
+	}
+	return toKYCAdminResponse(kyc)
+}
+
+func (ks *kycService) GetKYCDocumentPath(ctx context.Context, kycID uuid.UUID, document string) (string, error) {
+	kyc, err := ks.kycRepository.GetKYCByID(ctx, kycID)
+	if err != nil {
+		return "", err
+	}
+	switch document {
+	case KYCDocumentKTP:
+		return kyc.KTPPath, nil
+	case KYCDocumentSelfie:
+		return kyc.SelfiePath, nil
+	}
+	return "", errors.New("Jenis Dokumen Tidak Dikenali")
+}
+
+func (ks *kycService) ApproveKYC(ctx context.Context, reviewerID uuid.UUID, kycID uuid.UUID) error {
+	kyc, err := ks.kycRepository.GetKYCByID(ctx, kycID)
+	if err != nil {
+		return err
+	}
+
+	// User biasa otomatis menjadi campaigner setelah identitasnya terverifikasi
+	approved, err := ks.kycRepository.ApproveKYC(ctx, kycID, reviewerID, entities.RoleUser, entities.RoleCampaigner)
+	if err != nil {
+		return err
+	}
+	if !approved {
+		return errors.New("Pengajuan KYC Sudah Direview")
+	}
+
+	// Role disimpan sebagai claim di access token, token lama dicabut agar
+	// role campaigner langsung berlaku
+	return ks.jwtService.InvalidateAllUserToken(kyc.UserID)
+}
+
+func (ks *kycService) RejectKYC(ctx context.Context, reviewerID uuid.UUID, kycID uuid.UUID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("Alasan Penolakan Wajib Diisi")
+	}
+
+	reviewed, err := ks.kycRepository.ReviewKYC(ctx, kycID, entities.KYCStatusRejected, reason, reviewerID)
+	if err != nil {
+		return err
+	}
+	if !reviewed {
+		return errors.New("Pengajuan KYC Tidak Ditemukan Atau Sudah Direview")
+	}
+	return nil
+}
+
+// GetApprovedIdentity mengembalikan ErrKYCNotApproved jika user belum lolos KYC.
+func (ks *kycService) GetApprovedIdentity(ctx context.Context, userID uuid.UUID) (dto.KYCIdentity, error) {
+	kyc, err := ks.kycRepository.GetKYCByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.KYCIdentity{}, ErrKYCNotApproved
+		}
+		return dto.KYCIdentity{}, err
+	}
+	if kyc.Status != entities.KYCStatusApproved {
+		return dto.KYCIdentity{}, ErrKYCNotApproved
+	}
+
+	nik, err := helpers.DecryptString(kyc.NIKEncrypted)
+	if err != nil {
+		return dto.KYCIdentity{}, err
+	}
+	return dto.KYCIdentity{
+		NIK:          nik,
+		Pekerjaan:    kyc.Pekerjaan,
+		AsalInstansi: kyc.AsalInstansi,
+	}, nil
+}
+
+// saveDocument menyimpan dokumen di luar direktori publik. Tipe file dicek
+// dari isinya, bukan dari nama file atau header yang dikirim client.
+func (ks *kycService) saveDocument(userID uuid.UUID, document string, file *multipart.FileHeader) (string, error) {
+	if file == nil {
+		return "", fmt.Errorf("Dokumen %s Wajib Diunggah", strings.ToUpper(document))
+	}
+	if file.Size > maxKYCDocumentSize {
+		return "", fmt.Errorf("Ukuran Dokumen %s Maksimal 5MB", strings.ToUpper(document))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	ext, ok := allowedKYCDocumentTypes[http.DetectContentType(head[:n])]
+	if !ok {
+		return "", fmt.Errorf("Dokumen %s Harus Berupa JPG Atau PNG", strings.ToUpper(document))
+	}
+
+	dir := filepath.Join(ks.uploadDir, userID.String())
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, document+"-"+uuid.NewString()+ext)
+
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err := dst.Write(head[:n]); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	if _, err := io.Copy(dst, io.LimitReader(src, maxKYCDocumentSize)); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+func toKYCResponse(kyc entities.UserKYC, nik string) dto.KYCResponse {
+	return dto.KYCResponse{
+		ID:              kyc.ID,
+		NIK:             nik,
+		NamaLengkap:     kyc.NamaLengkap,
+		TanggalLahir:    kyc.TanggalLahir,
+		JenisKelamin:    kyc.JenisKelamin,
+		Pekerjaan:       kyc.Pekerjaan,
+		AsalInstansi:    kyc.AsalInstansi,
+		Status:          kyc.Status,
+		RejectionReason: kyc.RejectionReason,
+		SubmittedAt:     kyc.SubmittedAt,
+		ReviewedAt:      kyc.ReviewedAt,
+	}
+}
+
+func toKYCAdminResponse(kyc entities.UserKYC) (dto.KYCAdminResponse, error) {
+	nik, err := helpers.DecryptString(kyc.NIKEncrypted)
+	if err != nil {
+		return dto.KYCAdminResponse{}, err
+	}
+
+	res := dto.KYCAdminResponse{
+		KYCResponse: toKYCResponse(kyc, nik),
+		UserID:      kyc.UserID,
+		ReviewedBy:  kyc.ReviewedBy,
+		KTPURL:      fmt.Sprintf("/api/kyc/%s/document/%s", kyc.ID, KYCDocumentKTP),
+		SelfieURL:   fmt.Sprintf("/api/kyc/%s/document/%s", kyc.ID, KYCDocumentSelfie),
+	}
+	if info, err := helpers.ParseNIK(nik, kyc.SubmittedAt); err == nil {
+		res.Province = info.Province
+	}
+	if kyc.User.ID != uuid.Nil {
+		user := ToUserPublicResponse(kyc.User)
+		res.User = &user
+	}
+	return res, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeKYCRepository menyimpan pengajuan KYC di memori. ApproveKYC ikut
+// mengubah role di fakeUserRepository seperti transaksi aslinya.
+type fakeKYCRepository struct {
+	repository.KYCRepository
+	kycs  map[uuid.UUID]entities.UserKYC
+	users *fakeUserRepository
+}
+
+func (fr *fakeKYCRepository) GetKYCByID(ctx context.Context, kycID uuid.UUID) (entities.UserKYC, error) {
+	kyc, ok := fr.kycs[kycID]
+	if !ok {
+		return entities.UserKYC{}, gorm.ErrRecordNotFound
+	}
+	return kyc, nil
+}
+
+func (fr *fakeKYCRepository) ApproveKYC(ctx context.Context, kycID uuid.UUID, reviewerID uuid.UUID, fromRole string, toRole string) (bool, error) {
+	kyc, ok := fr.kycs[kycID]
+	if !ok || kyc.Status != entities.KYCStatusPending {
+		return false, nil
+	}
+	kyc.Status = entities.KYCStatusApproved
+	fr.kycs[kycID] = kyc
+
+	if user := fr.users.users[kyc.UserID]; user.Role == fromRole {
+		user.Role = toRole
+		fr.users.users[kyc.UserID] = user
+	}
+	return true, nil
+}
+
+// fakeRevokedTokenRepository hanya mencatat kapan semua token user dicabut.
+type fakeRevokedTokenRepository struct {
+	repository.RevokedTokenRepository
+	revokedBefore map[uuid.UUID]time.Time
+}
+
+func newFakeRevokedTokenRepository() *fakeRevokedTokenRepository {
+	return &fakeRevokedTokenRepository{revokedBefore: map[uuid.UUID]time.Time{}}
+}
+
+func (fr *fakeRevokedTokenRepository) RevokeAllUserToken(ctx context.Context, userID uuid.UUID, revokedBefore time.Time) error {
+	fr.revokedBefore[userID] = revokedBefore
+	return nil
+}
+
+func TestApproveKYCPromotesUserAndRevokesTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		wantRole string
+	}{
+		{"user menjadi campaigner", entities.RoleUser, entities.RoleCampaigner},
+		{"admin tetap admin", entities.RoleAdmin, entities.RoleAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := entities.User{ID: uuid.New(), Role: tt.role}
+			kyc := entities.UserKYC{ID: uuid.New(), UserID: user.ID, Status: entities.KYCStatusPending}
+			users := newFakeUserRepository(user)
+			revoked := newFakeRevokedTokenRepository()
+			service := NewKYCService(&fakeKYCRepository{kycs: map[uuid.UUID]entities.UserKYC{kyc.ID: kyc}, users: users}, NewJWTService(revoked))
+
+			if err := service.ApproveKYC(context.Background(), uuid.New(), kyc.ID); err != nil {
+				t.Fatal(err)
+			}
+			if got := users.users[user.ID].Role; got != tt.wantRole {
+				t.Errorf("role = %s, want %s", got, tt.wantRole)
+			}
+			if _, ok := revoked.revokedBefore[user.ID]; !ok {
+				t.Error("token lama tidak dicabut setelah KYC disetujui")
+			}
+
+			if err := service.ApproveKYC(context.Background(), uuid.New(), kyc.ID); err == nil {
+				t.Error("pengajuan yang sudah direview bisa disetujui lagi")
+			}
+		})
+	}
+}
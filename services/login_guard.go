@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+)
+
+type LoginGuardService interface {
+	ReserveLoginAttempt(ctx context.Context, email string, ip string) error
+	RecordLoginFailure(ctx context.Context, attempt entities.LoginAttempt) error
+	RecordLoginSuccess(ctx context.Context, attempt entities.LoginAttempt) error
+	UnlockUser(ctx context.Context, userID uuid.UUID) error
+	GetLoginAttempts(ctx context.Context, email string) ([]entities.LoginAttempt, error)
+}
+
+// LoginThrottledError dikembalikan ketika akun atau IP sedang dikunci atau
+// masih dalam masa tunggu backoff.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	seconds := int(math.Ceil(e.RetryAfter.Seconds()))
+	if e.Locked {
+		return fmt.Sprintf("Akun Dikunci Sementara, Coba Lagi Dalam %d Detik", seconds)
+	}
+	return fmt.Sprintf("Terlalu Banyak Percobaan Login, Coba Lagi Dalam %d Detik", seconds)
+}
+
+type loginGuardService struct {
+	loginThrottleRepository repository.LoginThrottleRepository
+	userRepository          repository.UserRepository
+	maxAccountAttempts      int
+	maxIPAttempts           int
+	lockoutDuration         time.Duration
+	backoffBase             time.Duration
+}
+
+func NewLoginGuardService(lr repository.LoginThrottleRepository, ur repository.UserRepository) LoginGuardService {
+	return &loginGuardService{
+		loginThrottleRepository: lr,
+		userRepository:          ur,
+		maxAccountAttempts:      helpers.GetEnvInt("LOGIN_MAX_ATTEMPTS", 5),
+		maxIPAttempts:           helpers.GetEnvInt("LOGIN_MAX_ATTEMPTS_PER_IP", 20),
+		lockoutDuration:         time.Duration(helpers.GetEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		backoffBase:             time.Duration(helpers.GetEnvInt("LOGIN_BACKOFF_BASE_SECONDS", 1)) * time.Second,
+	}
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+type loginLimit struct {
+	key   string
+	limit int
+}
+
+// loginLimits selalu menaruh key IP lebih dulu, sehingga IP yang sedang
+// dibatasi ditolak sebelum key akun ikut diproses.
+func (lg *loginGuardService) loginLimits(email string, ip string) []loginLimit {
+	return []loginLimit{
+		{key: ipThrottleKey(ip), limit: lg.maxIPAttempts},
+		{key: accountThrottleKey(email), limit: lg.maxAccountAttempts},
+	}
+}
+
+// checkThrottle mengembalikan LoginThrottledError jika throttle sedang dikunci
+// atau masih dalam masa backoff. Kegagalan yang lebih lama dari durasi lockout
+// tidak lagi dihitung.
+func (lg *loginGuardService) checkThrottle(throttle *entities.LoginThrottle, limit int, now time.Time) error {
+	if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+		return &LoginThrottledError{RetryAfter: throttle.LockedUntil.Sub(now), Locked: true}
+	}
+	if throttle.Failures > 0 && throttle.LastFailureAt.Before(now.Add(-lg.lockoutDuration)) {
+		throttle.Failures = 0
+	}
+	if throttle.Failures >= limit {
+		return &LoginThrottledError{RetryAfter: throttle.LastFailureAt.Add(lg.lockoutDuration).Sub(now), Locked: true}
+	}
+	if retryAt := throttle.LastFailureAt.Add(lg.backoff(throttle.Failures)); throttle.Failures > 0 && retryAt.After(now) {
+		return &LoginThrottledError{RetryAfter: retryAt.Sub(now)}
+	}
+	return nil
+}
+
+// ReserveLoginAttempt dipanggil sebelum password atau kode dicek. Percobaan
+// langsung dihitung sebagai kegagalan di dalam row lock, sehingga request
+// paralel tidak bisa melewati backoff bersamaan. Login yang berhasil
+// mengembalikan hitungan tersebut lewat RecordLoginSuccess.
+func (lg *loginGuardService) ReserveLoginAttempt(ctx context.Context, email string, ip string) error {
+	now := time.Now()
+	limits := lg.loginLimits(email, ip)
+
+	// Semua key dicek lebih dulu agar percobaan yang ditolak tidak menaikkan
+	// hitungan key lain, misalnya IP yang dibatasi mengganti email target
+	for _, l := range limits {
+		throttle, err := lg.loginThrottleRepository.GetLoginThrottle(ctx, l.key)
+		if err != nil {
+			return err
+		}
+		if err := lg.checkThrottle(&throttle, l.limit, now); err != nil {
+			return err
+		}
+	}
+
+	// Pengecekan diulang di dalam row lock karena request paralel bisa sudah
+	// menaikkan hitungan sejak dibaca di atas
+	for _, l := range limits {
+		err := lg.loginThrottleRepository.UpdateLoginThrottle(ctx, l.key, func(throttle *entities.LoginThrottle) error {
+			if err := lg.checkThrottle(throttle, l.limit, now); err != nil {
+				return err
+			}
+			throttle.Failures++
+			throttle.LastFailureAt = now
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backoff menggandakan waktu tunggu untuk setiap kegagalan berturut-turut,
+// dibatasi maksimal sepanjang durasi lockout.
+func (lg *loginGuardService) backoff(failures int) time.Duration {
+	if failures <= 1 {
+		return 0
+	}
+	delay := lg.backoffBase * time.Duration(math.Pow(2, float64(failures-2)))
+	if delay > lg.lockoutDuration || delay <= 0 {
+		return lg.lockoutDuration
+	}
+	return delay
+}
+
+// RecordLoginFailure mengunci akun atau IP yang sudah mencapai batas. Counter
+// kegagalan sudah dinaikkan oleh ReserveLoginAttempt.
+func (lg *loginGuardService) RecordLoginFailure(ctx context.Context, attempt entities.LoginAttempt) error {
+	now := time.Now()
+	for _, l := range lg.loginLimits(attempt.Email, attempt.IPAddress) {
+		throttle, err := lg.loginThrottleRepository.GetLoginThrottle(ctx, l.key)
+		if err != nil {
+			return err
+		}
+		if throttle.Failures >= l.limit {
+			if err := lg.loginThrottleRepository.LockLoginThrottle(ctx, l.key, now.Add(lg.lockoutDuration)); err != nil {
+				return err
+			}
+		}
+	}
+
+	attempt.Success = false
+	return lg.loginThrottleRepository.CreateLoginAttempt(ctx, attempt)
+}
+
+func (lg *loginGuardService) RecordLoginSuccess(ctx context.Context, attempt entities.LoginAttempt) error {
+	if err := lg.loginThrottleRepository.ResetLoginThrottle(ctx, accountThrottleKey(attempt.Email)); err != nil {
+		return err
+	}
+
+	// Counter IP tidak direset agar penyerang tidak bisa mereset dengan login
+	// ke akunnya sendiri, hanya percobaan yang berhasil ini yang dikembalikan
+	if err := lg.loginThrottleRepository.UpdateLoginThrottle(ctx, ipThrottleKey(attempt.IPAddress), func(throttle *entities.LoginThrottle) error {
+		if throttle.Failures > 0 {
+			throttle.Failures--
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	attempt.Success = true
+	return lg.loginThrottleRepository.CreateLoginAttempt(ctx, attempt)
+}
+
+func (lg *loginGuardService) UnlockUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := lg.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return lg.loginThrottleRepository.ResetLoginThrottle(ctx, accountThrottleKey(user.Email))
+}
+
+func (lg *loginGuardService) GetLoginAttempts(ctx context.Context, email string) ([]entities.LoginAttempt, error) {
+	return lg.loginThrottleRepository.GetLoginAttempts(ctx, email, 100)
+}
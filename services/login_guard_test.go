@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/repository"
+)
+
+// fakeLoginThrottleRepository menyimpan throttle di memori. Seperti
+// repository aslinya, perubahan dari UpdateLoginThrottle hanya disimpan jika
+// fn tidak mengembalikan error.
+type fakeLoginThrottleRepository struct {
+	repository.LoginThrottleRepository
+	throttles map[string]entities.LoginThrottle
+	attempts  []entities.LoginAttempt
+}
+
+func newFakeLoginThrottleRepository() *fakeLoginThrottleRepository {
+	return &fakeLoginThrottleRepository{throttles: map[string]entities.LoginThrottle{}}
+}
+
+func (fr *fakeLoginThrottleRepository) GetLoginThrottle(ctx context.Context, key string) (entities.LoginThrottle, error) {
+	if throttle, ok := fr.throttles[key]; ok {
+		return throttle, nil
+	}
+	return entities.LoginThrottle{Key: key}, nil
+}
+
+func (fr *fakeLoginThrottleRepository) UpdateLoginThrottle(ctx context.Context, key string, fn func(throttle *entities.LoginThrottle) error) error {
+	throttle, _ := fr.GetLoginThrottle(ctx, key)
+	if err := fn(&throttle); err != nil {
+		return err
+	}
+	fr.throttles[key] = throttle
+	return nil
+}
+
+func (fr *fakeLoginThrottleRepository) LockLoginThrottle(ctx context.Context, key string, lockedUntil time.Time) error {
+	throttle := fr.throttles[key]
+	throttle.LockedUntil = &lockedUntil
+	fr.throttles[key] = throttle
+	return nil
+}
+
+func (fr *fakeLoginThrottleRepository) ResetLoginThrottle(ctx context.Context, key string) error {
+	delete(fr.throttles, key)
+	return nil
+}
+
+func (fr *fakeLoginThrottleRepository) CreateLoginAttempt(ctx context.Context, attempt entities.LoginAttempt) error {
+	fr.attempts = append(fr.attempts, attempt)
+	return nil
+}
+
+func newTestLoginGuard(repo repository.LoginThrottleRepository) *loginGuardService {
+	return &loginGuardService{
+		loginThrottleRepository: repo,
+		maxAccountAttempts:      3,
+		maxIPAttempts:           5,
+		lockoutDuration:         time.Hour,
+		// Backoff sekecil mungkin agar percobaan berikutnya langsung boleh
+		backoffBase: time.Nanosecond,
+	}
+}
+
+// failLogin mensimulasikan login dengan password salah.
+func failLogin(guard *loginGuardService, email string, ip string) error {
+	ctx := context.Background()
+	if err := guard.ReserveLoginAttempt(ctx, email, ip); err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond)
+	return guard.RecordLoginFailure(ctx, entities.LoginAttempt{Email: email, IPAddress: ip})
+}
+
+func throttledError(t *testing.T, err error) *LoginThrottledError {
+	t.Helper()
+	var throttled *LoginThrottledError
+	if !errors.As(err, &throttled) {
+		t.Fatalf("err = %v, want LoginThrottledError", err)
+	}
+	return throttled
+}
+
+func TestLoginGuardLocksAccount(t *testing.T) {
+	repo := newFakeLoginThrottleRepository()
+	guard := newTestLoginGuard(repo)
+
+	for i := 0; i < guard.maxAccountAttempts; i++ {
+		if err := failLogin(guard, "korban@mail.com", "10.0.0.1"); err != nil {
+			t.Fatalf("percobaan %d: %v", i+1, err)
+		}
+	}
+
+	// Akun tetap terkunci walaupun percobaan datang dari IP lain
+	err := guard.ReserveLoginAttempt(context.Background(), "KORBAN@mail.com ", "10.0.0.2")
+	if throttled := throttledError(t, err); !throttled.Locked {
+		t.Error("akun harus terkunci setelah batas percobaan")
+	}
+	if repo.throttles[accountThrottleKey("korban@mail.com")].LockedUntil == nil {
+		t.Error("LockedUntil tidak di-set")
+	}
+	if got := repo.throttles[ipThrottleKey("10.0.0.2")].Failures; got != 0 {
+		t.Errorf("percobaan yang ditolak ikut dihitung pada IP: %d", got)
+	}
+	if len(repo.attempts) != guard.maxAccountAttempts {
+		t.Errorf("jumlah audit log = %d, want %d", len(repo.attempts), guard.maxAccountAttempts)
+	}
+}
+
+func TestLoginGuardThrottledIPDoesNotCountAgainstAccounts(t *testing.T) {
+	repo := newFakeLoginThrottleRepository()
+	guard := newTestLoginGuard(repo)
+	guard.maxAccountAttempts = 100
+
+	for i := 0; i < guard.maxIPAttempts; i++ {
+		if err := failLogin(guard, "akun@mail.com", "10.0.0.1"); err != nil {
+			t.Fatalf("percobaan %d: %v", i+1, err)
+		}
+	}
+
+	// IP yang sudah dibatasi mengganti email target, akun tersebut tidak
+	// boleh ikut mendapat hitungan kegagalan
+	for _, email := range []string{"a@mail.com", "b@mail.com", "c@mail.com"} {
+		throttledError(t, guard.ReserveLoginAttempt(context.Background(), email, "10.0.0.1"))
+		if throttle, ok := repo.throttles[accountThrottleKey(email)]; ok && throttle.Failures > 0 {
+			t.Errorf("akun %s mendapat %d kegagalan dari IP yang dibatasi", email, throttle.Failures)
+		}
+	}
+}
+
+func TestLoginGuardBackoff(t *testing.T) {
+	repo := newFakeLoginThrottleRepository()
+	guard := newTestLoginGuard(repo)
+	guard.backoffBase = time.Minute
+
+	// Kegagalan pertama tidak memberi waktu tunggu, kegagalan kedua memberi
+	// waktu tunggu sebesar backoffBase
+	for i := 0; i < 2; i++ {
+		if err := failLogin(guard, "akun@mail.com", "10.0.0.1"); err != nil {
+			t.Fatalf("percobaan %d: %v", i+1, err)
+		}
+	}
+
+	throttled := throttledError(t, guard.ReserveLoginAttempt(context.Background(), "akun@mail.com", "10.0.0.9"))
+	if throttled.Locked {
+		t.Error("backoff tidak boleh dianggap sebagai akun terkunci")
+	}
+	if throttled.RetryAfter <= 0 || throttled.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %v, want (0, 1m]", throttled.RetryAfter)
+	}
+}
+
+func TestLoginGuardBackoffDuration(t *testing.T) {
+	guard := newTestLoginGuard(nil)
+	guard.backoffBase = time.Second
+	guard.lockoutDuration = 10 * time.Second
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{4, 4 * time.Second},
+		{5, 8 * time.Second},
+		{6, 10 * time.Second},
+		{100, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := guard.backoff(tt.failures); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLoginGuardOldFailuresExpire(t *testing.T) {
+	repo := newFakeLoginThrottleRepository()
+	guard := newTestLoginGuard(repo)
+
+	key := accountThrottleKey("akun@mail.com")
+	repo.throttles[key] = entities.LoginThrottle{
+		Key:           key,
+		Failures:      guard.maxAccountAttempts,
+		LastFailureAt: time.Now().Add(-2 * guard.lockoutDuration),
+	}
+
+	if err := guard.ReserveLoginAttempt(context.Background(), "akun@mail.com", "10.0.0.1"); err != nil {
+		t.Fatalf("kegagalan lama masih dihitung: %v", err)
+	}
+	if got := repo.throttles[key].Failures; got != 1 {
+		t.Errorf("Failures = %d, want 1", got)
+	}
+}
+
+func TestLoginGuardSuccess(t *testing.T) {
+	repo := newFakeLoginThrottleRepository()
+	guard := newTestLoginGuard(repo)
+	ctx := context.Background()
+
+	if err := failLogin(guard, "akun@mail.com", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.ReserveLoginAttempt(ctx, "akun@mail.com", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.RecordLoginSuccess(ctx, entities.LoginAttempt{Email: "akun@mail.com", IPAddress: "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := repo.throttles[accountThrottleKey("akun@mail.com")]; ok {
+		t.Error("throttle akun harus direset setelah login berhasil")
+	}
+	// Hanya percobaan yang berhasil yang dikembalikan dari hitungan IP
+	if got := repo.throttles[ipThrottleKey("10.0.0.1")].Failures; got != 1 {
+		t.Errorf("Failures IP = %d, want 1", got)
+	}
+	if last := repo.attempts[len(repo.attempts)-1]; !last.Success {
+		t.Error("audit log login berhasil tidak ditandai Success")
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
+
+// NewMailer memilih implementasi mailer berdasarkan MAIL_DRIVER. Driver
+// "outbox" menulis email ke folder lokal sehingga cocok untuk development.
+func NewMailer() Mailer {
+	switch os.Getenv("MAIL_DRIVER") {
+	case "smtp":
+		return NewSMTPMailer(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USER"),
+			os.Getenv("SMTP_PASS"),
+			os.Getenv("MAIL_FROM"),
+		)
+	default:
+		dir := os.Getenv("MAIL_OUTBOX_DIR")
+		if dir == "" {
+			dir = "outbox"
+		}
+		return NewOutboxMailer(dir, os.Getenv("MAIL_FROM"))
+	}
+}
+
+var headerSanitizer = strings.NewReplacer("\r", "", "\n", "")
+
+func buildMessage(from string, to string, subject string, body string) []byte {
+	var sb strings.Builder
+	sb.WriteString("From: " + headerSanitizer.Replace(from) + "\r\n")
+	sb.WriteString("To: " + headerSanitizer.Replace(to) + "\r\n")
+	sb.WriteString("Subject: " + headerSanitizer.Replace(subject) + "\r\n")
+	sb.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(body)
+	return []byte(sb.String())
+}
+
+type smtpMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(host string, port string, username string, password string, from string) Mailer {
+	if port == "" {
+		port = "587"
+	}
+	return &smtpMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (sm *smtpMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	var auth smtp.Auth
+	if sm.username != "" {
+		auth = smtp.PlainAuth("", sm.username, sm.password, sm.host)
+	}
+	addr := net.JoinHostPort(sm.host, sm.port)
+	return smtp.SendMail(addr, auth, sm.from, []string{to}, buildMessage(sm.from, to, subject, body))
+}
+
+type outboxMailer struct {
+	dir  string
+	from string
+}
+
+func NewOutboxMailer(dir string, from string) Mailer {
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	return &outboxMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (om *outboxMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	if err := os.MkdirAll(om.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), uuid.NewString())
+	return os.WriteFile(filepath.Join(om.dir, name), buildMessage(om.from, to, subject, body), 0o644)
+}
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const testOIDCClientID = "donasi-app"
+
+// fakeOIDCProvider adalah provider OIDC pengganti yang menyediakan discovery,
+// JWKS dan token endpoint. ID token yang dikembalikan diatur oleh test.
+type fakeOIDCProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	idToken   string
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &fakeOIDCProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcProviderMetadata{
+			Issuer:                provider.server.URL,
+			AuthorizationEndpoint: provider.server.URL + "/authorize",
+			TokenEndpoint:         provider.server.URL + "/token",
+			JWKSURI:               provider.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string][]oidcJWK{
+			"keys": {{
+				Kid: "test-key",
+				Kty: "RSA",
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(verifier[:]) != provider.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": provider.idToken})
+	})
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+	return provider
+}
+
+// authorize meniru browser yang membuka halaman login provider, lalu
+// mengembalikan state dan nonce dari URL tersebut.
+func (p *fakeOIDCProvider) authorize(t *testing.T, authorizationURL string) (string, string) {
+	t.Helper()
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	p.challenge = query.Get("code_challenge")
+	return query.Get("state"), query.Get("nonce")
+}
+
+func (p *fakeOIDCProvider) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            p.server.URL,
+		"aud":            testOIDCClientID,
+		"sub":            "provider-user-1",
+		"email":          "oidc@example.com",
+		"email_verified": true,
+		"name":           "OIDC User",
+		"nonce":          nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"auth_time":      now.Unix(),
+	}
+}
+
+func (p *fakeOIDCProvider) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test-key"
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+type fakeOIDCRepository struct {
+	states     map[string]entities.OIDCLoginState
+	identities []entities.UserIdentity
+}
+
+func (r *fakeOIDCRepository) CreateLoginState(ctx context.Context, state entities.OIDCLoginState) error {
+	r.states[state.StateHash] = state
+	return nil
+}
+
+func (r *fakeOIDCRepository) ConsumeLoginState(ctx context.Context, stateHash string, now time.Time) (entities.OIDCLoginState, error) {
+	state, ok := r.states[stateHash]
+	if !ok || !state.ExpiresAt.After(now) {
+		return entities.OIDCLoginState{}, gorm.ErrRecordNotFound
+	}
+	delete(r.states, stateHash)
+	return state, nil
+}
+
+func (r *fakeOIDCRepository) GetUserIdentity(ctx context.Context, issuer string, subject string) (entities.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return entities.UserIdentity{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeOIDCRepository) CreateUserIdentity(ctx context.Context, identity entities.UserIdentity) (entities.UserIdentity, error) {
+	r.identities = append(r.identities, identity)
+	return identity, nil
+}
+
+func newTestOIDCService(t *testing.T) (*fakeOIDCProvider, OIDCService, *fakeOIDCRepository, *fakeUserRepository) {
+	t.Helper()
+	provider := newFakeOIDCProvider(t)
+	t.Setenv("OIDC_ISSUER", provider.server.URL)
+	t.Setenv("OIDC_CLIENT_ID", testOIDCClientID)
+	t.Setenv("OIDC_CLIENT_SECRET", "")
+
+	oidcRepository := &fakeOIDCRepository{states: map[string]entities.OIDCLoginState{}}
+	userRepository := &fakeUserRepository{users: map[uuid.UUID]entities.User{}}
+	service := NewOIDCService(oidcRepository, userRepository, NewJWTService(nil))
+	return provider, service, oidcRepository, userRepository
+}
+
+func TestOIDCHandleCallbackValidatesIDToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(claims jwt.MapClaims)
+		wantErr string
+	}{
+		{
+			name:   "valid token",
+			mutate: func(claims jwt.MapClaims) {},
+		},
+		{
+			name: "email_verified as string",
+			mutate: func(claims jwt.MapClaims) {
+				claims["email_verified"] = "true"
+			},
+		},
+		{
+			name: "wrong nonce",
+			mutate: func(claims jwt.MapClaims) {
+				claims["nonce"] = "nonce-from-another-login"
+			},
+			wantErr: "Nonce",
+		},
+		{
+			name: "wrong issuer",
+			mutate: func(claims jwt.MapClaims) {
+				claims["iss"] = "https://evil.example.com"
+			},
+			wantErr: "Issuer",
+		},
+		{
+			name: "wrong audience",
+			mutate: func(claims jwt.MapClaims) {
+				claims["aud"] = "another-client"
+			},
+			wantErr: "Audience",
+		},
+		{
+			name: "multiple audiences without azp",
+			mutate: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{testOIDCClientID, "another-client"}
+			},
+			wantErr: "Audience",
+		},
+		{
+			name: "expired token",
+			mutate: func(claims jwt.MapClaims) {
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			},
+			wantErr: "ID Token Tidak Valid",
+		},
+		{
+			name: "unverified email",
+			mutate: func(claims jwt.MapClaims) {
+				claims["email_verified"] = false
+			},
+			wantErr: "Belum Terverifikasi",
+		},
+		{
+			name: "missing email_verified",
+			mutate: func(claims jwt.MapClaims) {
+				delete(claims, "email_verified")
+			},
+			wantErr: "Belum Terverifikasi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, service, _, userRepository := newTestOIDCService(t)
+			authorization, err := service.GetAuthorizationURL(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			state, nonce := provider.authorize(t, authorization.URL)
+
+			claims := provider.claims(nonce)
+			tt.mutate(claims)
+			provider.idToken = provider.sign(t, claims)
+
+			result, err := service.HandleCallback(context.Background(), state, authorization.StateCookie, "code")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if len(userRepository.users) != 0 {
+					t.Fatalf("expected no user to be created, got %d", len(userRepository.users))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.User.Email != "oidc@example.com" || result.Reauth != nil {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+		})
+	}
+}
+
+func TestOIDCHandleCallbackRejectsStateReuse(t *testing.T) {
+	provider, service, _, _ := newTestOIDCService(t)
+	authorization, err := service.GetAuthorizationURL(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, nonce := provider.authorize(t, authorization.URL)
+	provider.idToken = provider.sign(t, provider.claims(nonce))
+
+	if _, err := service.HandleCallback(context.Background(), state, authorization.StateCookie, "code"); err != nil {
+		t.Fatalf("first callback failed: %v", err)
+	}
+	if _, err := service.HandleCallback(context.Background(), state, authorization.StateCookie, "code"); err == nil {
+		t.Fatal("expected reused state to be rejected")
+	}
+}
+
+func TestOIDCHandleCallbackRequiresStateCookie(t *testing.T) {
+	provider, service, oidcRepository, _ := newTestOIDCService(t)
+	authorization, err := service.GetAuthorizationURL(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, nonce := provider.authorize(t, authorization.URL)
+	provider.idToken = provider.sign(t, provider.claims(nonce))
+
+	// Browser korban tidak memiliki cookie dari login yang dimulai penyerang
+	for _, cookie := range []string{"", "cookie-from-another-login"} {
+		if _, err := service.HandleCallback(context.Background(), state, cookie, "code"); err == nil {
+			t.Fatalf("expected callback with cookie %q to be rejected", cookie)
+		}
+	}
+	if len(oidcRepository.states) != 1 {
+		t.Fatal("state must not be consumed by a callback from another browser")
+	}
+}
+
+func TestOIDCReauth(t *testing.T) {
+	provider, service, oidcRepository, userRepository := newTestOIDCService(t)
+	user, _ := userRepository.RegisterUser(context.Background(), entities.User{Email: "oidc@example.com"})
+	oidcRepository.identities = append(oidcRepository.identities, entities.UserIdentity{
+		Issuer:  provider.server.URL,
+		Subject: "provider-user-1",
+		UserID:  user.ID,
+	})
+	sessionID := uuid.New()
+
+	tests := []struct {
+		name    string
+		mutate  func(claims jwt.MapClaims)
+		wantErr string
+	}{
+		{
+			name:   "fresh login",
+			mutate: func(claims jwt.MapClaims) {},
+		},
+		{
+			name: "provider reused an old session",
+			mutate: func(claims jwt.MapClaims) {
+				claims["auth_time"] = time.Now().Add(-time.Hour).Unix()
+			},
+			wantErr: "Login Ulang",
+		},
+		{
+			name: "missing auth_time",
+			mutate: func(claims jwt.MapClaims) {
+				delete(claims, "auth_time")
+			},
+			wantErr: "Login Ulang",
+		},
+		{
+			name: "different provider account",
+			mutate: func(claims jwt.MapClaims) {
+				claims["sub"] = "provider-user-2"
+			},
+			wantErr: "Tidak Terhubung",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorization, err := service.GetReauthURL(context.Background(), user.ID, sessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(authorization.URL, "prompt=login") {
+				t.Fatalf("reauth must force a new login at the provider: %s", authorization.URL)
+			}
+			state, nonce := provider.authorize(t, authorization.URL)
+
+			claims := provider.claims(nonce)
+			tt.mutate(claims)
+			provider.idToken = provider.sign(t, claims)
+
+			result, err := service.HandleCallback(context.Background(), state, authorization.StateCookie, "code")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Reauth == nil {
+				t.Fatal("expected a reauth token")
+			}
+
+			ctx := context.Background()
+			if err := service.CheckReauthToken(ctx, user.ID, sessionID, result.Reauth.ReauthToken); err != nil {
+				t.Fatalf("reauth token rejected: %v", err)
+			}
+			if err := service.CheckReauthToken(ctx, user.ID, uuid.New(), result.Reauth.ReauthToken); err == nil {
+				t.Fatal("reauth token must be bound to the session that started the flow")
+			}
+			if err := service.CheckReauthToken(ctx, uuid.New(), sessionID, result.Reauth.ReauthToken); err == nil {
+				t.Fatal("reauth token must be bound to the user that started the flow")
+			}
+		})
+	}
+}
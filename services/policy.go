@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Actor adalah user yang sedang melakukan request, diambil dari claim JWT
+// yang sudah divalidasi oleh middleware Authenticate.
+type Actor struct {
+	UserID uuid.UUID
+	Role   string
+}
+
+func (a Actor) IsAdmin() bool {
+	return a.Role == entities.RoleAdmin
+}
+
+var (
+	ErrForbidden        = errors.New("Akses Ditolak")
+	ErrResourceNotFound = errors.New("Data Tidak Ditemukan")
+)
+
+// PolicyService menjadi satu-satunya tempat aturan kepemilikan resource,
+// sehingga controller cukup memanggil policy sebelum service dijalankan.
+type PolicyService interface {
+	AuthorizeEvent(ctx context.Context, actor Actor, eventID uuid.UUID) error
+}
+
+type policyService struct {
+	eventRepository repository.EventRepository
+}
+
+func NewPolicyService(er repository.EventRepository) PolicyService {
+	return &policyService{
+		eventRepository: er,
+	}
+}
+
+// AuthorizeEvent mengizinkan pemilik event dan admin.
+func (ps *policyService) AuthorizeEvent(ctx context.Context, actor Actor, eventID uuid.UUID) error {
+	ownerID, err := ps.eventRepository.GetEventOwnerID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrResourceNotFound
+		}
+		return err
+	}
+
+	if canManage(actor, ownerID) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// CanViewEventDetails menentukan apakah pemanggil boleh melihat data pribadi
+// pembuat event. actor bernilai nil untuk pengunjung yang tidak login.
+func CanViewEventDetails(actor *Actor, event entities.Event) bool {
+	return actor != nil && canManage(*actor, event.UserID)
+}
+
+// CanViewHiddenEvents menentukan apakah pemanggil boleh melihat event milik
+// ownerID yang belum atau tidak lagi tampil untuk umum, misalnya draft.
+func CanViewHiddenEvents(actor *Actor, ownerID uuid.UUID) bool {
+	return actor != nil && canManage(*actor, ownerID)
+}
+
+func canManage(actor Actor, ownerID uuid.UUID) bool {
+	return actor.IsAdmin() || ownerID == actor.UserID
+}
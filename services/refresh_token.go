@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+)
+
+type RefreshTokenService interface {
+	IssueTokenPair(ctx context.Context, user entities.User, client dto.SessionClient) (entities.Authorization, error)
+	RotateRefreshToken(ctx context.Context, refreshToken string, client dto.SessionClient) (entities.Authorization, error)
+	RevokeRefreshToken(ctx context.Context, refreshToken string) error
+	RevokeAllRefreshToken(ctx context.Context, userID uuid.UUID) error
+}
+
+type refreshTokenService struct {
+	refreshTokenRepository repository.RefreshTokenRepository
+	sessionRepository      repository.SessionRepository
+	userRepository         repository.UserRepository
+	jwtService             JWTService
+	refreshTTL             time.Duration
+}
+
+func NewRefreshTokenService(rr repository.RefreshTokenRepository, sr repository.SessionRepository, ur repository.UserRepository, jwt JWTService) RefreshTokenService {
+	return &refreshTokenService{
+		refreshTokenRepository: rr,
+		sessionRepository:      sr,
+		userRepository:         ur,
+		jwtService:             jwt,
+		refreshTTL:             getRefreshTokenTTL(),
+	}
+}
+
+func getRefreshTokenTTL() time.Duration {
+	return time.Duration(helpers.GetEnvInt("JWT_REFRESH_TTL_DAYS", 30)) * 24 * time.Hour
+}
+
+func (rs *refreshTokenService) IssueTokenPair(ctx context.Context, user entities.User, client dto.SessionClient) (entities.Authorization, error) {
+	return rs.issue(ctx, user, uuid.New(), uuid.New(), client)
+}
+
+// issue membuat access token baru beserta refresh token dengan id tertentu
+// di dalam family yang sama, sehingga satu login bisa dilacak sampai logout.
+// Family tersebut sekaligus menjadi id session.
+func (rs *refreshTokenService) issue(ctx context.Context, user entities.User, refreshTokenID uuid.UUID, familyID uuid.UUID, client dto.SessionClient) (entities.Authorization, error) {
+	raw, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return entities.Authorization{}, err
+	}
+
+	now := time.Now()
+	refreshToken := entities.RefreshToken{
+		ID:        refreshTokenID,
+		TokenHash: helpers.HashToken(raw),
+		FamilyID:  familyID,
+		ExpiresAt: now.Add(rs.refreshTTL),
+		UserID:    user.ID,
+	}
+	if _, err := rs.refreshTokenRepository.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return entities.Authorization{}, err
+	}
+
+	// Family lama yang belum punya session akan dibuatkan saat rotasi
+	if err := rs.sessionRepository.CreateSession(ctx, entities.UserSession{
+		ID:         familyID,
+		UserAgent:  truncate(client.UserAgent, 255),
+		IPAddress:  client.IPAddress,
+		LastSeenAt: now,
+		ExpiresAt:  refreshToken.ExpiresAt,
+		UserID:     user.ID,
+	}); err != nil {
+		return entities.Authorization{}, err
+	}
+
+	return entities.Authorization{
+		Token:            rs.jwtService.GenerateToken(user.ID, user.Role, familyID),
+		Role:             user.Role,
+		ExpiresAt:        now.Add(rs.jwtService.GetAccessTokenTTL()),
+		RefreshToken:     raw,
+		RefreshExpiresAt: refreshToken.ExpiresAt,
+	}, nil
+}
+
+func (rs *refreshTokenService) RotateRefreshToken(ctx context.Context, refreshToken string, client dto.SessionClient) (entities.Authorization, error) {
+	current, err := rs.refreshTokenRepository.GetRefreshTokenByHash(ctx, helpers.HashToken(refreshToken))
+	if err != nil {
+		return entities.Authorization{}, errors.New("Refresh Token Tidak Valid")
+	}
+
+	// Token yang sudah pernah dirotasi dipakai lagi, kemungkinan besar dicuri.
+	// Seluruh family dicabut agar pencuri maupun pemilik asli harus login ulang.
+	if current.RevokedAt != nil {
+		if err := rs.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+			return entities.Authorization{}, err
+		}
+		return entities.Authorization{}, errors.New("Refresh Token Sudah Digunakan")
+	}
+
+	if current.ExpiresAt.Before(time.Now()) {
+		return entities.Authorization{}, errors.New("Refresh Token Sudah Kadaluarsa")
+	}
+
+	user, err := rs.userRepository.GetUserByID(ctx, current.UserID)
+	if err != nil {
+		return entities.Authorization{}, err
+	}
+
+	nextID := uuid.New()
+	rotated, err := rs.refreshTokenRepository.RotateRefreshToken(ctx, current.ID, nextID)
+	if err != nil {
+		return entities.Authorization{}, err
+	}
+	if !rotated {
+		if err := rs.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+			return entities.Authorization{}, err
+		}
+		return entities.Authorization{}, errors.New("Refresh Token Sudah Digunakan")
+	}
+
+	return rs.issue(ctx, user, nextID, current.FamilyID, client)
+}
+
+func (rs *refreshTokenService) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	current, err := rs.refreshTokenRepository.GetRefreshTokenByHash(ctx, helpers.HashToken(refreshToken))
+	if err != nil {
+		return errors.New("Refresh Token Tidak Valid")
+	}
+	return rs.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, current.FamilyID)
+}
+
+func (rs *refreshTokenService) RevokeAllRefreshToken(ctx context.Context, userID uuid.UUID) error {
+	return rs.refreshTokenRepository.RevokeRefreshTokenByUserID(ctx, userID)
+}
+
+// truncate memotong berdasarkan rune agar karakter multibyte tidak terpotong.
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
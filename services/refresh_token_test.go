@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeRefreshTokenRepository menyimpan refresh token di memori dengan aturan
+// rotasi dan pencabutan yang sama seperti repository aslinya.
+type fakeRefreshTokenRepository struct {
+	repository.RefreshTokenRepository
+	tokens map[uuid.UUID]entities.RefreshToken
+}
+
+func (fr *fakeRefreshTokenRepository) CreateRefreshToken(ctx context.Context, refreshToken entities.RefreshToken) (entities.RefreshToken, error) {
+	fr.tokens[refreshToken.ID] = refreshToken
+	return refreshToken, nil
+}
+
+func (fr *fakeRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (entities.RefreshToken, error) {
+	for _, refreshToken := range fr.tokens {
+		if refreshToken.TokenHash == tokenHash {
+			return refreshToken, nil
+		}
+	}
+	return entities.RefreshToken{}, gorm.ErrRecordNotFound
+}
+
+func (fr *fakeRefreshTokenRepository) RotateRefreshToken(ctx context.Context, refreshTokenID uuid.UUID, replacedByID uuid.UUID) (bool, error) {
+	refreshToken := fr.tokens[refreshTokenID]
+	if refreshToken.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	refreshToken.RevokedAt = &now
+	refreshToken.ReplacedByID = &replacedByID
+	fr.tokens[refreshTokenID] = refreshToken
+	return true, nil
+}
+
+func (fr *fakeRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	now := time.Now()
+	for id, refreshToken := range fr.tokens {
+		if refreshToken.FamilyID == familyID && refreshToken.RevokedAt == nil {
+			refreshToken.RevokedAt = &now
+			fr.tokens[id] = refreshToken
+		}
+	}
+	return nil
+}
+
+// fakeSessionRepository hanya mencatat session yang dibuat.
+type fakeSessionRepository struct {
+	repository.SessionRepository
+	sessions map[uuid.UUID]entities.UserSession
+}
+
+func (fr *fakeSessionRepository) CreateSession(ctx context.Context, session entities.UserSession) error {
+	if _, ok := fr.sessions[session.ID]; !ok {
+		fr.sessions[session.ID] = session
+	}
+	return nil
+}
+
+func newTestRefreshTokenService(users *fakeUserRepository) (*refreshTokenService, *fakeRefreshTokenRepository) {
+	repo := &fakeRefreshTokenRepository{tokens: map[uuid.UUID]entities.RefreshToken{}}
+	return &refreshTokenService{
+		refreshTokenRepository: repo,
+		sessionRepository:      &fakeSessionRepository{sessions: map[uuid.UUID]entities.UserSession{}},
+		userRepository:         users,
+		jwtService:             NewJWTService(newFakeRevokedTokenRepository()),
+		refreshTTL:             time.Hour,
+	}, repo
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	user := entities.User{ID: uuid.New(), Role: entities.RoleUser}
+	users := newFakeUserRepository(user)
+	service, repo := newTestRefreshTokenService(users)
+	ctx := context.Background()
+
+	first, err := service.IssueTokenPair(ctx, user, dto.SessionClient{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Role baru dari database langsung dipakai saat rotasi
+	user.Role = entities.RoleCampaigner
+	users.users[user.ID] = user
+
+	second, err := service.RotateRefreshToken(ctx, first.RefreshToken, dto.SessionClient{})
+	if err != nil {
+		t.Fatalf("RotateRefreshToken: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("refresh token tidak diganti saat rotasi")
+	}
+	if second.Role != entities.RoleCampaigner {
+		t.Errorf("role = %s, want %s", second.Role, entities.RoleCampaigner)
+	}
+
+	if len(repo.tokens) != 2 {
+		t.Fatalf("jumlah refresh token = %d, want 2", len(repo.tokens))
+	}
+	var old, next entities.RefreshToken
+	for _, refreshToken := range repo.tokens {
+		if refreshToken.RevokedAt != nil {
+			old = refreshToken
+		} else {
+			next = refreshToken
+		}
+	}
+	if old.ReplacedByID == nil || *old.ReplacedByID != next.ID {
+		t.Error("token lama tidak menunjuk ke token penggantinya")
+	}
+	if old.FamilyID != next.FamilyID {
+		t.Error("token hasil rotasi berada di family yang berbeda")
+	}
+}
+
+func TestRotateRefreshTokenReuseRevokesFamily(t *testing.T) {
+	user := entities.User{ID: uuid.New(), Role: entities.RoleUser}
+	service, repo := newTestRefreshTokenService(newFakeUserRepository(user))
+	ctx := context.Background()
+
+	first, err := service.IssueTokenPair(ctx, user, dto.SessionClient{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := service.IssueTokenPair(ctx, user, dto.SessionClient{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := service.RotateRefreshToken(ctx, first.RefreshToken, dto.SessionClient{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Token lama dipakai lagi, misalnya oleh pencuri
+	if _, err := service.RotateRefreshToken(ctx, first.RefreshToken, dto.SessionClient{}); err == nil {
+		t.Fatal("refresh token yang sudah dirotasi bisa dipakai lagi")
+	}
+	if _, err := service.RotateRefreshToken(ctx, second.RefreshToken, dto.SessionClient{}); err == nil {
+		t.Error("token terbaru di family yang sama tidak ikut dicabut")
+	}
+	if _, err := service.RotateRefreshToken(ctx, other.RefreshToken, dto.SessionClient{}); err != nil {
+		t.Errorf("family lain ikut dicabut: %v", err)
+	}
+
+	reused, _ := repo.GetRefreshTokenByHash(ctx, helpers.HashToken(first.RefreshToken))
+	for _, refreshToken := range repo.tokens {
+		if refreshToken.FamilyID == reused.FamilyID && refreshToken.RevokedAt == nil {
+			t.Errorf("refresh token %s di family yang dicabut masih aktif", refreshToken.ID)
+		}
+	}
+}
+
+func TestRotateRefreshTokenRejectsInvalidToken(t *testing.T) {
+	user := entities.User{ID: uuid.New(), Role: entities.RoleUser}
+	service, repo := newTestRefreshTokenService(newFakeUserRepository(user))
+	ctx := context.Background()
+
+	if _, err := service.RotateRefreshToken(ctx, "tidak-ada", dto.SessionClient{}); err == nil {
+		t.Error("refresh token yang tidak dikenal diterima")
+	}
+
+	issued, err := service.IssueTokenPair(ctx, user, dto.SessionClient{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, refreshToken := range repo.tokens {
+		refreshToken.ExpiresAt = time.Now().Add(-time.Minute)
+		repo.tokens[id] = refreshToken
+	}
+	if _, err := service.RotateRefreshToken(ctx, issued.RefreshToken, dto.SessionClient{}); err == nil {
+		t.Error("refresh token yang kadaluarsa diterima")
+	}
+}
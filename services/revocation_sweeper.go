@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/repository"
+)
+
+// NewRevokedTokenSweepJob menghapus data pencabutan token yang sudah tidak
+// diperlukan.
+func NewRevokedTokenSweepJob(rr repository.RevokedTokenRepository, jwtService JWTService, interval time.Duration) Job {
+	return Job{
+		Name:     "revoked_token_sweep",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			deleted, err := rr.DeleteExpiredRevokedToken(ctx, time.Now(), jwtService.GetAccessTokenTTL())
+			if err != nil {
+				return err
+			}
+			if deleted > 0 {
+				log.Printf("swept %d revoked token", deleted)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/repository"
+)
+
+// Job adalah pekerjaan latar yang dijalankan berkala oleh Scheduler. Name
+// dipakai sebagai kunci lock sehingga harus unik.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler interface {
+	Register(job Job)
+	Start(ctx context.Context)
+}
+
+type scheduler struct {
+	jobLockRepository repository.JobLockRepository
+	jobs              []Job
+}
+
+func NewScheduler(jr repository.JobLockRepository) Scheduler {
+	return &scheduler{
+		jobLockRepository: jr,
+	}
+}
+
+func (s *scheduler) Register(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start menjalankan setiap job sekali saat start lalu sesuai intervalnya
+// sampai ctx dibatalkan.
+func (s *scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *scheduler) runOnce(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	// Instance lain yang sedang memegang lock akan menjalankan job ini
+	if _, err := s.jobLockRepository.RunWithLock(ctx, job.Name, job.Run); err != nil {
+		log.Printf("error running job %s: %v", job.Name, err)
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+)
+
+type SessionService interface {
+	ValidateSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, ip string) error
+	GetSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]dto.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) error
+}
+
+var (
+	ErrSessionNotFound = errors.New("Sesi Tidak Ditemukan")
+	ErrSessionExpired  = errors.New("Sesi Sudah Berakhir")
+)
+
+const sessionTouchInterval = time.Minute
+
+type sessionService struct {
+	sessionRepository repository.SessionRepository
+}
+
+func NewSessionService(sr repository.SessionRepository) SessionService {
+	return &sessionService{
+		sessionRepository: sr,
+	}
+}
+
+// ValidateSession dipanggil pada setiap request yang terautentikasi sehingga
+// access token dari session yang sudah dicabut langsung ditolak.
+func (ss *sessionService) ValidateSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, ip string) error {
+	session, err := ss.sessionRepository.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return ErrSessionExpired
+	}
+
+	now := time.Now()
+	if session.UserID != userID || session.RevokedAt != nil || !session.ExpiresAt.After(now) {
+		return ErrSessionExpired
+	}
+
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		if err := ss.sessionRepository.TouchSession(ctx, sessionID, ip, now, sessionTouchInterval); err != nil {
+			log.Printf("error touching session %s: %v", sessionID, err)
+		}
+	}
+	return nil
+}
+
+func (ss *sessionService) GetSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]dto.SessionResponse, error) {
+	sessions, err := ss.sessionRepository.GetActiveSessionsByUserID(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, dto.SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return res, nil
+}
+
+func (ss *sessionService) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	revoked, err := ss.sessionRepository.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (ss *sessionService) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) error {
+	return ss.sessionRepository.RevokeOtherSessions(ctx, userID, currentSessionID)
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Storage menyimpan file yang bisa diakses publik, misalnya foto event.
+// Key selalu memakai pemisah "/" apa pun implementasinya.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+var ErrStorageObjectNotFound = errors.New("File Tidak Ditemukan")
+
+// NewStorage memilih implementasi storage berdasarkan STORAGE_DRIVER. Driver
+// "local" menyimpan file di folder lokal dan cocok untuk development, driver
+// "s3" bisa dipakai untuk AWS S3 maupun layanan yang kompatibel seperti MinIO.
+func NewStorage() Storage {
+	if !UsesLocalStorage() {
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("STORAGE_PUBLIC_URL"),
+		})
+	}
+
+	dir := os.Getenv("STORAGE_LOCAL_DIR")
+	if dir == "" {
+		dir = "uploads/public"
+	}
+	publicURL := os.Getenv("STORAGE_PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = strings.TrimRight(os.Getenv("APP_URL"), "/") + LocalStorageRoute
+	}
+	return NewLocalStorage(dir, publicURL)
+}
+
+// UsesLocalStorage bernilai true jika STORAGE_DRIVER memakai storage lokal.
+// Hanya storage lokal yang file-nya disajikan oleh aplikasi ini.
+func UsesLocalStorage() bool {
+	return os.Getenv("STORAGE_DRIVER") != "s3"
+}
+
+// LocalStorageRoute adalah path tempat file dari storage lokal disajikan.
+const LocalStorageRoute = "/uploads"
+
+type localStorage struct {
+	dir       string
+	publicURL string
+}
+
+func NewLocalStorage(dir string, publicURL string) Storage {
+	return &localStorage{
+		dir:       dir,
+		publicURL: strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// path membersihkan key sehingga file tidak bisa dibaca atau ditulis di luar
+// folder storage, walaupun key berasal dari URL request.
+func (ls *localStorage) path(key string) string {
+	return filepath.Join(ls.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+func (ls *localStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	target := ls.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	// File ditulis ke file sementara dulu agar pembaca tidak pernah melihat file setengah jadi
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func (ls *localStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(ls.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrStorageObjectNotFound
+		}
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, ErrStorageObjectNotFound
+	}
+	return file, nil
+}
+
+func (ls *localStorage) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(ls.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (ls *localStorage) URL(key string) string {
+	return ls.publicURL + "/" + strings.TrimLeft(key, "/")
+}
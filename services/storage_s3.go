@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config berisi konfigurasi storage yang kompatibel dengan S3. Endpoint
+// diisi alamat server, misalnya https://s3.ap-southeast-1.amazonaws.com atau
+// http://localhost:9000 untuk MinIO. Bucket selalu diakses dengan path-style
+// sehingga tidak memerlukan DNS per bucket.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PublicURL dipakai untuk membentuk URL file, misalnya alamat CDN. Jika
+	// kosong, URL dibentuk dari Endpoint dan Bucket.
+	PublicURL string
+}
+
+type s3Storage struct {
+	config S3Config
+	client *http.Client
+}
+
+func NewS3Storage(config S3Config) Storage {
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.PublicURL == "" {
+		config.PublicURL = config.Endpoint + "/" + config.Bucket
+	}
+	config.PublicURL = strings.TrimRight(config.PublicURL, "/")
+
+	return &s3Storage{
+		config: config,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// s3EscapePath meng-encode key sesuai aturan URI encoding SigV4: semua byte
+// selain huruf, angka, "-", ".", "_", "~" dan pemisah "/" di-encode.
+func s3EscapePath(key string) string {
+	var builder strings.Builder
+	for _, b := range []byte(strings.TrimLeft(key, "/")) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || strings.IndexByte("-._~/", b) >= 0 {
+			builder.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&builder, "%%%02X", b)
+	}
+	return builder.String()
+}
+
+func (ss *s3Storage) objectURL(key string) string {
+	return ss.config.Endpoint + "/" + ss.config.Bucket + "/" + s3EscapePath(key)
+}
+
+func (ss *s3Storage) do(ctx context.Context, method string, key string, body []byte, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, ss.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	payloadHash := sha256.Sum256(body)
+	signS3Request(req, hex.EncodeToString(payloadHash[:]), ss.config.AccessKey, ss.config.SecretKey, ss.config.Region, time.Now())
+	return ss.client.Do(req)
+}
+
+func s3ResponseError(action string, key string, resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 %s %s: %s: %s", action, key, resp.Status, strings.TrimSpace(string(message)))
+}
+
+func (ss *s3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	resp, err := ss.do(ctx, http.MethodPut, key, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3ResponseError("put", key, resp)
+	}
+	return nil
+}
+
+func (ss *s3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := ss.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrStorageObjectNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s3ResponseError("get", key, resp)
+	}
+}
+
+func (ss *s3Storage) Delete(ctx context.Context, key string) error {
+	resp, err := ss.do(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 mengembalikan 204 walaupun object tidak ada
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3ResponseError("delete", key, resp)
+	}
+	return nil
+}
+
+func (ss *s3Storage) URL(key string) string {
+	return ss.config.PublicURL + "/" + s3EscapePath(key)
+}
+
+// signS3Request menandatangani request dengan AWS Signature Version 4. Semua
+// header yang sudah di-set pada request ikut ditandatangani bersama host.
+func signS3Request(req *http.Request, payloadHash string, accessKey string, secretKey string, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
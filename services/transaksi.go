@@ -11,11 +11,11 @@ import (
 )
 
 type TransaksiService interface {
-	CreateTransaksi(ctx context.Context, transaksiDTO dto.TransaksiCreateDTO) (entities.Transaksi, error)
-	GetAllTransaksi(ctx context.Context) ([]entities.Transaksi, error)
-	GetTransaksiByID(ctx context.Context, transaksiID uuid.UUID) (entities.Transaksi, error)
-	GetAllTransaksiByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Transaksi, error)
-	GetAllEventLastTransaksi(ctx context.Context, eventID uuid.UUID) ([]entities.Transaksi, error)
+	CreateTransaksi(ctx context.Context, transaksiDTO dto.TransaksiCreateDTO) (dto.TransaksiResponse, error)
+	GetAllTransaksi(ctx context.Context) ([]dto.TransaksiResponse, error)
+	GetTransaksiByID(ctx context.Context, transaksiID uuid.UUID) (dto.TransaksiResponse, error)
+	GetAllTransaksiByUserID(ctx context.Context, userID uuid.UUID) ([]dto.TransaksiResponse, error)
+	GetAllEventLastTransaksi(ctx context.Context, eventID uuid.UUID) ([]dto.TransaksiResponse, error)
 }
 
 type transaksiService struct {
@@ -28,27 +28,48 @@ func NewTransaksiService(tr repository.TransaksiRepository) TransaksiService {
 	}
 }
 
-func (ts *transaksiService) CreateTransaksi(ctx context.Context, transaksiDTO dto.TransaksiCreateDTO) (entities.Transaksi, error) {
+func (ts *transaksiService) CreateTransaksi(ctx context.Context, transaksiDTO dto.TransaksiCreateDTO) (dto.TransaksiResponse, error) {
 	transaksi := entities.Transaksi{}
 	err := smapping.FillStruct(&transaksi, smapping.MapFields(transaksiDTO))
 	if err != nil {
-		return entities.Transaksi{}, err
+		return dto.TransaksiResponse{}, err
 	}
-	return ts.transaksiRepository.CreateTransaksi(ctx, transaksi)
+
+	result, err := ts.transaksiRepository.CreateTransaksi(ctx, transaksi)
+	if err != nil {
+		return dto.TransaksiResponse{}, err
+	}
+	return ToTransaksiResponse(result), nil
 }
 
-func (ts *transaksiService) GetAllTransaksi(ctx context.Context) ([]entities.Transaksi, error) {
-	return ts.transaksiRepository.GetAllTransaksi(ctx)
+func (ts *transaksiService) GetAllTransaksi(ctx context.Context) ([]dto.TransaksiResponse, error) {
+	result, err := ts.transaksiRepository.GetAllTransaksi(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ToTransaksiResponses(result), nil
 }
 
-func (ts *transaksiService) GetTransaksiByID(ctx context.Context, transaksiID uuid.UUID) (entities.Transaksi, error) {
-	return ts.transaksiRepository.GetTransaksiByID(ctx, transaksiID)
+func (ts *transaksiService) GetTransaksiByID(ctx context.Context, transaksiID uuid.UUID) (dto.TransaksiResponse, error) {
+	result, err := ts.transaksiRepository.GetTransaksiByID(ctx, transaksiID)
+	if err != nil {
+		return dto.TransaksiResponse{}, err
+	}
+	return ToTransaksiResponse(result), nil
 }
 
-func (ts *transaksiService) GetAllTransaksiByUserID(ctx context.Context, userID uuid.UUID) ([]entities.Transaksi, error) {
-	return ts.transaksiRepository.GetAllTransaksiByUserID(ctx, userID)
+func (ts *transaksiService) GetAllTransaksiByUserID(ctx context.Context, userID uuid.UUID) ([]dto.TransaksiResponse, error) {
+	result, err := ts.transaksiRepository.GetAllTransaksiByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return ToTransaksiResponses(result), nil
 }
 
-func (ts *transaksiService) GetAllEventLastTransaksi(ctx context.Context, eventID uuid.UUID) ([]entities.Transaksi, error) {
-	return ts.transaksiRepository.GetAllEventLastTransaksi(ctx, eventID)
+func (ts *transaksiService) GetAllEventLastTransaksi(ctx context.Context, eventID uuid.UUID) ([]dto.TransaksiResponse, error) {
+	result, err := ts.transaksiRepository.GetAllEventLastTransaksi(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return ToTransaksiResponses(result), nil
 }
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TwoFactorService interface {
+	SetupTwoFactor(ctx context.Context, userID uuid.UUID) (dto.TwoFactorSetupResponse, error)
+	EnableTwoFactor(ctx context.Context, userID uuid.UUID, code string) (dto.TwoFactorRecoveryCodesResponse, error)
+	DisableTwoFactor(ctx context.Context, userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (dto.TwoFactorRecoveryCodesResponse, error)
+	IsTwoFactorEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	CreateLoginChallenge(user entities.User) dto.TwoFactorChallengeResponse
+	VerifyTwoFactorCode(ctx context.Context, userID uuid.UUID, code string) error
+	CreateStepUpToken(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, code string) (dto.StepUpTokenResponse, error)
+	CheckStepUp(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, stepUpToken string) error
+}
+
+const (
+	TwoFactorLoginPurpose = "two_factor_login"
+	StepUpPurpose         = "step_up"
+	StepUpHeader          = "X-Step-Up-Token"
+	twoFactorLoginTTL     = 5 * time.Minute
+	stepUpTTL             = 5 * time.Minute
+	recoveryCodeCount     = 10
+)
+
+var (
+	ErrTwoFactorInvalidCode = errors.New("Kode Autentikasi Tidak Valid")
+	ErrStepUpRequired       = errors.New("Verifikasi Dua Langkah Diperlukan")
+)
+
+type twoFactorService struct {
+	twoFactorRepository repository.TwoFactorRepository
+	userRepository      repository.UserRepository
+	jwtService          JWTService
+	issuer              string
+}
+
+func NewTwoFactorService(tr repository.TwoFactorRepository, ur repository.UserRepository, jwt JWTService) TwoFactorService {
+	return &twoFactorService{
+		twoFactorRepository: tr,
+		userRepository:      ur,
+		jwtService:          jwt,
+		issuer:              getTOTPIssuer(),
+	}
+}
+
+func getTOTPIssuer() string {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = "Fundle"
+	}
+	return issuer
+}
+
+func (ts *twoFactorService) SetupTwoFactor(ctx context.Context, userID uuid.UUID) (dto.TwoFactorSetupResponse, error) {
+	if enabled, err := ts.IsTwoFactorEnabled(ctx, userID); err != nil {
+		return dto.TwoFactorSetupResponse{}, err
+	} else if enabled {
+		return dto.TwoFactorSetupResponse{}, errors.New("Autentikasi Dua Langkah Sudah Aktif")
+	}
+
+	user, err := ts.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return dto.TwoFactorSetupResponse{}, err
+	}
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		return dto.TwoFactorSetupResponse{}, err
+	}
+	encrypted, err := helpers.EncryptString(secret)
+	if err != nil {
+		return dto.TwoFactorSetupResponse{}, err
+	}
+
+	// Secret baru menimpa setup sebelumnya yang belum dikonfirmasi
+	if err := ts.twoFactorRepository.SaveTwoFactor(ctx, entities.UserTwoFactor{
+		UserID:          userID,
+		SecretEncrypted: encrypted,
+	}); err != nil {
+		return dto.TwoFactorSetupResponse{}, err
+	}
+
+	return dto.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: helpers.TOTPProvisioningURI(ts.issuer, user.Email, secret),
+	}, nil
+}
+
+func (ts *twoFactorService) EnableTwoFactor(ctx context.Context, userID uuid.UUID, code string) (dto.TwoFactorRecoveryCodesResponse, error) {
+	twoFactor, err := ts.twoFactorRepository.GetTwoFactorByUserID(ctx, userID)
+	if err != nil {
+		return dto.TwoFactorRecoveryCodesResponse{}, errors.New("Autentikasi Dua Langkah Belum Disiapkan")
+	}
+	if twoFactor.EnabledAt != nil {
+		return dto.TwoFactorRecoveryCodesResponse{}, errors.New("Autentikasi Dua Langkah Sudah Aktif")
+	}
+
+	secret, err := helpers.DecryptString(twoFactor.SecretEncrypted)
+	if err != nil {
+		return dto.TwoFactorRecoveryCodesResponse{}, err
+	}
+	step, ok := helpers.ValidateTOTPCode(secret, code, time.Now())
+	if !ok {
+		return dto.TwoFactorRecoveryCodesResponse{}, ErrTwoFactorInvalidCode
+	}
+
+	if err := ts.twoFactorRepository.EnableTwoFactor(ctx, userID, step); err != nil {
+		return dto.TwoFactorRecoveryCodesResponse{}, err
+	}
+	return ts.generateRecoveryCodes(ctx, userID)
+}
+
+func (ts *twoFactorService) DisableTwoFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	if err := ts.VerifyTwoFactorCode(ctx, userID, code); err != nil {
+		return err
+	}
+	return ts.twoFactorRepository.DeleteTwoFactor(ctx, userID)
+}
+
+func (ts *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (dto.TwoFactorRecoveryCodesResponse, error) {
+	if err := ts.VerifyTwoFactorCode(ctx, userID, code); err != nil {
+		return dto.TwoFactorRecoveryCodesResponse{}, err
+	}
+	return ts.generateRecoveryCodes(ctx, userID)
+}
+
+func (ts *twoFactorService) IsTwoFactorEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	twoFactor, err := ts.twoFactorRepository.GetTwoFactorByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.EnabledAt != nil, nil
+}
+
+func (ts *twoFactorService) CreateLoginChallenge(user entities.User) dto.TwoFactorChallengeResponse {
+	return dto.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    ts.jwtService.GenerateActionToken(user.ID, user.Email, TwoFactorLoginPurpose, twoFactorLoginTTL),
+	}
+}
+
+func (ts *twoFactorService) CreateStepUpToken(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, code string) (dto.StepUpTokenResponse, error) {
+	if err := ts.VerifyTwoFactorCode(ctx, userID, code); err != nil {
+		return dto.StepUpTokenResponse{}, err
+	}
+	return dto.StepUpTokenResponse{
+		// Token hanya berlaku untuk session yang melakukan verifikasi
+		StepUpToken: ts.jwtService.GenerateActionToken(userID, sessionID.String(), StepUpPurpose, stepUpTTL),
+		ExpiresAt:   time.Now().Add(stepUpTTL),
+	}, nil
+}
+
+// CheckStepUp hanya mewajibkan step-up token bagi user yang mengaktifkan
+// autentikasi dua langkah. Token dari session lain ditolak.
+func (ts *twoFactorService) CheckStepUp(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, stepUpToken string) error {
+	enabled, err := ts.IsTwoFactorEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+	if stepUpToken == "" {
+		return ErrStepUpRequired
+	}
+
+	tokenUserID, tokenSessionID, err := ts.jwtService.ValidateActionToken(stepUpToken, StepUpPurpose)
+	if err != nil || tokenUserID != userID || tokenSessionID != sessionID.String() {
+		return ErrStepUpRequired
+	}
+	return nil
+}
+
+// VerifyTwoFactorCode menerima kode TOTP 6 digit atau salah satu recovery
+// code. Keduanya hanya bisa dipakai sekali.
+func (ts *twoFactorService) VerifyTwoFactorCode(ctx context.Context, userID uuid.UUID, code string) error {
+	twoFactor, err := ts.twoFactorRepository.GetTwoFactorByUserID(ctx, userID)
+	if err != nil || twoFactor.EnabledAt == nil {
+		return errors.New("Autentikasi Dua Langkah Belum Aktif")
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == helpers.TOTPDigits {
+		secret, err := helpers.DecryptString(twoFactor.SecretEncrypted)
+		if err != nil {
+			return err
+		}
+		step, ok := helpers.ValidateTOTPCode(secret, code, time.Now())
+		if !ok {
+			return ErrTwoFactorInvalidCode
+		}
+		used, err := ts.twoFactorRepository.UseTwoFactorStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrTwoFactorInvalidCode
+		}
+		return nil
+	}
+
+	used, err := ts.twoFactorRepository.UseRecoveryCode(ctx, userID, helpers.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrTwoFactorInvalidCode
+	}
+	return nil
+}
+
+func (ts *twoFactorService) generateRecoveryCodes(ctx context.Context, userID uuid.UUID) (dto.TwoFactorRecoveryCodesResponse, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return dto.TwoFactorRecoveryCodesResponse{}, err
+		}
+		code := hex.EncodeToString(raw)
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, helpers.HashToken(code))
+	}
+
+	if err := ts.twoFactorRepository.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return dto.TwoFactorRecoveryCodesResponse{}, err
+	}
+	return dto.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -3,6 +3,11 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
 
 	"github.com/Caknoooo/golang-clean_template/dto"
 	"github.com/Caknoooo/golang-clean_template/entities"
@@ -13,48 +18,116 @@ import (
 )
 
 type UserService interface {
-	RegisterUser(ctx context.Context, userDTO dto.UserCreateDTO) (entities.User, error)
-	GetAllUser(ctx context.Context) ([]entities.User, error)
+	RegisterUser(ctx context.Context, userDTO dto.UserCreateDTO) (dto.UserSelfResponse, error)
+	GetAllUser(ctx context.Context) ([]dto.UserAdminResponse, error)
 	GetUserByID(ctx context.Context, userID uuid.UUID) (entities.User, error)
+	GetMe(ctx context.Context, userID uuid.UUID) (dto.UserSelfResponse, error)
 	GetUserByEmail(ctx context.Context, email string) (entities.User, error)
 	CheckUser(ctx context.Context, email string) (bool, error)
 	UpdateUser(ctx context.Context, userDTO dto.UserUpdateDTO) error
-	DeleteUser(ctx context.Context, userID uuid.UUID) error
 	Verify(ctx context.Context, email string, password string) (bool, error)
+	PromoteUser(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) (dto.UserAdminResponse, error)
+	DemoteUser(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) (dto.UserAdminResponse, error)
+	SendVerificationEmail(ctx context.Context, user entities.User) error
+	ResendVerificationEmail(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, resetDTO dto.UserResetPasswordDTO) error
+	ChangePassword(ctx context.Context, userID uuid.UUID, passwordDTO dto.UserChangePasswordDTO, reauthenticated bool) error
+	RequestEmailChange(ctx context.Context, userID uuid.UUID, emailDTO dto.UserChangeEmailDTO, reauthenticated bool) error
+	ConfirmEmailChange(ctx context.Context, token string) error
 }
 
+const (
+	EmailVerificationPurpose = "email_verification"
+	emailVerificationTTL     = 24 * time.Hour
+	emailChangeTTL           = 24 * time.Hour
+	passwordResetTTL         = time.Hour
+)
+
 type userService struct {
-	userRepository repository.UserRepository
+	userRepository          repository.UserRepository
+	passwordResetRepository repository.PasswordResetRepository
+	emailChangeRepository   repository.EmailChangeRepository
+	mailer                  Mailer
+	jwtService              JWTService
 }
 
-func NewUserService(ur repository.UserRepository) UserService {
+func NewUserService(ur repository.UserRepository, prr repository.PasswordResetRepository, ecr repository.EmailChangeRepository, mailer Mailer, jwt JWTService) UserService {
 	return &userService{
-		userRepository: ur,
+		userRepository:          ur,
+		passwordResetRepository: prr,
+		emailChangeRepository:   ecr,
+		mailer:                  mailer,
+		jwtService:              jwt,
+	}
+}
+
+func getAppURL() string {
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:8888"
 	}
+	return appURL
 }
 
-func (us *userService) RegisterUser(ctx context.Context, userDTO dto.UserCreateDTO) (entities.User, error) {
+func getFrontendURL() string {
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = getAppURL()
+	}
+	return frontendURL
+}
+
+func (us *userService) RegisterUser(ctx context.Context, userDTO dto.UserCreateDTO) (dto.UserSelfResponse, error) {
 	if userDTO.Password != userDTO.ConfirmPassword {
-		return entities.User{}, errors.New("Invalid Password and Confirm Password")
+		return dto.UserSelfResponse{}, errors.New("Invalid Password and Confirm Password")
 	}
 
 	user := entities.User{}
 	err := smapping.FillStruct(&user, smapping.MapFields(userDTO))
-	user.Role = "user"
+	user.Role = entities.RoleUser
 	if err != nil {
-		return entities.User{}, err
+		return dto.UserSelfResponse{}, err
 	}
-	return us.userRepository.RegisterUser(ctx, user)
+
+	result, err := us.userRepository.RegisterUser(ctx, user)
+	if err != nil {
+		return dto.UserSelfResponse{}, err
+	}
+
+	// User tetap terdaftar walaupun email gagal terkirim, link bisa dikirim ulang
+	if err := us.SendVerificationEmail(ctx, result); err != nil {
+		log.Printf("error sending verification email to %s: %v", result.Email, err)
+	}
+	return ToUserSelfResponse(result), nil
 }
 
-func (us *userService) GetAllUser(ctx context.Context) ([]entities.User, error) {
-	return us.userRepository.GetAllUser(ctx)
+func (us *userService) GetAllUser(ctx context.Context) ([]dto.UserAdminResponse, error) {
+	users, err := us.userRepository.GetAllUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.UserAdminResponse, 0, len(users))
+	for _, user := range users {
+		res = append(res, ToUserAdminResponse(user))
+	}
+	return res, nil
 }
 
 func (us *userService) GetUserByID(ctx context.Context, userID uuid.UUID) (entities.User, error) {
 	return us.userRepository.GetUserByID(ctx, userID)
 }
 
+func (us *userService) GetMe(ctx context.Context, userID uuid.UUID) (dto.UserSelfResponse, error) {
+	user, err := us.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return dto.UserSelfResponse{}, err
+	}
+	return ToUserSelfResponse(user), nil
+}
+
 func (us *userService) GetUserByEmail(ctx context.Context, email string) (entities.User, error) {
 	return us.userRepository.GetUserByEmail(ctx, email)
 }
@@ -72,17 +145,18 @@ func (us *userService) CheckUser(ctx context.Context, email string) (bool, error
 }
 
 func (us *userService) UpdateUser(ctx context.Context, userDTO dto.UserUpdateDTO) error {
-	user := entities.User{}
-	if err := smapping.FillStruct(&user, smapping.MapFields(userDTO)); err != nil {
-		return nil
+	user := entities.User{
+		ID: userDTO.ID,
+	}
+	if userDTO.Nama != nil {
+		user.Nama = *userDTO.Nama
+	}
+	if userDTO.NoTelp != nil {
+		user.NoTelp = *userDTO.NoTelp
 	}
 	return us.userRepository.UpdateUser(ctx, user)
 }
 
-func (us *userService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
-	return us.userRepository.DeleteUser(ctx, userID)
-}
-
 func (us *userService) Verify(ctx context.Context, email string, password string) (bool, error) {
 	res, err := us.userRepository.GetUserByEmail(ctx, email)
 	if err != nil {
@@ -95,7 +169,266 @@ func (us *userService) Verify(ctx context.Context, email string, password string
 	}
 
 	if res.Email == email && checkPassword {
+		// Hash dengan parameter lama di-upgrade selagi password asli tersedia
+		if helpers.PasswordNeedsRehash(res.Password) {
+			if hashedPassword, err := helpers.HashPassword(password); err == nil {
+				if err := us.userRepository.UpdatePassword(ctx, res.ID, hashedPassword); err != nil {
+					log.Printf("error rehashing password for user %s: %v", res.ID, err)
+				}
+			}
+		}
 		return true, nil
 	}
 	return false, nil
 }
+
+func (us *userService) PromoteUser(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) (dto.UserAdminResponse, error) {
+	user, err := us.shiftRole(ctx, actorID, userID, 1)
+	if err != nil {
+		return dto.UserAdminResponse{}, err
+	}
+	return ToUserAdminResponse(user), nil
+}
+
+func (us *userService) DemoteUser(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) (dto.UserAdminResponse, error) {
+	user, err := us.shiftRole(ctx, actorID, userID, -1)
+	if err != nil {
+		return dto.UserAdminResponse{}, err
+	}
+	return ToUserAdminResponse(user), nil
+}
+
+// shiftRole memindahkan role user sebanyak step tingkat pada entities.RoleLevels.
+func (us *userService) shiftRole(ctx context.Context, actorID uuid.UUID, userID uuid.UUID, step int) (entities.User, error) {
+	if actorID == userID {
+		return entities.User{}, errors.New("Tidak Dapat Mengubah Role Diri Sendiri")
+	}
+
+	user, err := us.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	level := -1
+	for i, role := range entities.RoleLevels {
+		if role == user.Role {
+			level = i
+			break
+		}
+	}
+	if level == -1 {
+		return entities.User{}, errors.New("Role User Tidak Dikenali")
+	}
+
+	next := level + step
+	if next < 0 || next >= len(entities.RoleLevels) {
+		return entities.User{}, errors.New("Role User Sudah Berada Di Batas")
+	}
+
+	user.Role = entities.RoleLevels[next]
+	if err := us.userRepository.UpdateUserRole(ctx, user.ID, user.Role); err != nil {
+		return entities.User{}, err
+	}
+
+	// Access token menyimpan role sebagai claim, sehingga token lama dicabut
+	// agar role baru langsung berlaku. Refresh token tetap berlaku dan token
+	// berikutnya dibuat dengan role dari database.
+	if err := us.jwtService.InvalidateAllUserToken(user.ID); err != nil {
+		return entities.User{}, err
+	}
+	return user, nil
+}
+
+func (us *userService) SendVerificationEmail(ctx context.Context, user entities.User) error {
+	token := us.jwtService.GenerateActionToken(user.ID, user.Email, EmailVerificationPurpose, emailVerificationTTL)
+	link := fmt.Sprintf("%s/api/user/verify?token=%s", getAppURL(), url.QueryEscape(token))
+	body := fmt.Sprintf("Halo %s,\n\nSilakan verifikasi email anda dengan membuka link berikut:\n%s\n\nLink ini berlaku selama 24 jam.\n", user.Nama, link)
+	return us.mailer.Send(ctx, user.Email, "Verifikasi Email", body)
+}
+
+func (us *userService) ResendVerificationEmail(ctx context.Context, email string) error {
+	user, err := us.userRepository.GetUserByEmail(ctx, email)
+	if err != nil {
+		// Tidak membocorkan apakah email terdaftar atau tidak
+		return nil
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return us.SendVerificationEmail(ctx, user)
+}
+
+func (us *userService) VerifyEmail(ctx context.Context, token string) error {
+	userID, email, err := us.jwtService.ValidateActionToken(token, EmailVerificationPurpose)
+	if err != nil {
+		return errors.New("Token Verifikasi Tidak Valid")
+	}
+
+	user, err := us.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Email != email {
+		return errors.New("Token Verifikasi Tidak Valid")
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return us.userRepository.MarkEmailVerified(ctx, userID)
+}
+
+func (us *userService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := us.userRepository.GetUserByEmail(ctx, email)
+	if err != nil {
+		// Tidak membocorkan apakah email terdaftar atau tidak
+		return nil
+	}
+
+	// Hanya link terakhir yang berlaku
+	if err := us.passwordResetRepository.InvalidatePasswordResetTokenByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	raw, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	resetToken := entities.PasswordResetToken{
+		TokenHash: helpers.HashToken(raw),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+		UserID:    user.ID,
+	}
+	if _, err := us.passwordResetRepository.CreatePasswordResetToken(ctx, resetToken); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", getFrontendURL(), url.QueryEscape(raw))
+	body := fmt.Sprintf("Halo %s,\n\nKami menerima permintaan untuk mengatur ulang password akun anda. Buka link berikut untuk membuat password baru:\n%s\n\nLink ini berlaku selama 1 jam dan hanya dapat digunakan satu kali. Abaikan email ini jika anda tidak merasa memintanya.\n", user.Nama, link)
+	return us.mailer.Send(ctx, user.Email, "Atur Ulang Password", body)
+}
+
+func (us *userService) ResetPassword(ctx context.Context, resetDTO dto.UserResetPasswordDTO) error {
+	if resetDTO.Password != resetDTO.ConfirmPassword {
+		return errors.New("Invalid Password and Confirm Password")
+	}
+
+	resetToken, err := us.passwordResetRepository.GetPasswordResetTokenByHash(ctx, helpers.HashToken(resetDTO.Token))
+	if err != nil {
+		return errors.New("Token Reset Password Tidak Valid")
+	}
+
+	used, err := us.passwordResetRepository.UsePasswordResetToken(ctx, resetToken.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.New("Token Reset Password Sudah Digunakan Atau Kadaluarsa")
+	}
+
+	hashedPassword, err := helpers.HashPassword(resetDTO.Password)
+	if err != nil {
+		return err
+	}
+	// Seluruh sesi dicabut bersamaan dengan penggantian password
+	return us.userRepository.UpdatePasswordAndRevokeTokens(ctx, resetToken.UserID, hashedPassword, time.Now())
+}
+
+// ChangePassword dan RequestEmailChange tidak lagi memeriksa password jika
+// user sudah mengonfirmasi ulang identitasnya lewat provider OIDC.
+func (us *userService) ChangePassword(ctx context.Context, userID uuid.UUID, passwordDTO dto.UserChangePasswordDTO, reauthenticated bool) error {
+	if passwordDTO.Password != passwordDTO.ConfirmPassword {
+		return errors.New("Invalid Password and Confirm Password")
+	}
+
+	user, err := us.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !reauthenticated {
+		if ok, _ := helpers.CheckPassword(user.Password, []byte(passwordDTO.CurrentPassword)); !ok {
+			return errors.New("Password Saat Ini Salah")
+		}
+	}
+
+	hashedPassword, err := helpers.HashPassword(passwordDTO.Password)
+	if err != nil {
+		return err
+	}
+	// Seluruh sesi dicabut bersamaan dengan penggantian password
+	return us.userRepository.UpdatePasswordAndRevokeTokens(ctx, userID, hashedPassword, time.Now())
+}
+
+func (us *userService) RequestEmailChange(ctx context.Context, userID uuid.UUID, emailDTO dto.UserChangeEmailDTO, reauthenticated bool) error {
+	user, err := us.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !reauthenticated {
+		if ok, _ := helpers.CheckPassword(user.Password, []byte(emailDTO.Password)); !ok {
+			return errors.New("Password Salah")
+		}
+	}
+
+	if user.Email == emailDTO.Email {
+		return errors.New("Email Baru Sama Dengan Email Saat Ini")
+	}
+	if exists, _ := us.CheckUser(ctx, emailDTO.Email); exists {
+		return errors.New("Email Sudah Terdaftar")
+	}
+
+	// Hanya link terakhir yang berlaku
+	if err := us.emailChangeRepository.InvalidateEmailChangeTokenByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	raw, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	changeToken := entities.EmailChangeToken{
+		TokenHash: helpers.HashToken(raw),
+		NewEmail:  emailDTO.Email,
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+		UserID:    user.ID,
+	}
+	if _, err := us.emailChangeRepository.CreateEmailChangeToken(ctx, changeToken); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/user/email/confirm?token=%s", getAppURL(), url.QueryEscape(raw))
+	body := fmt.Sprintf("Halo %s,\n\nKonfirmasi perubahan email akun anda ke alamat ini dengan membuka link berikut:\n%s\n\nLink ini berlaku selama 24 jam.\n", user.Nama, link)
+	if err := us.mailer.Send(ctx, emailDTO.Email, "Konfirmasi Perubahan Email", body); err != nil {
+		return err
+	}
+
+	notice := fmt.Sprintf("Halo %s,\n\nAda permintaan untuk mengubah email akun anda menjadi %s. Segera ubah password anda jika anda tidak merasa melakukannya.\n", user.Nama, emailDTO.Email)
+	if err := us.mailer.Send(ctx, user.Email, "Permintaan Perubahan Email", notice); err != nil {
+		log.Printf("error sending email change notice to %s: %v", user.Email, err)
+	}
+	return nil
+}
+
+func (us *userService) ConfirmEmailChange(ctx context.Context, token string) error {
+	changeToken, err := us.emailChangeRepository.GetEmailChangeTokenByHash(ctx, helpers.HashToken(token))
+	if err != nil {
+		return errors.New("Token Konfirmasi Tidak Valid")
+	}
+
+	// Email bisa saja sudah dipakai user lain selama menunggu konfirmasi
+	if exists, _ := us.CheckUser(ctx, changeToken.NewEmail); exists {
+		return errors.New("Email Sudah Terdaftar")
+	}
+
+	used, err := us.emailChangeRepository.UseEmailChangeToken(ctx, changeToken.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.New("Token Konfirmasi Sudah Digunakan Atau Kadaluarsa")
+	}
+	return us.userRepository.UpdateEmail(ctx, changeToken.UserID, changeToken.NewEmail)
+}
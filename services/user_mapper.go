@@ -0,0 +1,70 @@
+package services
+
+import (
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/google/uuid"
+)
+
+// Mapper di bawah ini memastikan entity User tidak pernah dikirim langsung ke
+// client, sehingga hash password dan relasi internal tidak ikut terserialisasi.
+
+func ToUserPublicResponse(user entities.User) dto.UserPublicResponse {
+	return dto.UserPublicResponse{
+		ID:   user.ID,
+		Nama: user.Nama,
+	}
+}
+
+func ToUserSelfResponse(user entities.User) dto.UserSelfResponse {
+	return dto.UserSelfResponse{
+		ID:                  user.ID,
+		Nama:                user.Nama,
+		NoTelp:              user.NoTelp,
+		Email:               user.Email,
+		Role:                user.Role,
+		EmailVerifiedAt:     user.EmailVerifiedAt,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+	}
+}
+
+func ToUserAdminResponse(user entities.User) dto.UserAdminResponse {
+	return dto.UserAdminResponse{
+		ID:                  user.ID,
+		Nama:                user.Nama,
+		NoTelp:              user.NoTelp,
+		Email:               user.Email,
+		Role:                user.Role,
+		EmailVerifiedAt:     user.EmailVerifiedAt,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		AnonymizedAt:        user.AnonymizedAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+	}
+}
+
+func ToTransaksiResponse(transaksi entities.Transaksi) dto.TransaksiResponse {
+	res := dto.TransaksiResponse{
+		ID:                  transaksi.ID,
+		NamaBank:            transaksi.NamaBank,
+		Jumlah_Donasi_Event: transaksi.Jumlah_Donasi_Event,
+		Tanggal_Transaksi:   transaksi.Tanggal_Transaksi,
+		UserID:              transaksi.UserID,
+		EventID:             transaksi.EventID,
+		PembayaranID:        transaksi.PembayaranID,
+	}
+	if transaksi.User.ID != uuid.Nil {
+		user := ToUserPublicResponse(transaksi.User)
+		res.User = &user
+	}
+	return res
+}
+
+func ToTransaksiResponses(transaksis []entities.Transaksi) []dto.TransaksiResponse {
+	res := make([]dto.TransaksiResponse, 0, len(transaksis))
+	for _, transaksi := range transaksis {
+		res = append(res, ToTransaksiResponse(transaksi))
+	}
+	return res
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/golang-clean_template/dto"
+	"github.com/Caknoooo/golang-clean_template/entities"
+	"github.com/Caknoooo/golang-clean_template/helpers"
+	"github.com/Caknoooo/golang-clean_template/repository"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepository menyimpan user di memori dan hanya mengimplementasikan
+// method yang dipakai test, method lain akan panic karena interface yang
+// di-embed bernilai nil.
+type fakeUserRepository struct {
+	repository.UserRepository
+	users           map[uuid.UUID]entities.User
+	passwordUpdates int
+	// revokedAt berisi waktu seluruh token user dicabut bersama password
+	revokedAt map[uuid.UUID]time.Time
+	// kycFiles berisi dokumen KYC yang dikembalikan AnonymizeUser
+	kycFiles map[uuid.UUID][]string
+}
+
+func newFakeUserRepository(users ...entities.User) *fakeUserRepository {
+	repo := &fakeUserRepository{users: map[uuid.UUID]entities.User{}, revokedAt: map[uuid.UUID]time.Time{}}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (fr *fakeUserRepository) RegisterUser(ctx context.Context, user entities.User) (entities.User, error) {
+	user.ID = uuid.New()
+	fr.users[user.ID] = user
+	return user, nil
+}
+
+func (fr *fakeUserRepository) GetUserByID(ctx context.Context, userID uuid.UUID) (entities.User, error) {
+	user, ok := fr.users[userID]
+	if !ok {
+		return entities.User{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (fr *fakeUserRepository) GetUserByEmail(ctx context.Context, email string) (entities.User, error) {
+	for _, user := range fr.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return entities.User{}, gorm.ErrRecordNotFound
+}
+
+func (fr *fakeUserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
+	user := fr.users[userID]
+	user.Password = hashedPassword
+	fr.users[userID] = user
+	fr.passwordUpdates++
+	return nil
+}
+
+func (fr *fakeUserRepository) UpdatePasswordAndRevokeTokens(ctx context.Context, userID uuid.UUID, hashedPassword string, now time.Time) error {
+	if err := fr.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		return err
+	}
+	fr.revokedAt[userID] = now
+	return nil
+}
+
+func (fr *fakeUserRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	user := fr.users[userID]
+	user.Email = email
+	fr.users[userID] = user
+	return nil
+}
+
+// fakePasswordResetRepository menyimpan token reset berdasarkan hash-nya.
+type fakePasswordResetRepository struct {
+	repository.PasswordResetRepository
+	tokens map[string]entities.PasswordResetToken
+}
+
+func (fr *fakePasswordResetRepository) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (entities.PasswordResetToken, error) {
+	resetToken, ok := fr.tokens[tokenHash]
+	if !ok {
+		return entities.PasswordResetToken{}, gorm.ErrRecordNotFound
+	}
+	return resetToken, nil
+}
+
+func (fr *fakePasswordResetRepository) UsePasswordResetToken(ctx context.Context, resetTokenID uuid.UUID) (bool, error) {
+	for hash, resetToken := range fr.tokens {
+		if resetToken.ID == resetTokenID && resetToken.UsedAt == nil && resetToken.ExpiresAt.After(time.Now()) {
+			now := time.Now()
+			resetToken.UsedAt = &now
+			fr.tokens[hash] = resetToken
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestResetPasswordRevokesSessions(t *testing.T) {
+	user := entities.User{ID: uuid.New(), Email: "user@mail.com", Password: "hash-lama"}
+	users := newFakeUserRepository(user)
+	resets := &fakePasswordResetRepository{tokens: map[string]entities.PasswordResetToken{
+		helpers.HashToken("token-reset"): {ID: uuid.New(), UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	service := &userService{userRepository: users, passwordResetRepository: resets}
+	ctx := context.Background()
+
+	resetDTO := dto.UserResetPasswordDTO{Token: "token-reset", Password: "rahasia123", ConfirmPassword: "rahasia123"}
+	if err := service.ResetPassword(ctx, resetDTO); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	newHash := users.users[user.ID].Password
+	if ok, _ := helpers.CheckPassword(newHash, []byte("rahasia123")); !ok {
+		t.Error("password tidak diganti")
+	}
+	if _, ok := users.revokedAt[user.ID]; !ok {
+		t.Error("sesi lama tidak dicabut bersama penggantian password")
+	}
+
+	// Token yang sama tidak bisa dipakai dua kali
+	resetDTO.Password, resetDTO.ConfirmPassword = "rahasia456", "rahasia456"
+	if err := service.ResetPassword(ctx, resetDTO); err == nil {
+		t.Error("token reset bisa dipakai ulang")
+	}
+	if users.users[user.ID].Password != newHash {
+		t.Error("password berubah oleh token yang sudah dipakai")
+	}
+}
+
+func TestVerifyRehashesOutdatedPassword(t *testing.T) {
+	// Cost 4 berbeda dari konfigurasi default sehingga hash perlu di-upgrade
+	oldHash, err := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := entities.User{ID: uuid.New(), Email: "user@mail.com", Password: string(oldHash)}
+	repo := newFakeUserRepository(user)
+	service := &userService{userRepository: repo}
+	ctx := context.Background()
+
+	if ok, _ := service.Verify(ctx, user.Email, "salah"); ok {
+		t.Fatal("Verify menerima password salah")
+	}
+	if repo.passwordUpdates != 0 {
+		t.Fatal("password salah tidak boleh memicu rehash")
+	}
+
+	ok, err := service.Verify(ctx, user.Email, "rahasia123")
+	if !ok || err != nil {
+		t.Fatalf("Verify = %v, %v", ok, err)
+	}
+	if repo.passwordUpdates != 1 {
+		t.Fatalf("jumlah rehash = %d, want 1", repo.passwordUpdates)
+	}
+	newHash := repo.users[user.ID].Password
+	if newHash == string(oldHash) || helpers.PasswordNeedsRehash(newHash) {
+		t.Error("hash baru masih memakai parameter lama")
+	}
+	if ok, _ := helpers.CheckPassword(newHash, []byte("rahasia123")); !ok {
+		t.Error("hash baru tidak cocok dengan password")
+	}
+
+	// Hash yang sudah sesuai konfigurasi tidak di-rehash lagi
+	if ok, _ := service.Verify(ctx, user.Email, "rahasia123"); !ok {
+		t.Fatal("Verify gagal setelah rehash")
+	}
+	if repo.passwordUpdates != 1 {
+		t.Errorf("jumlah rehash = %d, want 1", repo.passwordUpdates)
+	}
+}
+
+func TestChangePasswordRevokesSessions(t *testing.T) {
+	hash, err := helpers.HashPassword("rahasia123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := entities.User{ID: uuid.New(), Email: "user@mail.com", Password: hash}
+	users := newFakeUserRepository(user)
+	service := &userService{userRepository: users}
+	ctx := context.Background()
+
+	wrong := dto.UserChangePasswordDTO{CurrentPassword: "salah", Password: "baru12345", ConfirmPassword: "baru12345"}
+	if err := service.ChangePassword(ctx, user.ID, wrong, false); err == nil {
+		t.Fatal("ChangePassword menerima password saat ini yang salah")
+	}
+	if _, ok := users.revokedAt[user.ID]; ok {
+		t.Fatal("sesi dicabut walaupun password gagal diganti")
+	}
+
+	passwordDTO := dto.UserChangePasswordDTO{CurrentPassword: "rahasia123", Password: "baru12345", ConfirmPassword: "baru12345"}
+	if err := service.ChangePassword(ctx, user.ID, passwordDTO, false); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if ok, _ := helpers.CheckPassword(users.users[user.ID].Password, []byte("baru12345")); !ok {
+		t.Error("password tidak diganti")
+	}
+	if _, ok := users.revokedAt[user.ID]; !ok {
+		t.Error("sesi lama tidak dicabut bersama penggantian password")
+	}
+}
+
+// fakeEmailChangeRepository menyimpan permintaan perubahan email berdasarkan
+// hash token-nya.
+type fakeEmailChangeRepository struct {
+	repository.EmailChangeRepository
+	tokens map[string]entities.EmailChangeToken
+}
+
+func (fr *fakeEmailChangeRepository) CreateEmailChangeToken(ctx context.Context, changeToken entities.EmailChangeToken) (entities.EmailChangeToken, error) {
+	changeToken.ID = uuid.New()
+	fr.tokens[changeToken.TokenHash] = changeToken
+	return changeToken, nil
+}
+
+func (fr *fakeEmailChangeRepository) GetEmailChangeTokenByHash(ctx context.Context, tokenHash string) (entities.EmailChangeToken, error) {
+	changeToken, ok := fr.tokens[tokenHash]
+	if !ok {
+		return entities.EmailChangeToken{}, gorm.ErrRecordNotFound
+	}
+	return changeToken, nil
+}
+
+func (fr *fakeEmailChangeRepository) UseEmailChangeToken(ctx context.Context, changeTokenID uuid.UUID) (bool, error) {
+	for hash, changeToken := range fr.tokens {
+		if changeToken.ID == changeTokenID && changeToken.UsedAt == nil && changeToken.ExpiresAt.After(time.Now()) {
+			now := time.Now()
+			changeToken.UsedAt = &now
+			fr.tokens[hash] = changeToken
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (fr *fakeEmailChangeRepository) InvalidateEmailChangeTokenByUserID(ctx context.Context, userID uuid.UUID) error {
+	for hash, changeToken := range fr.tokens {
+		if changeToken.UserID == userID && changeToken.UsedAt == nil {
+			now := time.Now()
+			changeToken.UsedAt = &now
+			fr.tokens[hash] = changeToken
+		}
+	}
+	return nil
+}
+
+// fakeMailer menyimpan isi email terakhir untuk setiap penerima.
+type fakeMailer struct {
+	bodies map[string]string
+}
+
+func (fm *fakeMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	fm.bodies[to] = body
+	return nil
+}
+
+// confirmToken mengambil token dari link konfirmasi di dalam email.
+func confirmToken(t *testing.T, body string) string {
+	t.Helper()
+	start := strings.Index(body, "token=")
+	if start == -1 {
+		t.Fatalf("email tidak berisi link konfirmasi: %q", body)
+	}
+	raw := body[start+len("token="):]
+	raw = raw[:strings.IndexByte(raw, '\n')]
+	token, err := url.QueryUnescape(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestConfirmEmailChangeIsSingleUse(t *testing.T) {
+	user := entities.User{ID: uuid.New(), Nama: "User", Email: "lama@mail.com"}
+	users := newFakeUserRepository(user)
+	mailer := &fakeMailer{bodies: map[string]string{}}
+	service := &userService{
+		userRepository:        users,
+		emailChangeRepository: &fakeEmailChangeRepository{tokens: map[string]entities.EmailChangeToken{}},
+		mailer:                mailer,
+	}
+	ctx := context.Background()
+
+	if err := service.RequestEmailChange(ctx, user.ID, dto.UserChangeEmailDTO{Email: "pertama@mail.com"}, true); err != nil {
+		t.Fatalf("RequestEmailChange: %v", err)
+	}
+	first := confirmToken(t, mailer.bodies["pertama@mail.com"])
+	if err := service.RequestEmailChange(ctx, user.ID, dto.UserChangeEmailDTO{Email: "kedua@mail.com"}, true); err != nil {
+		t.Fatalf("RequestEmailChange: %v", err)
+	}
+	second := confirmToken(t, mailer.bodies["kedua@mail.com"])
+
+	// Permintaan baru membatalkan link sebelumnya
+	if err := service.ConfirmEmailChange(ctx, first); err == nil {
+		t.Error("link perubahan email lama masih berlaku")
+	}
+	if err := service.ConfirmEmailChange(ctx, second); err != nil {
+		t.Fatalf("ConfirmEmailChange: %v", err)
+	}
+	if got := users.users[user.ID].Email; got != "kedua@mail.com" {
+		t.Fatalf("email = %s, want kedua@mail.com", got)
+	}
+
+	// Link yang sama tidak bisa dipakai lagi, misalnya setelah email
+	// dikembalikan ke alamat lama
+	users.users[user.ID] = user
+	if err := service.ConfirmEmailChange(ctx, second); err == nil {
+		t.Error("link perubahan email bisa dipakai ulang")
+	}
+	if got := users.users[user.ID].Email; got != user.Email {
+		t.Errorf("email = %s, want %s", got, user.Email)
+	}
+}
@@ -5,6 +5,7 @@ type Response struct {
 	Message string `json:"message"`
 	Error   any    `json:"error"`
 	Data    any    `json:"data"`
+	Meta    any    `json:"meta,omitempty"`
 }
 
 type EmptyObj struct{}
@@ -19,6 +20,13 @@ func BuildResponseSuccess(message string, data any) Response {
 	return res
 }
 
+// BuildResponseSuccessWithMeta dipakai untuk daftar yang dipaginasi.
+func BuildResponseSuccessWithMeta(message string, data any, meta any) Response {
+	res := BuildResponseSuccess(message, data)
+	res.Meta = meta
+	return res
+}
+
 func BuildResponseFailed(message string, err string, data any) Response {
 	res := Response{
 		Status: false,